@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS document_updates_archive;
+
+ALTER TABLE document_snapshots DROP COLUMN IF EXISTS compacted_at;
+ALTER TABLE document_snapshots DROP COLUMN IF EXISTS compacted_version;
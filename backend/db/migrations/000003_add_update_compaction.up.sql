@@ -0,0 +1,16 @@
+-- Highest update version already folded into the snapshot
+ALTER TABLE document_snapshots ADD COLUMN IF NOT EXISTS compacted_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE document_snapshots ADD COLUMN IF NOT EXISTS compacted_at TIMESTAMP;
+
+-- Archive table: keeps folded updates when archiving is enabled
+CREATE TABLE IF NOT EXISTS document_updates_archive (
+    id BIGINT PRIMARY KEY,
+    document_id UUID NOT NULL REFERENCES documents(uuid) ON DELETE CASCADE,
+    yjs_update BYTEA NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(uuid),
+    version INTEGER DEFAULT 1,
+    created_at TIMESTAMP DEFAULT NOW(),
+    archived_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_document_updates_archive_document_id_version ON document_updates_archive(document_id, version);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_document_updates_archive_created_at;
+
+ALTER TABLE document_snapshots DROP COLUMN IF EXISTS history_base_version;
+ALTER TABLE document_snapshots DROP COLUMN IF EXISTS history_base;
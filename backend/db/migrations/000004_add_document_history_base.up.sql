@@ -0,0 +1,6 @@
+-- History base: merged state of updates pruned by compaction, so older
+-- versions can still be rebuilt from the remaining updates
+ALTER TABLE document_snapshots ADD COLUMN IF NOT EXISTS history_base BYTEA;
+ALTER TABLE document_snapshots ADD COLUMN IF NOT EXISTS history_base_version INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_document_updates_archive_created_at ON document_updates_archive(created_at);
@@ -0,0 +1,14 @@
+-- Named checkpoints: a Yjs snapshot and its Markdown text captured on request
+CREATE TABLE IF NOT EXISTS document_versions (
+    uuid UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    document_uuid UUID NOT NULL REFERENCES documents(uuid) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    yjs_snapshot BYTEA NOT NULL,
+    content TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 0,
+    created_by UUID REFERENCES users(uuid) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (document_uuid, name)
+);
+
+CREATE INDEX idx_document_versions_document_uuid_created_at ON document_versions(document_uuid, created_at);
@@ -0,0 +1,3 @@
+-- Per-group cap on the size of a collaborative document state, in bytes;
+-- NULL uses the instance default
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS max_document_size INTEGER CHECK (max_document_size > 0);
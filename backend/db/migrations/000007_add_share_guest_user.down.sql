@@ -0,0 +1,5 @@
+DELETE FROM document_presence WHERE user_id = '00000000-0000-0000-0000-000000000001';
+DELETE FROM document_updates WHERE user_id = '00000000-0000-0000-0000-000000000001';
+DELETE FROM document_updates_archive WHERE user_id = '00000000-0000-0000-0000-000000000001';
+UPDATE document_snapshots SET modified_by = NULL WHERE modified_by = '00000000-0000-0000-0000-000000000001';
+DELETE FROM users WHERE uuid = '00000000-0000-0000-0000-000000000001';
@@ -0,0 +1,7 @@
+-- Synthetic user that edits made through share links are attributed to.
+-- Its password hash is not a bcrypt hash, so nobody can log in as it. A user
+-- that registered the login or email first leaves the row out rather than
+-- failing the migration.
+INSERT INTO users (uuid, login, email, hashed_password)
+VALUES ('00000000-0000-0000-0000-000000000001', 'share-link-guest', 'guest@share-link.invalid', '!')
+ON CONFLICT DO NOTHING;
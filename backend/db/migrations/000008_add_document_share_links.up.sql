@@ -0,0 +1,14 @@
+-- Share links: signed URLs are checked against their row so they can be revoked
+CREATE TABLE IF NOT EXISTS document_share_links (
+    uuid UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    document_uuid UUID NOT NULL REFERENCES documents(uuid) ON DELETE CASCADE,
+    created_by UUID REFERENCES users(uuid) ON DELETE SET NULL,
+    role VARCHAR(50) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    max_uses INTEGER CHECK (max_uses > 0),
+    uses INTEGER NOT NULL DEFAULT 0,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_document_share_links_document_uuid_created_at ON document_share_links(document_uuid, created_at);
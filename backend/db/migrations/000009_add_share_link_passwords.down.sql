@@ -0,0 +1,4 @@
+ALTER TABLE document_share_links
+    DROP COLUMN IF EXISTS unlock_window_start,
+    DROP COLUMN IF EXISTS failed_unlocks,
+    DROP COLUMN IF EXISTS password_hash;
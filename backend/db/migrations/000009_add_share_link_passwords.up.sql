@@ -0,0 +1,5 @@
+-- Password-protected share links: failed unlocks are counted per window to throttle guessing
+ALTER TABLE document_share_links
+    ADD COLUMN IF NOT EXISTS password_hash TEXT,
+    ADD COLUMN IF NOT EXISTS failed_unlocks INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS unlock_window_start TIMESTAMP WITH TIME ZONE;
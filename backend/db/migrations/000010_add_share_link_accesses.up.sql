@@ -0,0 +1,15 @@
+-- Uses of share links, for analytics; visitors are only stored as a keyed hash of their IP
+CREATE TABLE IF NOT EXISTS share_link_accesses (
+    id BIGSERIAL PRIMARY KEY,
+    document_uuid UUID NOT NULL REFERENCES documents(uuid) ON DELETE CASCADE,
+    link_uuid UUID REFERENCES document_share_links(uuid) ON DELETE CASCADE,
+    signature_hash VARCHAR(64),
+    ip_hash VARCHAR(64) NOT NULL,
+    user_agent_family VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    accessed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (link_uuid IS NOT NULL OR signature_hash IS NOT NULL)
+);
+
+CREATE INDEX idx_share_link_accesses_document_uuid_accessed_at ON share_link_accesses(document_uuid, accessed_at);
+CREATE INDEX idx_share_link_accesses_accessed_at ON share_link_accesses(accessed_at);
@@ -0,0 +1 @@
+ALTER TABLE document_presence DROP COLUMN IF EXISTS connections;
@@ -0,0 +1,3 @@
+-- Open collaboration connections of a user to a document across all instances;
+-- the presence row is removed when the last one closes
+ALTER TABLE document_presence ADD COLUMN IF NOT EXISTS connections INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Tags label documents inside their group, so a group share link can be limited to one of them
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_documents_tags ON documents USING GIN (tags);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_document_share_links_group_uuid_created_at;
+
+DELETE FROM document_share_links WHERE group_uuid IS NOT NULL;
+
+ALTER TABLE document_share_links DROP CONSTRAINT IF EXISTS document_share_links_target_check;
+ALTER TABLE document_share_links DROP COLUMN IF EXISTS tag;
+ALTER TABLE document_share_links DROP COLUMN IF EXISTS group_uuid;
+ALTER TABLE document_share_links ALTER COLUMN document_uuid SET NOT NULL;
@@ -0,0 +1,10 @@
+-- Group share links are stored next to document links, so they can be listed,
+-- capped and revoked the same way; a link belongs to exactly one of them
+ALTER TABLE document_share_links ALTER COLUMN document_uuid DROP NOT NULL;
+ALTER TABLE document_share_links ADD COLUMN IF NOT EXISTS group_uuid UUID REFERENCES groups(uuid) ON DELETE CASCADE;
+-- tag limits a group link to the documents carrying it
+ALTER TABLE document_share_links ADD COLUMN IF NOT EXISTS tag VARCHAR(50);
+ALTER TABLE document_share_links ADD CONSTRAINT document_share_links_target_check
+    CHECK ((document_uuid IS NULL) <> (group_uuid IS NULL));
+
+CREATE INDEX IF NOT EXISTS idx_document_share_links_group_uuid_created_at ON document_share_links(group_uuid, created_at);
@@ -5,16 +5,24 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/config"
+	websockethandler "github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/websocket"
+	collabrepo "github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
+	compactionservice "github.com/ukma-cs-ssdm-2025/team-circus/internal/service/compaction"
+	documentservice "github.com/ukma-cs-ssdm-2025/team-circus/internal/service/document"
 	"go.uber.org/zap"
 )
 
 const (
 	shutdownTimeout = 20 * time.Second
 	readTimeout     = 15 * time.Second
+	// shareAnalyticsPruneInterval is how often share link accesses past their
+	// retention are deleted.
+	shareAnalyticsPruneInterval = time.Hour
 )
 
 type App struct {
@@ -22,6 +30,11 @@ type App struct {
 	DB  *sql.DB
 	API *http.Server
 	l   *zap.Logger
+
+	broker    *collabrepo.NotifyBroker
+	hubs      *websockethandler.HubManager
+	documents *documentservice.DocumentService
+	workers   sync.WaitGroup
 }
 
 func New(cfg *config.Config, l *zap.Logger) *App {
@@ -55,6 +68,9 @@ func (a *App) Run(ctx context.Context) error {
 	}()
 	a.l.Info("API server started", zap.String("port", a.cfg.Srv.Port))
 
+	// background workers
+	a.startWorkers(ctx)
+
 	// wait for shutdown signal
 	<-ctx.Done()
 	a.l.Info("Shutdown signal received")
@@ -70,6 +86,55 @@ func (a *App) Run(ctx context.Context) error {
 	return err
 }
 
+func (a *App) startWorkers(ctx context.Context) {
+	compactionService := compactionservice.NewCompactionService(
+		collabrepo.NewDocumentPersistence(a.DB),
+		a.l,
+		compactionservice.Config{
+			Interval:  a.cfg.Collab.CompactionInterval,
+			Retention: a.cfg.Collab.UpdateRetention,
+			Archive:   a.cfg.Collab.ArchiveUpdates,
+		},
+	)
+
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		compactionService.Run(ctx)
+	}()
+
+	if a.documents != nil && a.cfg.Share.AnalyticsRetention > 0 {
+		a.workers.Add(1)
+		go func() {
+			defer a.workers.Done()
+			a.pruneShareAnalytics(ctx)
+		}()
+	}
+}
+
+// pruneShareAnalytics deletes share link accesses past their retention every
+// shareAnalyticsPruneInterval until ctx is canceled.
+func (a *App) pruneShareAnalytics(ctx context.Context) {
+	ticker := time.NewTicker(shareAnalyticsPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			deleted, err := a.documents.PruneShareLinkAccesses(ctx)
+			if err != nil {
+				a.l.Warn("failed to prune share link accesses", zap.Error(err))
+				continue
+			}
+			if deleted > 0 {
+				a.l.Info("pruned share link accesses", zap.Int64("deleted", deleted))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 func (a *App) shutdown(timeoutCtx context.Context) error {
 	var shutdownErr error
 
@@ -84,6 +149,35 @@ func (a *App) shutdown(timeoutCtx context.Context) error {
 		}
 	}
 
+	// collaboration hubs save their state while the DB is still open
+	if a.hubs != nil {
+		if err := a.hubs.Shutdown(timeoutCtx); err != nil {
+			wrapped := fmt.Errorf("shutdown collaboration hubs: %w", err)
+			a.l.Error("Shutdown collaboration hubs error", zap.Error(wrapped))
+			if shutdownErr == nil {
+				shutdownErr = wrapped
+			}
+		} else {
+			a.l.Info("Collaboration hubs shutdown successfully")
+		}
+	}
+
+	// background workers stop with the run context
+	a.workers.Wait()
+
+	// collaboration broker
+	if a.broker != nil {
+		if err := a.broker.Close(); err != nil {
+			wrapped := fmt.Errorf("shutdown collaboration broker: %w", err)
+			a.l.Error("Shutdown collaboration broker error", zap.Error(wrapped))
+			if shutdownErr == nil {
+				shutdownErr = wrapped
+			}
+		} else {
+			a.l.Info("Collaboration broker shutdown successfully")
+		}
+	}
+
 	// db
 	if a.DB != nil {
 		if err := a.DB.Close(); err != nil {
@@ -7,6 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginswagger "github.com/swaggo/gin-swagger"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/config"
+	adminhandler "github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/admin"
 	authhandler "github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/auth"
 	documenthandler "github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/document"
 	grouphandler "github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/group"
@@ -46,20 +48,40 @@ func (a *App) setupRouter() *gin.Engine {
 
 	groupRepo := grouprepo.NewGroupRepository(a.DB)
 	memberRepo := memberrepo.NewMemberRepository(a.DB)
-	groupService := groupservice.NewGroupService(groupRepo, memberRepo)
+	documentPersistence := collabrepo.NewDocumentPersistence(a.DB)
+	var wsBroker websockethandler.Broker
+	if a.cfg.Collab.Broker == config.CollabBrokerPostgres {
+		a.broker = collabrepo.NewNotifyBroker(a.DB, a.cfg.DB.DSN(), a.l)
+		wsBroker = a.broker
+	}
+	wsHubManager := websockethandler.NewHubManager(a.l, documentPersistence, wsBroker, websockethandler.Config{
+		AwarenessInterval: a.cfg.Collab.AwarenessInterval,
+		MaxDocumentSize:   a.cfg.Collab.MaxDocumentSize,
+	})
+	a.hubs = wsHubManager
+	groupService := groupservice.NewGroupService(groupRepo, memberRepo, documentPersistence, wsHubManager)
 
 	documentRepo := documentrepo.NewDocumentRepository(a.DB)
 	documentService := documentservice.NewDocumentService(
 		documentRepo,
 		memberRepo,
+		groupRepo,
+		documentPersistence,
+		wsHubManager,
 		documentservice.ShareConfig{
 			Secret:                a.cfg.Share.HMACSecret,
 			BaseURL:               a.cfg.Share.BaseURL,
 			DefaultExpirationDays: a.cfg.Share.DefaultExpirationDays,
 			MaxExpirationDays:     a.cfg.Share.MaxExpirationDays,
+			HashingCost:           a.cfg.HashingCost,
+			UnlockDuration:        a.cfg.Share.UnlockDuration,
+			UnlockAttempts:        a.cfg.Share.UnlockAttempts,
+			UnlockWindow:          a.cfg.Share.UnlockWindow,
+			VisitDuration:         a.cfg.Share.VisitDuration,
+			AnalyticsRetention:    a.cfg.Share.AnalyticsRetention,
 		},
 	)
-	documentPersistence := collabrepo.NewDocumentPersistence(a.DB)
+	a.documents = documentService
 
 	userRepo := userrepo.NewUserRepository(a.DB)
 	userService := userservice.NewUserService(userRepo, a.cfg.HashingCost)
@@ -67,12 +89,10 @@ func (a *App) setupRouter() *gin.Engine {
 	regRepo := regrepo.NewRegRepository(a.DB)
 	regService := regservice.NewRegService(regRepo, a.cfg.HashingCost)
 
-	memberService := memberservice.NewMemberService(memberRepo, groupRepo, userRepo)
+	memberService := memberservice.NewMemberService(memberRepo, groupRepo, userRepo, wsHubManager)
 
 	apiV1 := router.Group("/api/v1")
 
-	wsHubManager := websockethandler.NewHubManager(a.l, documentPersistence)
-
 	public := apiV1.Group("")
 	{
 		public.POST("/signup", reghandler.NewRegHandler(regService, a.l))
@@ -80,6 +100,10 @@ func (a *App) setupRouter() *gin.Engine {
 			a.cfg.SecretToken, a.cfg.AccessDuration, a.cfg.RefreshDuration))
 		public.POST("/auth/refresh", authhandler.NewRefreshTokenHandler(userRepo, a.l, a.cfg.SecretToken, a.cfg.AccessDuration))
 		public.GET("/documents/public", documenthandler.NewGetPublicDocumentHandler(documentService, a.l))
+		public.PUT("/documents/public", documenthandler.NewUpdatePublicDocumentHandler(documentService, a.l))
+		public.POST("/documents/public/unlock", documenthandler.NewUnlockShareLinkHandler(documentService, a.l))
+		public.GET("/groups/public", grouphandler.NewGetPublicGroupHandler(documentService, a.l))
+		public.GET("/groups/public/documents/:doc_uuid", grouphandler.NewGetPublicGroupDocumentHandler(documentService, a.l))
 	}
 
 	protected := apiV1.Group("")
@@ -94,6 +118,10 @@ func (a *App) setupRouter() *gin.Engine {
 			groups.GET("", grouphandler.NewGetAllGroupsHandler(groupService, a.l))
 			groups.PUT("/:uuid", grouphandler.NewUpdateGroupHandler(groupService, a.l))
 			groups.DELETE("/:uuid", grouphandler.NewDeleteGroupHandler(groupService, a.l))
+			groups.GET("/:uuid/presence", grouphandler.NewGetGroupPresenceHandler(groupService, a.l))
+			groups.POST("/:uuid/share", grouphandler.NewShareGroupHandler(documentService, a.l))
+			groups.GET("/:uuid/share", grouphandler.NewGetGroupShareLinksHandler(documentService, a.l))
+			groups.DELETE("/:uuid/share/:link_uuid", grouphandler.NewRevokeGroupShareLinkHandler(documentService, a.l))
 
 			members := groups.Group("/:uuid/members")
 			{
@@ -111,6 +139,16 @@ func (a *App) setupRouter() *gin.Engine {
 			documents.GET("", documenthandler.NewGetAllDocumentsHandler(documentService, a.l))
 			documents.PUT("/:uuid", documenthandler.NewUpdateDocumentHandler(documentService, a.l))
 			documents.POST("/:uuid/share", documenthandler.NewShareDocumentHandler(documentService, a.l))
+			documents.GET("/:uuid/share", documenthandler.NewGetShareLinksHandler(documentService, a.l))
+			documents.DELETE("/:uuid/share/:link_uuid", documenthandler.NewRevokeShareLinkHandler(documentService, a.l))
+			documents.GET("/:uuid/share/analytics", documenthandler.NewGetShareLinkAnalyticsHandler(documentService, a.l))
+			documents.GET("/:uuid/history", documenthandler.NewGetDocumentHistoryHandler(documentService, a.l))
+			documents.POST("/:uuid/restore", documenthandler.NewRestoreDocumentHandler(documentService, a.l))
+			documents.POST("/:uuid/versions", documenthandler.NewCreateDocumentVersionHandler(documentService, a.l))
+			documents.GET("/:uuid/versions", documenthandler.NewGetDocumentVersionsHandler(documentService, a.l))
+			documents.GET("/:uuid/versions/:version_uuid", documenthandler.NewGetDocumentVersionHandler(documentService, a.l))
+			documents.GET("/:uuid/versions/:version_uuid/diff", documenthandler.NewDiffDocumentVersionsHandler(documentService, a.l))
+			documents.GET("/:uuid/presence", documenthandler.NewGetDocumentPresenceHandler(documentService, a.l))
 			documents.DELETE("/:uuid", documenthandler.NewDeleteDocumentHandler(documentService, a.l))
 		}
 
@@ -121,6 +159,17 @@ func (a *App) setupRouter() *gin.Engine {
 			users.PUT("/:uuid", userhandler.NewUpdateUserHandler(userService, a.l))
 			users.DELETE("/:uuid", userhandler.NewDeleteUserHandler(userService, a.l))
 		}
+
+		admin := protected.Group("/admin")
+		admin.Use(middleware.AdminMiddleware(a.cfg.Admin.UserUUIDs))
+		{
+			collab := admin.Group("/collab")
+			collab.GET("/hubs", adminhandler.NewGetCollabStatsHandler(wsHubManager, a.l))
+			collab.GET("/hubs/:uuid", adminhandler.NewGetCollabHubHandler(wsHubManager, a.l))
+			collab.POST("/hubs/:uuid/persist", adminhandler.NewPersistCollabHubHandler(wsHubManager, a.l))
+			collab.POST("/hubs/:uuid/close", adminhandler.NewCloseCollabHubHandler(wsHubManager, a.l))
+			collab.GET("/metrics", adminhandler.NewGetCollabMetricsHandler(wsHubManager, a.l))
+		}
 	}
 
 	wsGroup := router.Group("/ws")
@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/kelseyhightower/envconfig"
 )
 
@@ -40,6 +42,36 @@ type ShareConfig struct {
 	BaseURL               string `envconfig:"APP_BASE_URL" required:"true"`
 	DefaultExpirationDays int    `envconfig:"SHARE_DEFAULT_EXPIRATION_DAYS" default:"7"`
 	MaxExpirationDays     int    `envconfig:"SHARE_MAX_EXPIRATION_DAYS" default:"90"`
+	// UnlockDuration is how long a guest stays in after entering a share link's password.
+	UnlockDuration time.Duration `envconfig:"SHARE_UNLOCK_DURATION" default:"1h"`
+	// UnlockAttempts wrong passwords lock a share link for the rest of UnlockWindow.
+	UnlockAttempts int           `envconfig:"SHARE_UNLOCK_ATTEMPTS" default:"5"`
+	UnlockWindow   time.Duration `envconfig:"SHARE_UNLOCK_WINDOW" default:"15m"`
+	// VisitDuration is how long opening a share link lasts before it counts as a new use.
+	VisitDuration time.Duration `envconfig:"SHARE_VISIT_DURATION" default:"12h"`
+	// AnalyticsRetention is how long share link accesses are kept; zero keeps them forever.
+	AnalyticsRetention time.Duration `envconfig:"SHARE_ANALYTICS_RETENTION" default:"2160h"`
+}
+
+// CollabBrokerPostgres relays collaboration messages between instances via LISTEN/NOTIFY.
+const CollabBrokerPostgres = "postgres"
+
+type CollabConfig struct {
+	// Broker selects how websocket messages reach other instances; "none" keeps hubs local.
+	Broker string `envconfig:"COLLAB_BROKER" default:"postgres"`
+	// CompactionInterval controls how often updates are folded into snapshots; zero disables compaction.
+	CompactionInterval time.Duration `envconfig:"COLLAB_COMPACTION_INTERVAL" default:"10m"`
+	UpdateRetention    time.Duration `envconfig:"COLLAB_UPDATE_RETENTION" default:"168h"`
+	ArchiveUpdates     bool          `envconfig:"COLLAB_ARCHIVE_UPDATES" default:"false"`
+	// AwarenessInterval controls how often merged cursor and presence states are relayed.
+	AwarenessInterval time.Duration `envconfig:"COLLAB_AWARENESS_INTERVAL" default:"100ms"`
+	// MaxDocumentSize caps the encoded state of a document, in bytes, for groups without a limit of their own.
+	MaxDocumentSize int `envconfig:"COLLAB_MAX_DOCUMENT_SIZE" default:"4194304"`
+}
+
+type AdminConfig struct {
+	// UserUUIDs lists the users allowed to use the admin API; empty disables it.
+	UserUUIDs []uuid.UUID `envconfig:"ADMIN_USER_UUIDS"`
 }
 
 type Config struct {
@@ -51,6 +83,8 @@ type Config struct {
 	AccessDuration  int    `envconfig:"ACCESS_DURATION" required:"true"`
 	RefreshDuration int    `envconfig:"REFRESH_DURATION" required:"true"`
 	Share           ShareConfig
+	Collab          CollabConfig
+	Admin           AdminConfig
 }
 
 func Load() (*Config, error) {
@@ -8,9 +8,12 @@ import (
 )
 
 type Group struct {
-	UUID      uuid.UUID
-	Name      string
-	CreatedAt time.Time
+	UUID uuid.UUID
+	Name string
+	// MaxDocumentSize caps the collaborative state of the group's documents,
+	// in bytes; nil uses the instance default.
+	MaxDocumentSize *int
+	CreatedAt       time.Time
 }
 
 type Member struct {
@@ -20,12 +23,222 @@ type Member struct {
 	CreatedAt time.Time
 }
 
+// PermissionChange describes a new role of a user in a group. An empty Role
+// means the user was removed from the group.
+type PermissionChange struct {
+	GroupUUID uuid.UUID
+	UserUUID  uuid.UUID
+	Role      string
+	// ShareLinkUUID, when set, makes the change apply to the guests that
+	// joined through the share link instead of to a member.
+	ShareLinkUUID uuid.UUID
+}
+
 type Document struct {
 	UUID      uuid.UUID
 	GroupUUID uuid.UUID
 	Name      string
 	Content   string
 	CreatedAt time.Time
+	// Tags label the document inside its group.
+	Tags []string
+	// Version is the collaborative version of the document; it is only set
+	// when a single document is read or updated.
+	Version int
+}
+
+// DocumentUpdate is a change of a document made through the REST API.
+type DocumentUpdate struct {
+	Name string
+	// Content is the new text; nil leaves the text alone.
+	Content *string
+	// BaseVersion is the version Content was edited from. When set, the write
+	// is refused with ErrVersionConflict if the document changed since.
+	BaseVersion *int
+	// Tags replace the tags of the document; nil leaves them alone.
+	Tags []string
+}
+
+// DocumentHistoryEntry groups consecutive updates of one user made within a time window.
+type DocumentHistoryEntry struct {
+	UserUUID    uuid.UUID
+	UserLogin   string
+	FromVersion int
+	ToVersion   int
+	Updates     int
+	Size        int
+	StartedAt   time.Time
+	EndedAt     time.Time
+}
+
+// RestoredDocument describes the result of restoring a document to an earlier version.
+type RestoredDocument struct {
+	UUID            uuid.UUID
+	RestoredVersion int
+	Version         int
+	Content         string
+}
+
+// DocumentVersion is a named checkpoint of a document.
+type DocumentVersion struct {
+	UUID         uuid.UUID
+	DocumentUUID uuid.UUID
+	Name         string
+	Content      string
+	YjsSnapshot  []byte
+	Version      int
+	CreatedBy    uuid.UUID
+	CreatedAt    time.Time
+}
+
+// ShareLink is a stored share link of a document or of a group. Its row lets
+// the link be revoked and its uses be counted.
+type ShareLink struct {
+	UUID uuid.UUID
+	// DocumentUUID is the shared document; uuid.Nil for group links.
+	DocumentUUID uuid.UUID
+	// GroupUUID is the shared group; uuid.Nil for document links.
+	GroupUUID uuid.UUID
+	// Tag limits a group link to the documents carrying it; empty shares
+	// every document of the group.
+	Tag       string
+	CreatedBy uuid.UUID
+	Role      string
+	ExpiresAt time.Time
+	// MaxUses caps how often the link can be opened; nil is unlimited.
+	MaxUses   *int
+	Uses      int
+	Revoked   bool
+	CreatedAt time.Time
+	// PasswordHash is the bcrypt hash of the link's password; empty when the
+	// link needs none.
+	PasswordHash string
+	// URL is the signed link; it is derived, not stored.
+	URL string
+}
+
+// ShareLinkOptions are the settings of a new share link.
+type ShareLinkOptions struct {
+	// ExpirationDays is how long the link lasts; zero uses the default.
+	ExpirationDays int
+	// Role is what the link lets guests do; empty shares read-only.
+	Role string
+	// MaxUses caps how often the link can be opened; nil is unlimited.
+	MaxUses *int
+	// Password must be entered before the link opens; empty needs none.
+	Password string
+}
+
+// ShareLinkQuery holds the parameters a share link carries in its URL. Link
+// is empty for the stateless links issued before links were stored.
+type ShareLinkQuery struct {
+	Doc  string
+	Sig  string
+	Exp  string
+	Role string
+	Link string
+	// Token is the guest token issued when the link's password was entered.
+	Token string
+	// Visit is the visit token issued when the link was opened; requests
+	// carrying it do not count as further uses.
+	Visit string
+}
+
+// ShareVisit is a token proving that a guest opened a stored share link,
+// valid until ExpiresAt.
+type ShareVisit struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// GroupShareLinkOptions are the settings of a new group share link.
+type GroupShareLinkOptions struct {
+	// ExpirationDays is how long the link lasts; zero uses the default.
+	ExpirationDays int
+	// MaxUses caps how often the link can be opened; nil is unlimited.
+	MaxUses *int
+	// Tag limits the link to the documents carrying it; empty shares every
+	// document of the group.
+	Tag string
+}
+
+// GroupShareLinkQuery holds the parameters a group share link carries in its
+// URL.
+type GroupShareLinkQuery struct {
+	Group string
+	Sig   string
+	Exp   string
+	Link  string
+	// Visit is the visit token issued when the link was opened; requests
+	// carrying it do not count as further uses.
+	Visit string
+}
+
+// Channels a share link can be used through.
+const (
+	ShareChannelREST      = "rest"
+	ShareChannelWebSocket = "websocket"
+)
+
+// ShareLinkVisit describes the request that used a share link.
+type ShareLinkVisit struct {
+	Channel   string
+	IP        string
+	UserAgent string
+}
+
+// ShareLinkAccess is a recorded use of a share link. Stored links are told
+// apart by LinkUUID, stateless ones by SignatureHash; visitors are only known
+// by a hash of their IP address.
+type ShareLinkAccess struct {
+	DocumentUUID    uuid.UUID
+	LinkUUID        uuid.UUID
+	SignatureHash   string
+	IPHash          string
+	UserAgentFamily string
+	Channel         string
+	AccessedAt      time.Time
+}
+
+// ShareLinkDailyAccesses counts the uses of a document's share links on a day.
+type ShareLinkDailyAccesses struct {
+	Day            time.Time
+	Accesses       int
+	UniqueVisitors int
+	WebSocket      int
+}
+
+// ShareLinkAnalytics sums up the uses of a document's share links since From.
+type ShareLinkAnalytics struct {
+	From           time.Time
+	Accesses       int
+	UniqueVisitors int
+	Days           []ShareLinkDailyAccesses
+}
+
+// DiffLine is a line of a line-based text diff; Op is "equal", "insert" or "delete".
+type DiffLine struct {
+	Op   string
+	Text string
+}
+
+// DocumentVersionDiff compares a checkpoint with another one, or with the
+// current document when ToUUID is uuid.Nil.
+type DocumentVersionDiff struct {
+	FromUUID uuid.UUID
+	ToUUID   uuid.UUID
+	Lines    []DiffLine
+	Added    int
+	Removed  int
+}
+
+// Presence tells that a user currently has a document open.
+type Presence struct {
+	DocumentUUID   uuid.UUID
+	UserUUID       uuid.UUID
+	UserLogin      string
+	CursorPosition *int
+	LastSeen       time.Time
 }
 
 type User struct {
@@ -37,19 +250,46 @@ type User struct {
 }
 
 var (
-	ErrGroupNotFound    = errors.New("group not found")
-	ErrDocumentNotFound = errors.New("document not found")
-	ErrUserNotFound     = errors.New("user not found")
-	ErrInternal         = errors.New("internal error")
-	ErrForbidden        = errors.New("forbidden")
-	ErrAlreadyExists    = errors.New("already exists")
-	ErrOnlyAuthor       = errors.New("there should be one author")
-	ErrShareLinkInvalid = errors.New("invalid share link")
-	ErrShareLinkExpired = errors.New("expired share link")
+	ErrGroupNotFound     = errors.New("group not found")
+	ErrDocumentNotFound  = errors.New("document not found")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrInternal          = errors.New("internal error")
+	ErrForbidden         = errors.New("forbidden")
+	ErrAlreadyExists     = errors.New("already exists")
+	ErrOnlyAuthor        = errors.New("there should be one author")
+	ErrShareLinkInvalid  = errors.New("invalid share link")
+	ErrShareLinkExpired  = errors.New("expired share link")
+	ErrShareLinkRevoked  = errors.New("revoked share link")
+	ErrShareLinkUsedUp   = errors.New("share link has no uses left")
+	ErrShareLinkNotFound = errors.New("share link not found")
+	ErrShareLinkLocked   = errors.New("share link requires a password")
+	ErrWrongPassword     = errors.New("wrong share link password")
+	ErrTooManyAttempts   = errors.New("too many failed attempts")
+	ErrHistoryPruned     = errors.New("document history no longer available")
+	ErrVersionNotFound   = errors.New("document version not found")
+	ErrVersionConflict   = errors.New("document changed since base version")
 )
 
 const (
 	RoleAuthor = "author"
 	RoleEditor = "editor"
 	RoleViewer = "viewer"
+	// RoleCommenter is only granted through share links; like viewers,
+	// commenters cannot change the document content.
+	RoleCommenter = "commenter"
 )
+
+// GuestUserUUID is the synthetic user that edits made through share links are
+// attributed to.
+var GuestUserUUID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// DocumentTextName is the root Y.Text the editor binds document content to.
+const DocumentTextName = "content"
+
+// ShareTokenCookiePrefix starts the name of the cookie holding the guest token
+// of a password-protected share link; the link UUID follows it.
+const ShareTokenCookiePrefix = "shareToken_"
+
+// ShareVisitCookiePrefix starts the name of the cookie holding the visit token
+// of a stored share link; the link UUID follows it.
+const ShareVisitCookiePrefix = "shareVisit_"
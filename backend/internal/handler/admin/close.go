@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/admin/responses"
+	"go.uber.org/zap"
+)
+
+type closeCollabHubService interface {
+	ForceCloseHub(ctx context.Context, documentID uuid.UUID) (bool, error)
+}
+
+// NewCloseCollabHubHandler closes a collaboration hub
+// @Summary Close a collaboration hub
+// @Description Save and close the hub of a document; its clients are asked to reconnect
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Document UUID"
+// @Success 200 {object} responses.CollabHubActionResponse "Hub closed successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Admin access required"
+// @Failure 404 {object} map[string]interface{} "Hub not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/collab/hubs/{uuid}/close [post]
+func NewCloseCollabHubHandler(service closeCollabHubService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uuidParam := c.Param("uuid")
+		docUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("close collab hub handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		ok, err := service.ForceCloseHub(c.Request.Context(), docUUID)
+		if err != nil {
+			logger.Error("failed to close collaboration hub", zap.Error(err), zap.String("uuid", uuidParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to close collaboration hub"})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "hub not found"})
+			return
+		}
+
+		logger.Info("collaboration hub closed by admin", zap.String("uuid", uuidParam))
+		c.JSON(http.StatusOK, responses.CollabHubActionResponse{Message: "Hub closed successfully"})
+	}
+}
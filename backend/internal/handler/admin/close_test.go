@@ -0,0 +1,106 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/admin"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/admin/responses"
+	"go.uber.org/zap"
+)
+
+type mockCloseCollabHubService struct {
+	mock.Mock
+}
+
+func (m *mockCloseCollabHubService) ForceCloseHub(ctx context.Context, documentID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, documentID)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestNewCloseCollabHubHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockCloseCollabHubService, gin.HandlerFunc) {
+		mockService := &mockCloseCollabHubService{}
+		handler := admin.NewCloseCollabHubHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, docParam string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/collab/hubs/"+docParam+"/close", nil)
+		c.Params = gin.Params{{Key: "uuid", Value: docParam}}
+		handler(c)
+		return w
+	}
+
+	t.Run("SuccessfulForceCloseHub", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		mockService.On("ForceCloseHub", mock.Anything, documentUUID).Return(true, nil)
+
+		// Act
+		w := serve(handler, documentUUID.String())
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response responses.CollabHubActionResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Hub closed successfully", response.Message)
+	})
+
+	t.Run("HubNotFound", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		mockService.On("ForceCloseHub", mock.Anything, documentUUID).Return(false, nil)
+
+		// Act
+		w := serve(handler, documentUUID.String())
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidUUID", func(t *testing.T) {
+		// Arrange
+		_, handler := setup(t)
+
+		// Act
+		w := serve(handler, "invalid-uuid")
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("ServiceError", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		mockService.On("ForceCloseHub", mock.Anything, documentUUID).Return(false, errors.New("database unavailable"))
+
+		// Act
+		w := serve(handler, documentUUID.String())
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/admin/responses"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/websocket"
+	"go.uber.org/zap"
+)
+
+type collabHubService interface {
+	Hub(ctx context.Context, documentID uuid.UUID) (websocket.HubStats, bool, error)
+}
+
+// NewGetCollabHubHandler describes the collaboration hub of a document
+// @Summary Get a collaboration hub
+// @Description Retrieve the clients, traffic and persistence state of the hub of a document open on this instance
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Document UUID"
+// @Success 200 {object} responses.GetCollabHubResponse "Hub retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Admin access required"
+// @Failure 404 {object} map[string]interface{} "Hub not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/collab/hubs/{uuid} [get]
+func NewGetCollabHubHandler(service collabHubService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uuidParam := c.Param("uuid")
+		docUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("get collab hub handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		hub, ok, err := service.Hub(c.Request.Context(), docUUID)
+		if err != nil {
+			logger.Error("failed to get collaboration hub", zap.Error(err), zap.String("uuid", uuidParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get collaboration hub"})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "hub not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, responses.GetCollabHubResponse{HubStats: hub})
+	}
+}
@@ -0,0 +1,113 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/admin"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/admin/responses"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/websocket"
+	"go.uber.org/zap"
+)
+
+type mockCollabHubService struct {
+	mock.Mock
+}
+
+func (m *mockCollabHubService) Hub(ctx context.Context, documentID uuid.UUID) (websocket.HubStats, bool, error) {
+	args := m.Called(ctx, documentID)
+	return args.Get(0).(websocket.HubStats), args.Bool(1), args.Error(2) //nolint:errcheck
+}
+
+func TestNewGetCollabHubHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockCollabHubService, gin.HandlerFunc) {
+		mockService := &mockCollabHubService{}
+		handler := admin.NewGetCollabHubHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, docParam string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/admin/collab/hubs/"+docParam, nil)
+		c.Params = gin.Params{{Key: "uuid", Value: docParam}}
+		handler(c)
+		return w
+	}
+
+	t.Run("SuccessfulGetHub", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		mockService.On("Hub", mock.Anything, documentUUID).Return(websocket.HubStats{
+			DocumentID:   documentUUID,
+			Version:      7,
+			SnapshotSize: 120,
+		}, true, nil)
+
+		// Act
+		w := serve(handler, documentUUID.String())
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response responses.GetCollabHubResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, documentUUID, response.DocumentID)
+		assert.Equal(t, 7, response.Version)
+		assert.Equal(t, 120, response.SnapshotSize)
+	})
+
+	t.Run("HubNotFound", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		mockService.On("Hub", mock.Anything, documentUUID).Return(websocket.HubStats{}, false, nil)
+
+		// Act
+		w := serve(handler, documentUUID.String())
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("InvalidUUID", func(t *testing.T) {
+		// Arrange
+		_, handler := setup(t)
+
+		// Act
+		w := serve(handler, "invalid-uuid")
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("ServiceError", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		mockService.On("Hub", mock.Anything, documentUUID).Return(websocket.HubStats{}, false, errors.New("timeout"))
+
+		// Act
+		w := serve(handler, documentUUID.String())
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,101 @@
+package admin
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/websocket"
+	"go.uber.org/zap"
+)
+
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// NewGetCollabMetricsHandler exposes the collaboration stats for Prometheus
+// @Summary Get collaboration metrics
+// @Description Retrieve the collaboration stats of this instance in the Prometheus text format
+// @Tags admin
+// @Produce plain
+// @Success 200 {string} string "Metrics retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Admin access required"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/collab/metrics [get]
+func NewGetCollabMetricsHandler(service collabStatsService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		summary, err := service.Stats(c.Request.Context())
+		if err != nil {
+			logger.Error("failed to get collaboration stats", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get collaboration metrics"})
+			return
+		}
+
+		hubs, err := service.Hubs(c.Request.Context())
+		if err != nil {
+			logger.Error("failed to list collaboration hubs", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get collaboration metrics"})
+			return
+		}
+
+		c.Data(http.StatusOK, prometheusContentType, formatMetrics(summary, hubs))
+	}
+}
+
+// formatMetrics renders the stats in the Prometheus text exposition format.
+func formatMetrics(summary websocket.Stats, hubs []websocket.HubStats) []byte {
+	var buf bytes.Buffer
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	metric("collab_hubs", "gauge", "Collaboration hubs open on this instance.")
+	fmt.Fprintf(&buf, "collab_hubs %d\n", summary.Hubs)
+	metric("collab_clients", "gauge", "Clients connected to the hubs.")
+	fmt.Fprintf(&buf, "collab_clients %d\n", summary.Clients)
+
+	metric("collab_messages_received_total", "counter", "Websocket frames received from clients by kind.")
+	for _, kind := range slices.Sorted(maps.Keys(summary.Traffic.Received)) {
+		fmt.Fprintf(&buf, "collab_messages_received_total{kind=%q} %d\n", kind, summary.Traffic.Received[kind].Count)
+	}
+	metric("collab_messages_sent_total", "counter", "Websocket frames sent to clients by kind.")
+	for _, kind := range slices.Sorted(maps.Keys(summary.Traffic.Sent)) {
+		fmt.Fprintf(&buf, "collab_messages_sent_total{kind=%q} %d\n", kind, summary.Traffic.Sent[kind].Count)
+	}
+	metric("collab_received_bytes_total", "counter", "Bytes received from clients.")
+	fmt.Fprintf(&buf, "collab_received_bytes_total %d\n", summary.Traffic.BytesIn)
+	metric("collab_sent_bytes_total", "counter", "Bytes sent to clients.")
+	fmt.Fprintf(&buf, "collab_sent_bytes_total %d\n", summary.Traffic.BytesOut)
+	metric("collab_persistence_failures_total", "counter", "Failed snapshot, content and update writes.")
+	fmt.Fprintf(&buf, "collab_persistence_failures_total %d\n", summary.Traffic.PersistenceFailures)
+	metric("collab_rejected_messages_total", "counter", "Malformed frames and updates refused for the document size limit.")
+	fmt.Fprintf(&buf, "collab_rejected_messages_total %d\n", summary.Traffic.Rejected)
+
+	metric("collab_delivery_dropped_total", "counter", "Messages to slow clients replaced by a full-state resync.")
+	fmt.Fprintf(&buf, "collab_delivery_dropped_total %d\n", summary.Delivery.Dropped)
+	metric("collab_delivery_coalesced_total", "counter", "Awareness states replaced by a newer one before they were sent.")
+	fmt.Fprintf(&buf, "collab_delivery_coalesced_total %d\n", summary.Delivery.Coalesced)
+	metric("collab_delivery_resynced_total", "counter", "Full states sent to clients that fell too far behind.")
+	fmt.Fprintf(&buf, "collab_delivery_resynced_total %d\n", summary.Delivery.Resynced)
+
+	metric("collab_hub_clients", "gauge", "Clients connected to a hub.")
+	for _, hub := range hubs {
+		fmt.Fprintf(&buf, "collab_hub_clients{document_id=%q} %d\n", hub.DocumentID, len(hub.Clients))
+	}
+	metric("collab_hub_state_bytes", "gauge", "Size of the encoded state of a hub.")
+	for _, hub := range hubs {
+		fmt.Fprintf(&buf, "collab_hub_state_bytes{document_id=%q} %d\n", hub.DocumentID, hub.StateSize)
+	}
+	metric("collab_hub_snapshot_bytes", "gauge", "Size of the snapshot a hub saved last.")
+	for _, hub := range hubs {
+		fmt.Fprintf(&buf, "collab_hub_snapshot_bytes{document_id=%q} %d\n", hub.DocumentID, hub.SnapshotSize)
+	}
+	metric("collab_hub_last_updated_seconds", "gauge", "Unix time of the last change to a hub.")
+	for _, hub := range hubs {
+		fmt.Fprintf(&buf, "collab_hub_last_updated_seconds{document_id=%q} %d\n", hub.DocumentID, hub.LastUpdated.Unix())
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,67 @@
+package admin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/admin"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/websocket"
+	"go.uber.org/zap"
+)
+
+func TestNewGetCollabMetricsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Arrange
+	mockService := &mockCollabStatsService{}
+	handler := admin.NewGetCollabMetricsHandler(mockService, zap.NewNop())
+	t.Cleanup(func() {
+		mockService.AssertExpectations(t)
+	})
+
+	documentUUID := uuid.New()
+	mockService.On("Stats", mock.Anything).Return(websocket.Stats{
+		Hubs:    1,
+		Clients: 2,
+		Traffic: websocket.TrafficStats{
+			Received:            map[string]websocket.MessageStats{"update": {Count: 5}, "sync": {Count: 2}},
+			BytesIn:             640,
+			PersistenceFailures: 1,
+			Rejected:            3,
+		},
+	}, nil)
+	mockService.On("Hubs", mock.Anything).Return([]websocket.HubStats{{
+		DocumentID:   documentUUID,
+		Clients:      make([]websocket.ClientStats, 2),
+		SnapshotSize: 96,
+		LastUpdated:  time.Unix(1700000000, 0),
+	}}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/collab/metrics", nil)
+
+	// Act
+	handler(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+
+	body := w.Body.String()
+	assert.Contains(t, body, "# TYPE collab_hubs gauge\ncollab_hubs 1\n")
+	assert.Contains(t, body, "collab_clients 2\n")
+	assert.Contains(t, body, "collab_messages_received_total{kind=\"sync\"} 2\ncollab_messages_received_total{kind=\"update\"} 5\n")
+	assert.Contains(t, body, "collab_received_bytes_total 640\n")
+	assert.Contains(t, body, "collab_persistence_failures_total 1\n")
+	assert.Contains(t, body, "collab_rejected_messages_total 3\n")
+	assert.Contains(t, body, "collab_hub_clients{document_id=\""+documentUUID.String()+"\"} 2\n")
+	assert.Contains(t, body, "collab_hub_snapshot_bytes{document_id=\""+documentUUID.String()+"\"} 96\n")
+	assert.Contains(t, body, "collab_hub_last_updated_seconds{document_id=\""+documentUUID.String()+"\"} 1700000000\n")
+}
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/admin/responses"
+	"go.uber.org/zap"
+)
+
+type persistCollabHubService interface {
+	PersistHub(ctx context.Context, documentID uuid.UUID) (bool, error)
+}
+
+// NewPersistCollabHubHandler saves the state of a collaboration hub right away
+// @Summary Persist a collaboration hub
+// @Description Save the snapshot, content and pending updates of the hub of a document without waiting for the next persistence tick
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Document UUID"
+// @Success 200 {object} responses.CollabHubActionResponse "Hub persisted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Admin access required"
+// @Failure 404 {object} map[string]interface{} "Hub not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/collab/hubs/{uuid}/persist [post]
+func NewPersistCollabHubHandler(service persistCollabHubService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uuidParam := c.Param("uuid")
+		docUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("persist collab hub handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		ok, err := service.PersistHub(c.Request.Context(), docUUID)
+		if err != nil {
+			logger.Error("failed to persist collaboration hub", zap.Error(err), zap.String("uuid", uuidParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to persist collaboration hub"})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "hub not found"})
+			return
+		}
+
+		logger.Info("collaboration hub persisted by admin", zap.String("uuid", uuidParam))
+		c.JSON(http.StatusOK, responses.CollabHubActionResponse{Message: "Hub persisted successfully"})
+	}
+}
@@ -0,0 +1,16 @@
+package responses
+
+import "github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/websocket"
+
+type GetCollabStatsResponse struct {
+	Summary websocket.Stats      `json:"summary"`
+	Hubs    []websocket.HubStats `json:"hubs"`
+}
+
+type GetCollabHubResponse struct {
+	websocket.HubStats
+}
+
+type CollabHubActionResponse struct {
+	Message string `json:"message"`
+}
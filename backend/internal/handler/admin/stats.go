@@ -0,0 +1,52 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/admin/responses"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/websocket"
+	"go.uber.org/zap"
+)
+
+type collabStatsService interface {
+	Stats(ctx context.Context) (websocket.Stats, error)
+	Hubs(ctx context.Context) ([]websocket.HubStats, error)
+}
+
+// NewGetCollabStatsHandler lists the collaboration hubs open on this instance
+// @Summary Get collaboration stats
+// @Description Retrieve the open collaboration hubs with their clients and traffic, and the totals of this instance
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} responses.GetCollabStatsResponse "Stats retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Admin access required"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/collab/hubs [get]
+func NewGetCollabStatsHandler(service collabStatsService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		summary, err := service.Stats(c.Request.Context())
+		if err != nil {
+			logger.Error("failed to get collaboration stats", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get collaboration stats"})
+			return
+		}
+
+		hubs, err := service.Hubs(c.Request.Context())
+		if err != nil {
+			logger.Error("failed to list collaboration hubs", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get collaboration stats"})
+			return
+		}
+
+		response := responses.GetCollabStatsResponse{
+			Summary: summary,
+			Hubs:    hubs,
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
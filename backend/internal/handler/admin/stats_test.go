@@ -0,0 +1,102 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/admin"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/admin/responses"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/websocket"
+	"go.uber.org/zap"
+)
+
+type mockCollabStatsService struct {
+	mock.Mock
+}
+
+func (m *mockCollabStatsService) Stats(ctx context.Context) (websocket.Stats, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(websocket.Stats), args.Error(1) //nolint:errcheck
+}
+
+func (m *mockCollabStatsService) Hubs(ctx context.Context) ([]websocket.HubStats, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]websocket.HubStats), args.Error(1) //nolint:errcheck
+}
+
+func TestNewGetCollabStatsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockCollabStatsService, gin.HandlerFunc) {
+		mockService := &mockCollabStatsService{}
+		handler := admin.NewGetCollabStatsHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/admin/collab/hubs", nil)
+		handler(c)
+		return w
+	}
+
+	t.Run("SuccessfulGetStats", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		userUUID := uuid.New()
+		mockService.On("Stats", mock.Anything).Return(websocket.Stats{
+			Hubs:     1,
+			Clients:  1,
+			Delivery: websocket.DeliveryStats{Dropped: 3},
+		}, nil)
+		mockService.On("Hubs", mock.Anything).Return([]websocket.HubStats{{
+			DocumentID: documentUUID,
+			Version:    4,
+			Clients:    []websocket.ClientStats{{UserID: userUUID, UserName: "alice", Role: "editor", CanEdit: true}},
+		}}, nil)
+
+		// Act
+		w := serve(handler)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response responses.GetCollabStatsResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Summary.Hubs)
+		assert.EqualValues(t, 3, response.Summary.Delivery.Dropped)
+		assert.Len(t, response.Hubs, 1)
+		assert.Equal(t, documentUUID, response.Hubs[0].DocumentID)
+		assert.Equal(t, "alice", response.Hubs[0].Clients[0].UserName)
+		assert.Equal(t, "editor", response.Hubs[0].Clients[0].Role)
+	})
+
+	t.Run("ServiceError", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+		mockService.On("Stats", mock.Anything).Return(websocket.Stats{}, errors.New("timeout"))
+
+		// Act
+		w := serve(handler)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
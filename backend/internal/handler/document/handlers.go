@@ -1,6 +1,9 @@
 package document
 
 import (
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/document/responses"
 )
@@ -22,6 +25,8 @@ func mapDocumentToGetResponse(document *domain.Document) responses.GetDocumentRe
 		Name:      document.Name,
 		Content:   document.Content,
 		CreatedAt: document.CreatedAt,
+		Tags:      document.Tags,
+		Version:   document.Version,
 	}
 }
 
@@ -32,6 +37,8 @@ func mapDocumentToUpdateResponse(document *domain.Document) responses.UpdateDocu
 		Name:      document.Name,
 		Content:   document.Content,
 		CreatedAt: document.CreatedAt,
+		Tags:      document.Tags,
+		Version:   document.Version,
 	}
 }
 
@@ -42,3 +49,130 @@ func mapDocumentsToGetAllResponse(documents []*domain.Document) []responses.GetD
 	}
 	return result
 }
+
+func mapHistoryToGetResponse(docUUID uuid.UUID, entries []domain.DocumentHistoryEntry) responses.GetDocumentHistoryResponse {
+	result := make([]responses.DocumentHistoryEntryResponse, len(entries))
+	for i, entry := range entries {
+		result[i] = responses.DocumentHistoryEntryResponse{
+			UserUUID:    entry.UserUUID,
+			UserLogin:   entry.UserLogin,
+			FromVersion: entry.FromVersion,
+			ToVersion:   entry.ToVersion,
+			Updates:     entry.Updates,
+			Size:        entry.Size,
+			StartedAt:   entry.StartedAt,
+			EndedAt:     entry.EndedAt,
+		}
+	}
+	return responses.GetDocumentHistoryResponse{
+		DocumentUUID: docUUID,
+		Entries:      result,
+	}
+}
+
+func mapRestoredDocumentToResponse(restored *domain.RestoredDocument) responses.RestoreDocumentResponse {
+	return responses.RestoreDocumentResponse{
+		UUID:            restored.UUID,
+		RestoredVersion: restored.RestoredVersion,
+		Version:         restored.Version,
+		Content:         restored.Content,
+	}
+}
+
+func mapVersionToResponse(version *domain.DocumentVersion) responses.DocumentVersionResponse {
+	return responses.DocumentVersionResponse{
+		UUID:         version.UUID,
+		DocumentUUID: version.DocumentUUID,
+		Name:         version.Name,
+		Version:      version.Version,
+		CreatedBy:    version.CreatedBy,
+		CreatedAt:    version.CreatedAt,
+	}
+}
+
+func mapVersionToGetResponse(version *domain.DocumentVersion) responses.GetDocumentVersionResponse {
+	return responses.GetDocumentVersionResponse{
+		DocumentVersionResponse: mapVersionToResponse(version),
+		Content:                 version.Content,
+	}
+}
+
+func mapVersionsToGetAllResponse(versions []*domain.DocumentVersion) responses.GetAllDocumentVersionsResponse {
+	result := make([]responses.DocumentVersionResponse, len(versions))
+	for i, version := range versions {
+		result[i] = mapVersionToResponse(version)
+	}
+	return responses.GetAllDocumentVersionsResponse{Versions: result}
+}
+
+func mapVersionDiffToResponse(diff *domain.DocumentVersionDiff) responses.DiffDocumentVersionsResponse {
+	lines := make([]responses.DiffLineResponse, len(diff.Lines))
+	for i, line := range diff.Lines {
+		lines[i] = responses.DiffLineResponse{Op: line.Op, Text: line.Text}
+	}
+
+	response := responses.DiffDocumentVersionsResponse{
+		From:    diff.FromUUID,
+		Added:   diff.Added,
+		Removed: diff.Removed,
+		Lines:   lines,
+	}
+	if diff.ToUUID != uuid.Nil {
+		response.To = &diff.ToUUID
+	}
+	return response
+}
+
+func mapPresenceToGetResponse(presences []*domain.Presence) responses.GetDocumentPresenceResponse {
+	result := make([]responses.PresenceResponse, len(presences))
+	for i, presence := range presences {
+		result[i] = responses.PresenceResponse{
+			DocumentUUID:   presence.DocumentUUID,
+			UserUUID:       presence.UserUUID,
+			UserLogin:      presence.UserLogin,
+			CursorPosition: presence.CursorPosition,
+			LastSeen:       presence.LastSeen,
+		}
+	}
+	return responses.GetDocumentPresenceResponse{Presence: result}
+}
+
+func mapShareLinksToResponse(links []*domain.ShareLink) responses.GetShareLinksResponse {
+	result := make([]responses.ShareLinkResponse, len(links))
+	for i, link := range links {
+		result[i] = responses.ShareLinkResponse{
+			UUID:              link.UUID,
+			DocumentUUID:      link.DocumentUUID,
+			URL:               link.URL,
+			Role:              link.Role,
+			ExpiresAt:         link.ExpiresAt,
+			MaxUses:           link.MaxUses,
+			Uses:              link.Uses,
+			Revoked:           link.Revoked,
+			CreatedBy:         link.CreatedBy,
+			CreatedAt:         link.CreatedAt,
+			PasswordProtected: link.PasswordHash != "",
+		}
+	}
+	return responses.GetShareLinksResponse{Links: result}
+}
+
+func mapShareLinkAnalyticsToResponse(docUUID uuid.UUID, analytics *domain.ShareLinkAnalytics) responses.GetShareLinkAnalyticsResponse {
+	days := make([]responses.ShareLinkDailyAccessesResponse, len(analytics.Days))
+	for i, daily := range analytics.Days {
+		days[i] = responses.ShareLinkDailyAccessesResponse{
+			Date:           daily.Day.Format(time.DateOnly),
+			Accesses:       daily.Accesses,
+			UniqueVisitors: daily.UniqueVisitors,
+			REST:           daily.Accesses - daily.WebSocket,
+			WebSocket:      daily.WebSocket,
+		}
+	}
+	return responses.GetShareLinkAnalyticsResponse{
+		DocumentUUID:   docUUID,
+		From:           analytics.From,
+		Accesses:       analytics.Accesses,
+		UniqueVisitors: analytics.UniqueVisitors,
+		Days:           days,
+	}
+}
@@ -0,0 +1,185 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/document/requests"
+	documentservice "github.com/ukma-cs-ssdm-2025/team-circus/internal/service/document"
+	"go.uber.org/zap"
+)
+
+type documentHistoryService interface {
+	GetHistory(ctx context.Context, docUUID, userUUID uuid.UUID, window time.Duration) ([]domain.DocumentHistoryEntry, error)
+}
+
+type restoreDocumentService interface {
+	Restore(ctx context.Context, docUUID, userUUID uuid.UUID, version int, at time.Time) (*domain.RestoredDocument, error)
+}
+
+// NewGetDocumentHistoryHandler returns the edit timeline of a document
+// @Summary Get document history
+// @Description Retrieve the stored updates of a document grouped by user and time window
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Document UUID"
+// @Param window query string false "Maximum gap between grouped updates, e.g. 5m"
+// @Success 200 {object} responses.GetDocumentHistoryResponse "History retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format or window"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Access forbidden"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /documents/{uuid}/history [get]
+func NewGetDocumentHistoryHandler(service documentHistoryService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUIDValue, exists := c.Get("user_uid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+
+		userUUID, ok := userUUIDValue.(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user context"})
+			return
+		}
+
+		uuidParam := c.Param("uuid")
+		docUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("get document history handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		var window time.Duration
+		if windowParam := c.Query("window"); windowParam != "" {
+			window, err = time.ParseDuration(windowParam)
+			if err != nil || window <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid window"})
+				return
+			}
+		}
+
+		entries, err := service.GetHistory(c.Request.Context(), docUUID, userUUID, window)
+		switch {
+		case errors.Is(err, domain.ErrDocumentNotFound):
+			logger.Warn("document not found", zap.String("uuid", uuidParam))
+			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
+			return
+		case err != nil:
+			logger.Error("failed to get document history", zap.Error(err), zap.String("uuid", uuidParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get document history"})
+			return
+		}
+
+		response := mapHistoryToGetResponse(docUUID, entries)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// NewRestoreDocumentHandler restores a document to an earlier version
+// @Summary Restore a document
+// @Description Restore the document text as of a version or timestamp. The restore is stored as a new version.
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Document UUID"
+// @Param request body requests.RestoreDocumentRequest true "Restore point"
+// @Success 200 {object} responses.RestoreDocumentResponse "Document restored successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format or restore point"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Access forbidden"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Failure 410 {object} map[string]interface{} "History no longer available"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /documents/{uuid}/restore [post]
+func NewRestoreDocumentHandler(service restoreDocumentService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role, exists := c.Get("user_role"); exists {
+			if roleStr, ok := role.(string); ok && roleStr == domain.RoleViewer {
+				c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
+				return
+			}
+		}
+
+		uuidParam := c.Param("uuid")
+		docUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("restore document handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		userUUIDValue, exists := c.Get("user_uid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+
+		userUUID, ok := userUUIDValue.(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user context"})
+			return
+		}
+
+		var req requests.RestoreDocumentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = fmt.Errorf("restore document handler: failed to bind request: %v", err)
+			logger.Error("failed to bind request", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			err = fmt.Errorf("restore document handler: validation failed: %v", err)
+			logger.Error("validation failed", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "details": err.Error()})
+			return
+		}
+
+		var at time.Time
+		if req.At != nil {
+			at = *req.At
+		}
+
+		restored, err := service.Restore(c.Request.Context(), docUUID, userUUID, req.Version, at)
+		switch {
+		case errors.Is(err, domain.ErrDocumentNotFound):
+			logger.Warn("document not found for restore", zap.String("uuid", uuidParam))
+			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
+			return
+		case errors.Is(err, documentservice.ErrInvalidRestorePoint):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restore point"})
+			return
+		case errors.Is(err, domain.ErrHistoryPruned):
+			c.JSON(http.StatusGone, gin.H{"error": "document history no longer available"})
+			return
+		case err != nil:
+			logger.Error("failed to restore document", zap.Error(err), zap.String("uuid", uuidParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore document"})
+			return
+		}
+
+		response := mapRestoredDocumentToResponse(restored)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,262 @@
+package document_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/document"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/document/responses"
+	documentservice "github.com/ukma-cs-ssdm-2025/team-circus/internal/service/document"
+	"go.uber.org/zap"
+)
+
+type mockDocumentHistoryService struct {
+	mock.Mock
+}
+
+func (m *mockDocumentHistoryService) GetHistory(ctx context.Context, docUUID, userUUID uuid.UUID,
+	window time.Duration) ([]domain.DocumentHistoryEntry, error) {
+	args := m.Called(ctx, docUUID, userUUID, window)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.DocumentHistoryEntry), args.Error(1) //nolint:errcheck
+}
+
+func (m *mockDocumentHistoryService) Restore(ctx context.Context, docUUID, userUUID uuid.UUID,
+	version int, at time.Time) (*domain.RestoredDocument, error) {
+	args := m.Called(ctx, docUUID, userUUID, version, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RestoredDocument), args.Error(1) //nolint:errcheck
+}
+
+func TestNewGetDocumentHistoryHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockDocumentHistoryService, gin.HandlerFunc) {
+		mockService := &mockDocumentHistoryService{}
+		handler := document.NewGetDocumentHistoryHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, docParam, query string, userUUID uuid.UUID) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/documents/"+docParam+"/history"+query, nil)
+		c.Params = gin.Params{{Key: "uuid", Value: docParam}}
+		c.Set("user_uid", userUUID)
+		handler(c)
+		return w
+	}
+
+	t.Run("SuccessfulGet", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		userUUID := uuid.New()
+		startedAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		entries := []domain.DocumentHistoryEntry{{
+			UserUUID:    userUUID,
+			UserLogin:   "alice",
+			FromVersion: 2,
+			ToVersion:   5,
+			Updates:     4,
+			Size:        120,
+			StartedAt:   startedAt,
+			EndedAt:     startedAt.Add(time.Minute),
+		}}
+
+		mockService.On("GetHistory", mock.Anything, documentUUID, userUUID, 10*time.Minute).Return(entries, nil)
+
+		// Act
+		w := serve(handler, documentUUID.String(), "?window=10m", userUUID)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response responses.GetDocumentHistoryResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, documentUUID, response.DocumentUUID)
+		assert.Len(t, response.Entries, 1)
+		assert.Equal(t, "alice", response.Entries[0].UserLogin)
+		assert.Equal(t, 2, response.Entries[0].FromVersion)
+		assert.Equal(t, 5, response.Entries[0].ToVersion)
+	})
+
+	t.Run("InvalidWindow", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		// Act
+		w := serve(handler, uuid.New().String(), "?window=soon", uuid.New())
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetHistory")
+	})
+
+	t.Run("InvalidUUID", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		// Act
+		w := serve(handler, "invalid-uuid", "", uuid.New())
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetHistory")
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		userUUID := uuid.New()
+		mockService.On("GetHistory", mock.Anything, documentUUID, userUUID, time.Duration(0)).Return(nil, domain.ErrForbidden)
+
+		// Act
+		w := serve(handler, documentUUID.String(), "", userUUID)
+
+		// Assert
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestNewRestoreDocumentHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockDocumentHistoryService, gin.HandlerFunc) {
+		mockService := &mockDocumentHistoryService{}
+		handler := document.NewRestoreDocumentHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, docUUID uuid.UUID, body string, userUUID uuid.UUID) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/documents/"+docUUID.String()+"/restore", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{{Key: "uuid", Value: docUUID.String()}}
+		c.Set("user_uid", userUUID)
+		handler(c)
+		return w
+	}
+
+	t.Run("RestoreByVersion", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		userUUID := uuid.New()
+		mockService.On("Restore", mock.Anything, documentUUID, userUUID, 3, time.Time{}).Return(&domain.RestoredDocument{
+			UUID:            documentUUID,
+			RestoredVersion: 3,
+			Version:         9,
+			Content:         "old text",
+		}, nil)
+
+		// Act
+		w := serve(handler, documentUUID, `{"version": 3}`, userUUID)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response responses.RestoreDocumentResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 3, response.RestoredVersion)
+		assert.Equal(t, 9, response.Version)
+		assert.Equal(t, "old text", response.Content)
+	})
+
+	t.Run("RestoreByTime", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		userUUID := uuid.New()
+		at := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+		mockService.On("Restore", mock.Anything, documentUUID, userUUID, 0, at).Return(&domain.RestoredDocument{
+			UUID:            documentUUID,
+			RestoredVersion: 4,
+			Version:         9,
+		}, nil)
+
+		// Act
+		w := serve(handler, documentUUID, `{"at": "2025-01-01T10:00:00Z"}`, userUUID)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("ValidationFailed", func(t *testing.T) {
+		cases := map[string]string{
+			"Empty":           `{}`,
+			"Both":            `{"version": 3, "at": "2025-01-01T10:00:00Z"}`,
+			"NegativeVersion": `{"version": -1}`,
+		}
+		for name, body := range cases {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				mockService, handler := setup(t)
+
+				// Act
+				w := serve(handler, uuid.New(), body, uuid.New())
+
+				// Assert
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				mockService.AssertNotCalled(t, "Restore")
+			})
+		}
+	})
+
+	t.Run("ErrorMapping", func(t *testing.T) {
+		cases := map[string]struct {
+			err    error
+			status int
+		}{
+			"NotFound":     {domain.ErrDocumentNotFound, http.StatusNotFound},
+			"Forbidden":    {domain.ErrForbidden, http.StatusForbidden},
+			"InvalidPoint": {documentservice.ErrInvalidRestorePoint, http.StatusBadRequest},
+			"Pruned":       {domain.ErrHistoryPruned, http.StatusGone},
+			"Internal":     {errors.Join(domain.ErrInternal, errors.New("db down")), http.StatusInternalServerError},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				mockService, handler := setup(t)
+
+				documentUUID := uuid.New()
+				userUUID := uuid.New()
+				mockService.On("Restore", mock.Anything, documentUUID, userUUID, 2, time.Time{}).Return(nil, tc.err)
+
+				// Act
+				w := serve(handler, documentUUID, `{"version": 2}`, userUUID)
+
+				// Assert
+				assert.Equal(t, tc.status, w.Code)
+			})
+		}
+	})
+}
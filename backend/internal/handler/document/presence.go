@@ -0,0 +1,75 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"go.uber.org/zap"
+)
+
+type getDocumentPresenceService interface {
+	GetPresence(ctx context.Context, docUUID, userUUID uuid.UUID) ([]*domain.Presence, error)
+}
+
+// NewGetDocumentPresenceHandler lists who currently has a document open
+// @Summary Get document presence
+// @Description Retrieve the users that currently have the document open in the editor
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Document UUID"
+// @Success 200 {object} responses.GetDocumentPresenceResponse "Presence retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Access forbidden"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /documents/{uuid}/presence [get]
+func NewGetDocumentPresenceHandler(service getDocumentPresenceService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUIDValue, exists := c.Get("user_uid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+
+		userUUID, ok := userUUIDValue.(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user context"})
+			return
+		}
+
+		uuidParam := c.Param("uuid")
+		docUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("get document presence handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		presences, err := service.GetPresence(c.Request.Context(), docUUID, userUUID)
+		switch {
+		case errors.Is(err, domain.ErrDocumentNotFound):
+			logger.Warn("document not found", zap.String("uuid", uuidParam))
+			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
+			return
+		case err != nil:
+			logger.Error("failed to get document presence", zap.Error(err), zap.String("uuid", uuidParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get document presence"})
+			return
+		}
+
+		response := mapPresenceToGetResponse(presences)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,141 @@
+package document_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/document"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/document/responses"
+	"go.uber.org/zap"
+)
+
+type mockDocumentPresenceService struct {
+	mock.Mock
+}
+
+func (m *mockDocumentPresenceService) GetPresence(ctx context.Context, docUUID, userUUID uuid.UUID) ([]*domain.Presence, error) {
+	args := m.Called(ctx, docUUID, userUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Presence), args.Error(1) //nolint:errcheck
+}
+
+func TestNewGetDocumentPresenceHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockDocumentPresenceService, gin.HandlerFunc) {
+		mockService := &mockDocumentPresenceService{}
+		handler := document.NewGetDocumentPresenceHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, docParam string, userUUID uuid.UUID) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/documents/"+docParam+"/presence", nil)
+		c.Params = gin.Params{{Key: "uuid", Value: docParam}}
+		c.Set("user_uid", userUUID)
+		handler(c)
+		return w
+	}
+
+	t.Run("SuccessfulGetPresence", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		userUUID := uuid.New()
+		collaboratorUUID := uuid.New()
+		lastSeen := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		mockService.On("GetPresence", mock.Anything, documentUUID, userUUID).Return([]*domain.Presence{
+			{
+				DocumentUUID: documentUUID,
+				UserUUID:     collaboratorUUID,
+				UserLogin:    "alice",
+				LastSeen:     lastSeen,
+			},
+		}, nil)
+
+		// Act
+		w := serve(handler, documentUUID.String(), userUUID)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response responses.GetDocumentPresenceResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Presence, 1)
+		assert.Equal(t, collaboratorUUID, response.Presence[0].UserUUID)
+		assert.Equal(t, "alice", response.Presence[0].UserLogin)
+		assert.Nil(t, response.Presence[0].CursorPosition)
+		assert.True(t, lastSeen.Equal(response.Presence[0].LastSeen))
+	})
+
+	t.Run("EmptyPresence", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		userUUID := uuid.New()
+		mockService.On("GetPresence", mock.Anything, documentUUID, userUUID).Return([]*domain.Presence{}, nil)
+
+		// Act
+		w := serve(handler, documentUUID.String(), userUUID)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"presence": []}`, w.Body.String())
+	})
+
+	t.Run("InvalidUUID", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		// Act
+		w := serve(handler, "invalid-uuid", uuid.New())
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetPresence")
+	})
+
+	t.Run("ErrorMapping", func(t *testing.T) {
+		cases := map[string]struct {
+			err    error
+			status int
+		}{
+			"NotFound":  {domain.ErrDocumentNotFound, http.StatusNotFound},
+			"Forbidden": {domain.ErrForbidden, http.StatusForbidden},
+			"Internal":  {domain.ErrInternal, http.StatusInternalServerError},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				mockService, handler := setup(t)
+
+				documentUUID := uuid.New()
+				userUUID := uuid.New()
+				mockService.On("GetPresence", mock.Anything, documentUUID, userUUID).Return(nil, tc.err)
+
+				// Act
+				w := serve(handler, documentUUID.String(), userUUID)
+
+				// Assert
+				assert.Equal(t, tc.status, w.Code)
+			})
+		}
+	})
+}
@@ -0,0 +1,20 @@
+package requests
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// RestoreDocumentRequest selects the restore point either by version or by time.
+type RestoreDocumentRequest struct {
+	Version int        `json:"version"`
+	At      *time.Time `json:"at"`
+}
+
+func (r RestoreDocumentRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Version, validation.Min(0), validation.When(r.At == nil, validation.Required)),
+		validation.Field(&r.At, validation.When(r.Version != 0, validation.Nil)),
+	)
+}
@@ -1,5 +1,35 @@
 package requests
 
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+)
+
 type ShareDocumentRequest struct {
 	ExpirationDays int `json:"expiration_days"`
+	// Role is what the link lets guests do; empty shares the document read-only.
+	Role string `json:"role"`
+	// MaxUses caps how often the link can be opened; omitted is unlimited.
+	MaxUses *int `json:"max_uses"`
+	// Password must be entered before the link opens; omitted needs none.
+	Password string `json:"password"`
+}
+
+func (r ShareDocumentRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Role, validation.In(domain.RoleViewer, domain.RoleCommenter, domain.RoleEditor)),
+		validation.Field(&r.MaxUses, validation.NilOrNotEmpty, validation.Min(1)),
+		validation.Field(&r.Password, validation.Length(1, 72)),
+	)
+}
+
+type UnlockShareLinkRequest struct {
+	Password string `json:"password"`
+}
+
+func (r UnlockShareLinkRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Password, validation.Required, validation.Length(1, 72)),
+	)
 }
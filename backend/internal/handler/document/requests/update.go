@@ -5,13 +5,21 @@ import (
 )
 
 type UpdateDocumentRequest struct {
-	Name    string `json:"name"`
-	Content string `json:"content"`
+	Name string `json:"name"`
+	// Content is the new text; leaving it out keeps the current text.
+	Content *string `json:"content"`
+	// BaseVersion is the document version Content was edited from. When set,
+	// the update is refused if the document changed since.
+	BaseVersion *int `json:"base_version"`
+	// Tags replace the tags of the document; leaving them out keeps them.
+	Tags []string `json:"tags"`
 }
 
 func (r UpdateDocumentRequest) Validate() error {
 	return validation.ValidateStruct(&r,
 		validation.Field(&r.Name, validation.Required, validation.Length(1, 255)),
 		validation.Field(&r.Content),
+		validation.Field(&r.BaseVersion, validation.Min(0)),
+		validation.Field(&r.Tags, validation.Length(0, 20), validation.Each(validation.Required, validation.Length(1, 50))),
 	)
 }
@@ -0,0 +1,15 @@
+package requests
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type CreateDocumentVersionRequest struct {
+	Name string `json:"name"`
+}
+
+func (r CreateDocumentVersionRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Name, validation.Required, validation.Length(1, 255)),
+	)
+}
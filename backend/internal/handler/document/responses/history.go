@@ -0,0 +1,30 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DocumentHistoryEntryResponse struct {
+	UserUUID    uuid.UUID `json:"user_uuid"`
+	UserLogin   string    `json:"user_login"`
+	FromVersion int       `json:"from_version"`
+	ToVersion   int       `json:"to_version"`
+	Updates     int       `json:"updates"`
+	Size        int       `json:"size"`
+	StartedAt   time.Time `json:"started_at"`
+	EndedAt     time.Time `json:"ended_at"`
+}
+
+type GetDocumentHistoryResponse struct {
+	DocumentUUID uuid.UUID                      `json:"document_uuid"`
+	Entries      []DocumentHistoryEntryResponse `json:"entries"`
+}
+
+type RestoreDocumentResponse struct {
+	UUID            uuid.UUID `json:"uuid"`
+	RestoredVersion int       `json:"restored_version"`
+	Version         int       `json:"version"`
+	Content         string    `json:"content"`
+}
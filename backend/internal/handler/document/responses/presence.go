@@ -0,0 +1,19 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PresenceResponse struct {
+	DocumentUUID   uuid.UUID `json:"document_uuid"`
+	UserUUID       uuid.UUID `json:"user_uuid"`
+	UserLogin      string    `json:"user_login"`
+	CursorPosition *int      `json:"cursor_position"`
+	LastSeen       time.Time `json:"last_seen"`
+}
+
+type GetDocumentPresenceResponse struct {
+	Presence []PresenceResponse `json:"presence"`
+}
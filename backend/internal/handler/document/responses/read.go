@@ -12,6 +12,9 @@ type GetDocumentResponse struct {
 	Name      string    `json:"name"`
 	Content   string    `json:"content"`
 	CreatedAt time.Time `json:"created_at"`
+	Tags      []string  `json:"tags"`
+	// Version is the base version for the next update of the content.
+	Version int `json:"version"`
 }
 
 type GetAllDocumentsResponse struct {
@@ -8,6 +8,64 @@ import (
 
 type ShareDocumentResponse struct {
 	DocumentUUID uuid.UUID `json:"document_uuid"`
+	LinkUUID     uuid.UUID `json:"link_uuid"`
 	URL          string    `json:"url"`
 	ExpiresAt    time.Time `json:"expires_at"`
+	Role         string    `json:"role"`
+	MaxUses      *int      `json:"max_uses"`
+	// PasswordProtected tells that guests must enter a password first.
+	PasswordProtected bool `json:"password_protected"`
+}
+
+type ShareLinkResponse struct {
+	UUID         uuid.UUID `json:"uuid"`
+	DocumentUUID uuid.UUID `json:"document_uuid"`
+	URL          string    `json:"url"`
+	Role         string    `json:"role"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	MaxUses      *int      `json:"max_uses"`
+	Uses         int       `json:"uses"`
+	Revoked      bool      `json:"revoked"`
+	CreatedBy    uuid.UUID `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	// PasswordProtected tells that guests must enter a password first.
+	PasswordProtected bool `json:"password_protected"`
+}
+
+type GetShareLinksResponse struct {
+	Links []ShareLinkResponse `json:"links"`
+}
+
+type RevokeShareLinkResponse struct {
+	Message string `json:"message"`
+}
+
+type ShareLinkDailyAccessesResponse struct {
+	// Date is the UTC day, as YYYY-MM-DD.
+	Date           string `json:"date"`
+	Accesses       int    `json:"accesses"`
+	UniqueVisitors int    `json:"unique_visitors"`
+	REST           int    `json:"rest"`
+	WebSocket      int    `json:"websocket"`
+}
+
+type GetShareLinkAnalyticsResponse struct {
+	DocumentUUID   uuid.UUID                        `json:"document_uuid"`
+	From           time.Time                        `json:"from"`
+	Accesses       int                              `json:"accesses"`
+	UniqueVisitors int                              `json:"unique_visitors"`
+	Days           []ShareLinkDailyAccessesResponse `json:"days"`
+}
+
+// UnlockShareLinkResponse tells until when the guest cookie set by the unlock
+// lets the share link open.
+type UnlockShareLinkResponse struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// GetPublicDocumentResponse is a shared document with the access its link grants.
+type GetPublicDocumentResponse struct {
+	GetDocumentResponse
+	Role    string `json:"role"`
+	CanEdit bool   `json:"can_edit"`
 }
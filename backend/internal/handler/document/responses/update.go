@@ -12,4 +12,7 @@ type UpdateDocumentResponse struct {
 	Name      string    `json:"name"`
 	Content   string    `json:"content"`
 	CreatedAt time.Time `json:"created_at"`
+	Tags      []string  `json:"tags"`
+	// Version is the base version for the next update of the content.
+	Version int `json:"version"`
 }
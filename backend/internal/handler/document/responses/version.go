@@ -0,0 +1,38 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DocumentVersionResponse struct {
+	UUID         uuid.UUID `json:"uuid"`
+	DocumentUUID uuid.UUID `json:"document_uuid"`
+	Name         string    `json:"name"`
+	Version      int       `json:"version"`
+	CreatedBy    uuid.UUID `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type GetDocumentVersionResponse struct {
+	DocumentVersionResponse
+	Content string `json:"content"`
+}
+
+type GetAllDocumentVersionsResponse struct {
+	Versions []DocumentVersionResponse `json:"versions"`
+}
+
+type DiffLineResponse struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type DiffDocumentVersionsResponse struct {
+	From    uuid.UUID          `json:"from"`
+	To      *uuid.UUID         `json:"to"`
+	Added   int                `json:"added"`
+	Removed int                `json:"removed"`
+	Lines   []DiffLineResponse `json:"lines"`
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -19,15 +20,99 @@ import (
 )
 
 type shareDocumentService interface {
-	GenerateShareLink(ctx context.Context, docUUID, userUUID uuid.UUID, expirationDays int) (string, time.Time, error)
+	GenerateShareLink(ctx context.Context, docUUID, userUUID uuid.UUID, opts domain.ShareLinkOptions) (*domain.ShareLink, error)
+}
+
+type getShareLinksService interface {
+	GetShareLinks(ctx context.Context, docUUID, userUUID uuid.UUID) ([]*domain.ShareLink, error)
+}
+
+type revokeShareLinkService interface {
+	RevokeShareLink(ctx context.Context, docUUID, linkUUID, userUUID uuid.UUID) error
+}
+
+type shareLinkAccessRecorder interface {
+	RecordShareLinkAccess(ctx context.Context, docUUID uuid.UUID, query domain.ShareLinkQuery, visit domain.ShareLinkVisit) error
 }
 
 type publicDocumentService interface {
-	ValidateShareLink(ctx context.Context, docParam, sigParam, expParam string) (*domain.Document, error)
+	OpenShareLink(ctx context.Context, query domain.ShareLinkQuery) (*domain.Document, string, *domain.ShareVisit, error)
+	shareLinkAccessRecorder
+}
+
+type unlockShareLinkService interface {
+	UnlockShareLink(ctx context.Context, query domain.ShareLinkQuery, password string) (string, time.Time, error)
+}
+
+type updatePublicDocumentService interface {
+	UpdateShared(ctx context.Context, query domain.ShareLinkQuery, update domain.DocumentUpdate) (*domain.Document, error)
+	shareLinkAccessRecorder
+}
+
+type shareLinkAnalyticsService interface {
+	GetShareLinkAnalytics(ctx context.Context, docUUID, userUUID uuid.UUID, days int) (*domain.ShareLinkAnalytics, error)
+}
+
+// shareLinkQuery reads the parameters of a share link from the query string,
+// and its guest and visit tokens from the link's cookies.
+func shareLinkQuery(c *gin.Context) domain.ShareLinkQuery {
+	query := domain.ShareLinkQuery{
+		Doc:  c.Query("doc"),
+		Sig:  c.Query("sig"),
+		Exp:  c.Query("exp"),
+		Role: c.Query("role"),
+		Link: c.Query("link"),
+	}
+	if query.Link != "" {
+		query.Token, _ = c.Cookie(domain.ShareTokenCookiePrefix + query.Link)
+		query.Visit, _ = c.Cookie(domain.ShareVisitCookiePrefix + query.Link)
+	}
+	return query
+}
+
+// recordShareLinkAccess records a use of a share link over REST. Failing to
+// record it is logged but does not fail the request.
+func recordShareLinkAccess(
+	c *gin.Context,
+	recorder shareLinkAccessRecorder,
+	logger *zap.Logger,
+	docUUID uuid.UUID,
+	query domain.ShareLinkQuery,
+) {
+	visit := domain.ShareLinkVisit{
+		Channel:   domain.ShareChannelREST,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if err := recorder.RecordShareLinkAccess(c.Request.Context(), docUUID, query, visit); err != nil {
+		logger.Warn("failed to record share link access", zap.Error(err), zap.String("uuid", docUUID.String()))
+	}
+}
+
+// writeShareLinkError answers a request whose share link was refused. It
+// reports false when err is not about the link.
+func writeShareLinkError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrShareLinkExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "share link expired"})
+	case errors.Is(err, domain.ErrShareLinkRevoked):
+		c.JSON(http.StatusGone, gin.H{"error": "share link revoked"})
+	case errors.Is(err, domain.ErrShareLinkUsedUp):
+		c.JSON(http.StatusGone, gin.H{"error": "share link has no uses left"})
+	case errors.Is(err, domain.ErrShareLinkInvalid):
+		c.JSON(http.StatusNotFound, gin.H{"error": "invalid share link"})
+	case errors.Is(err, domain.ErrShareLinkLocked):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "share link requires a password"})
+	default:
+		return false
+	}
+	return true
 }
 
 // @Summary Generate a shareable link for a document
-// @Description Create a time-limited, read-only share link for the specified document
+// @Description Create a time-limited share link for the specified document. The link is read-only unless
+// @Description a commenter or editor role is requested, and can be limited to a number of uses or protected
+// @Description with a password.
 // @Tags documents
 // @Accept json
 // @Produce json
@@ -77,12 +162,24 @@ func NewShareDocumentHandler(service shareDocumentService, logger *zap.Logger) g
 			return
 		}
 
-		shareURL, expiresAt, err := service.GenerateShareLink(
-			c.Request.Context(),
-			docUUID,
-			userUUID,
-			req.ExpirationDays,
-		)
+		if err := req.Validate(); err != nil {
+			err = fmt.Errorf("share document handler: validation failed: %v", err)
+			logger.Error("validation failed", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "details": err.Error()})
+			return
+		}
+
+		role := req.Role
+		if role == "" {
+			role = domain.RoleViewer
+		}
+
+		link, err := service.GenerateShareLink(c.Request.Context(), docUUID, userUUID, domain.ShareLinkOptions{
+			ExpirationDays: req.ExpirationDays,
+			Role:           role,
+			MaxUses:        req.MaxUses,
+			Password:       req.Password,
+		})
 		switch {
 		case errors.Is(err, domain.ErrDocumentNotFound):
 			logger.Warn("document not found for share", zap.String("uuid", uuidParam))
@@ -94,6 +191,12 @@ func NewShareDocumentHandler(service shareDocumentService, logger *zap.Logger) g
 		case errors.Is(err, documentservice.ErrInvalidExpiration):
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid expiration range"})
 			return
+		case errors.Is(err, documentservice.ErrInvalidShareRole):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid share role"})
+			return
+		case errors.Is(err, documentservice.ErrInvalidSharePassword):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid share link password"})
+			return
 		case err != nil:
 			logger.Error("failed to generate share link", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate share link"})
@@ -101,9 +204,13 @@ func NewShareDocumentHandler(service shareDocumentService, logger *zap.Logger) g
 		}
 
 		response := responses.ShareDocumentResponse{
-			DocumentUUID: docUUID,
-			URL:          shareURL,
-			ExpiresAt:    expiresAt,
+			DocumentUUID:      docUUID,
+			LinkUUID:          link.UUID,
+			URL:               link.URL,
+			ExpiresAt:         link.ExpiresAt,
+			Role:              link.Role,
+			MaxUses:           link.MaxUses,
+			PasswordProtected: link.PasswordHash != "",
 		}
 
 		c.JSON(http.StatusOK, response)
@@ -111,49 +218,418 @@ func NewShareDocumentHandler(service shareDocumentService, logger *zap.Logger) g
 }
 
 // @Summary Access a shared document
-// @Description Retrieve a document using a public share link. No authentication required.
+// @Description Retrieve a document using a public share link. No authentication required. Opening a stored
+// @Description link counts as one of its uses and sets a visit cookie; requests carrying it, here, over the
+// @Description public websocket or on PUT, do not count again until it expires. Password-protected links
+// @Description need the guest cookie set by POST /documents/public/unlock.
 // @Tags documents
 // @Accept json
 // @Produce json
 // @Param doc query string true "Document UUID from share link"
 // @Param sig query string true "HMAC signature"
 // @Param exp query string true "Expiration timestamp (unix seconds)"
-// @Success 200 {object} responses.GetDocumentResponse "Document retrieved successfully"
+// @Param role query string false "Role granted by the share link; read-only when omitted"
+// @Param link query string false "Stored share link UUID"
+// @Success 200 {object} responses.GetPublicDocumentResponse "Document retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Missing or invalid parameters"
+// @Failure 401 {object} map[string]interface{} "Share link requires a password"
 // @Failure 404 {object} map[string]interface{} "Invalid share link"
-// @Failure 410 {object} map[string]interface{} "Share link expired"
+// @Failure 410 {object} map[string]interface{} "Share link expired, revoked or used up"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /documents/public [get]
 func NewGetPublicDocumentHandler(service publicDocumentService, logger *zap.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		docParam := c.Query("doc")
-		sigParam := c.Query("sig")
-		expParam := c.Query("exp")
+		query := shareLinkQuery(c)
+		if query.Doc == "" || query.Sig == "" || query.Exp == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing required parameters"})
+			return
+		}
 
-		if docParam == "" || sigParam == "" || expParam == "" {
+		document, role, visit, err := service.OpenShareLink(c.Request.Context(), query)
+		if writeShareLinkError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, domain.ErrDocumentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
+			return
+		case err != nil:
+			logger.Error("failed to validate share link", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load document"})
+			return
+		}
+
+		recordShareLinkAccess(c, service, logger, document.UUID, query)
+		if visit != nil {
+			c.SetSameSite(http.SameSiteNoneMode)
+			c.SetCookie(domain.ShareVisitCookiePrefix+query.Link, visit.Token, int(time.Until(visit.ExpiresAt).Seconds()), "/", "", true, true)
+		}
+
+		c.Set("user_role", role)
+		response := responses.GetPublicDocumentResponse{
+			GetDocumentResponse: mapDocumentToGetResponse(document),
+			Role:                role,
+			CanEdit:             role == domain.RoleEditor,
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// @Summary Update a shared document
+// @Description Update a document's name and content through an editor share link. No authentication required;
+// @Description the change is attributed to the share-link guest user. Without the visit cookie of the link, the
+// @Description update counts as a use of it.
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param doc query string true "Document UUID from share link"
+// @Param sig query string true "HMAC signature"
+// @Param exp query string true "Expiration timestamp (unix seconds)"
+// @Param role query string true "Role granted by the share link"
+// @Param link query string false "Stored share link UUID"
+// @Param request body requests.UpdateDocumentRequest true "Document update request"
+// @Success 200 {object} responses.UpdateDocumentResponse "Document updated successfully"
+// @Failure 400 {object} map[string]interface{} "Missing parameters or validation failed"
+// @Failure 401 {object} map[string]interface{} "Share link requires a password"
+// @Failure 403 {object} map[string]interface{} "Share link does not allow editing"
+// @Failure 404 {object} map[string]interface{} "Invalid share link"
+// @Failure 409 {object} map[string]interface{} "Document changed since the base version"
+// @Failure 410 {object} map[string]interface{} "Share link expired, revoked or used up"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /documents/public [put]
+func NewUpdatePublicDocumentHandler(service updatePublicDocumentService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := shareLinkQuery(c)
+		if query.Doc == "" || query.Sig == "" || query.Exp == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "missing required parameters"})
 			return
 		}
 
-		document, err := service.ValidateShareLink(c.Request.Context(), docParam, sigParam, expParam)
+		var req requests.UpdateDocumentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = fmt.Errorf("update public document handler: failed to bind request: %v", err)
+			logger.Error("failed to bind request", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			err = fmt.Errorf("update public document handler: validation failed: %v", err)
+			logger.Error("validation failed", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "details": err.Error()})
+			return
+		}
+
+		document, err := service.UpdateShared(c.Request.Context(), query, domain.DocumentUpdate{
+			Name:        req.Name,
+			Content:     req.Content,
+			BaseVersion: req.BaseVersion,
+		})
+		if writeShareLinkError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, domain.ErrDocumentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "share link does not allow editing"})
+			return
+		case errors.Is(err, domain.ErrVersionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "document changed, reload it and retry"})
+			return
+		case err != nil:
+			logger.Error("failed to update shared document", zap.Error(err), zap.String("uuid", query.Doc))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update document"})
+			return
+		}
+
+		recordShareLinkAccess(c, service, logger, document.UUID, query)
+
+		response := mapDocumentToUpdateResponse(document)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// NewUnlockShareLinkHandler exchanges the password of a share link for a guest cookie
+// @Summary Unlock a password-protected share link
+// @Description Check the password of a share link and set a short-lived guest cookie that lets the link open,
+// @Description both here and over the public websocket. No authentication required. Wrong passwords are
+// @Description throttled per link.
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param doc query string true "Document UUID from share link"
+// @Param sig query string true "HMAC signature"
+// @Param exp query string true "Expiration timestamp (unix seconds)"
+// @Param role query string false "Role granted by the share link; read-only when omitted"
+// @Param link query string true "Stored share link UUID"
+// @Param request body requests.UnlockShareLinkRequest true "Share link password"
+// @Success 200 {object} responses.UnlockShareLinkResponse "Share link unlocked successfully"
+// @Failure 400 {object} map[string]interface{} "Missing parameters, validation failed or link has no password"
+// @Failure 401 {object} map[string]interface{} "Wrong password"
+// @Failure 404 {object} map[string]interface{} "Invalid share link"
+// @Failure 410 {object} map[string]interface{} "Share link expired or revoked"
+// @Failure 429 {object} map[string]interface{} "Too many wrong passwords"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /documents/public/unlock [post]
+func NewUnlockShareLinkHandler(service unlockShareLinkService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := shareLinkQuery(c)
+		if query.Doc == "" || query.Sig == "" || query.Exp == "" || query.Link == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing required parameters"})
+			return
+		}
+
+		var req requests.UnlockShareLinkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = fmt.Errorf("unlock share link handler: failed to bind request: %v", err)
+			logger.Error("failed to bind request", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			err = fmt.Errorf("unlock share link handler: validation failed: %v", err)
+			logger.Error("validation failed", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "details": err.Error()})
+			return
+		}
+
+		token, expiresAt, err := service.UnlockShareLink(c.Request.Context(), query, req.Password)
+		if writeShareLinkError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, documentservice.ErrShareLinkNotProtected):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "share link has no password"})
+			return
+		case errors.Is(err, domain.ErrWrongPassword):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "wrong password"})
+			return
+		case errors.Is(err, domain.ErrTooManyAttempts):
+			logger.Warn("share link unlock throttled", zap.String("link", query.Link))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many failed attempts, try again later"})
+			return
+		case err != nil:
+			logger.Error("failed to unlock share link", zap.Error(err), zap.String("link", query.Link))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unlock share link"})
+			return
+		}
+
+		c.SetSameSite(http.SameSiteNoneMode)
+		c.SetCookie(domain.ShareTokenCookiePrefix+query.Link, token, int(time.Until(expiresAt).Seconds()), "/", "", true, true)
+
+		response := responses.UnlockShareLinkResponse{
+			ExpiresAt: expiresAt,
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// NewGetShareLinksHandler lists the share links of a document
+// @Summary Get document share links
+// @Description List the share links of a document, newest first, including revoked and expired ones.
+// @Description Only editors and authors may list share links.
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Document UUID"
+// @Success 200 {object} responses.GetShareLinksResponse "Share links retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Access forbidden"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /documents/{uuid}/share [get]
+func NewGetShareLinksHandler(service getShareLinksService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uuidParam := c.Param("uuid")
+		docUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("get share links handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		userUUIDValue, exists := c.Get("user_uid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+
+		userUUID, ok := userUUIDValue.(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user context"})
+			return
+		}
+
+		links, err := service.GetShareLinks(c.Request.Context(), docUUID, userUUID)
 		switch {
-		case errors.Is(err, domain.ErrShareLinkExpired):
-			c.JSON(http.StatusGone, gin.H{"error": "share link expired"})
+		case errors.Is(err, domain.ErrDocumentNotFound):
+			logger.Warn("document not found for share links", zap.String("uuid", uuidParam))
+			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
 			return
-		case errors.Is(err, domain.ErrShareLinkInvalid):
-			c.JSON(http.StatusNotFound, gin.H{"error": "invalid share link"})
+		case err != nil:
+			logger.Error("failed to get share links", zap.Error(err), zap.String("uuid", uuidParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get share links"})
 			return
+		}
+
+		response := mapShareLinksToResponse(links)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// NewRevokeShareLinkHandler revokes a share link of a document
+// @Summary Revoke a document share link
+// @Description Stop a share link from working and disconnect the guests connected through it. Only editors and
+// @Description authors may revoke share links.
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Document UUID"
+// @Param link_uuid path string true "Share link UUID"
+// @Success 200 {object} responses.RevokeShareLinkResponse "Share link revoked successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Access forbidden"
+// @Failure 404 {object} map[string]interface{} "Document or share link not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /documents/{uuid}/share/{link_uuid} [delete]
+func NewRevokeShareLinkHandler(service revokeShareLinkService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uuidParam := c.Param("uuid")
+		docUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("revoke share link handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		linkUUID, err := uuid.Parse(c.Param("link_uuid"))
+		if err != nil {
+			err = fmt.Errorf("revoke share link handler: failed to parse link uuid: %v", err)
+			logger.Error("failed to parse link uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		userUUIDValue, exists := c.Get("user_uid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+
+		userUUID, ok := userUUIDValue.(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user context"})
+			return
+		}
+
+		err = service.RevokeShareLink(c.Request.Context(), docUUID, linkUUID, userUUID)
+		switch {
 		case errors.Is(err, domain.ErrDocumentNotFound):
+			logger.Warn("document not found for share link revocation", zap.String("uuid", uuidParam))
 			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
 			return
+		case errors.Is(err, domain.ErrShareLinkNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "share link not found"})
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
+			return
 		case err != nil:
-			logger.Error("failed to validate share link", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load document"})
+			logger.Error("failed to revoke share link", zap.Error(err), zap.String("uuid", uuidParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke share link"})
+			return
+		}
+
+		response := responses.RevokeShareLinkResponse{
+			Message: "Share link revoked successfully",
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// NewGetShareLinkAnalyticsHandler reports how often the share links of a document were used
+// @Summary Get document share link analytics
+// @Description Count the uses of a document's share links per UTC day over the last days, today included,
+// @Description with unique visitors told apart by a hash of their IP address. Only editors and authors may
+// @Description see share link analytics.
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Document UUID"
+// @Param days query int false "Number of days to report, 1 to 365; defaults to 30"
+// @Success 200 {object} responses.GetShareLinkAnalyticsResponse "Analytics retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format or days"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Access forbidden"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /documents/{uuid}/share/analytics [get]
+func NewGetShareLinkAnalyticsHandler(service shareLinkAnalyticsService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uuidParam := c.Param("uuid")
+		docUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("get share link analytics handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		var days int
+		if daysParam := c.Query("days"); daysParam != "" {
+			days, err = strconv.Atoi(daysParam)
+			if err != nil || days <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid days"})
+				return
+			}
+		}
+
+		userUUIDValue, exists := c.Get("user_uid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+
+		userUUID, ok := userUUIDValue.(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user context"})
+			return
+		}
+
+		analytics, err := service.GetShareLinkAnalytics(c.Request.Context(), docUUID, userUUID, days)
+		switch {
+		case errors.Is(err, documentservice.ErrInvalidAnalyticsDays):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid days"})
+			return
+		case errors.Is(err, domain.ErrDocumentNotFound):
+			logger.Warn("document not found for share link analytics", zap.String("uuid", uuidParam))
+			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
+			return
+		case err != nil:
+			logger.Error("failed to get share link analytics", zap.Error(err), zap.String("uuid", uuidParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get share link analytics"})
 			return
 		}
 
-		c.Set("user_role", domain.RoleViewer)
-		response := mapDocumentToGetResponse(document)
+		response := mapShareLinkAnalyticsToResponse(docUUID, analytics)
 
 		c.JSON(http.StatusOK, response)
 	}
@@ -0,0 +1,720 @@
+package document_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/document"
+	documentservice "github.com/ukma-cs-ssdm-2025/team-circus/internal/service/document"
+	"go.uber.org/zap"
+)
+
+type mockShareDocumentService struct {
+	mock.Mock
+}
+
+func (m *mockShareDocumentService) GenerateShareLink(
+	ctx context.Context,
+	docUUID, userUUID uuid.UUID,
+	opts domain.ShareLinkOptions,
+) (*domain.ShareLink, error) {
+	args := m.Called(ctx, docUUID, userUUID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ShareLink), args.Error(1) //nolint:errcheck
+}
+
+func (m *mockShareDocumentService) GetShareLinks(ctx context.Context, docUUID, userUUID uuid.UUID) ([]*domain.ShareLink, error) {
+	args := m.Called(ctx, docUUID, userUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ShareLink), args.Error(1) //nolint:errcheck
+}
+
+func (m *mockShareDocumentService) RevokeShareLink(ctx context.Context, docUUID, linkUUID, userUUID uuid.UUID) error {
+	args := m.Called(ctx, docUUID, linkUUID, userUUID)
+	return args.Error(0)
+}
+
+func (m *mockShareDocumentService) GetShareLinkAnalytics(
+	ctx context.Context,
+	docUUID, userUUID uuid.UUID,
+	days int,
+) (*domain.ShareLinkAnalytics, error) {
+	args := m.Called(ctx, docUUID, userUUID, days)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ShareLinkAnalytics), args.Error(1) //nolint:errcheck
+}
+
+type mockPublicDocumentService struct {
+	mock.Mock
+}
+
+func (m *mockPublicDocumentService) OpenShareLink(
+	ctx context.Context,
+	query domain.ShareLinkQuery,
+) (*domain.Document, string, *domain.ShareVisit, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, "", nil, args.Error(3)
+	}
+	visit, _ := args.Get(2).(*domain.ShareVisit)
+	return args.Get(0).(*domain.Document), args.String(1), visit, args.Error(3) //nolint:errcheck
+}
+
+func (m *mockPublicDocumentService) RecordShareLinkAccess(
+	ctx context.Context,
+	docUUID uuid.UUID,
+	query domain.ShareLinkQuery,
+	visit domain.ShareLinkVisit,
+) error {
+	args := m.Called(ctx, docUUID, query, visit)
+	return args.Error(0)
+}
+
+func (m *mockPublicDocumentService) UnlockShareLink(
+	ctx context.Context,
+	query domain.ShareLinkQuery,
+	password string,
+) (string, time.Time, error) {
+	args := m.Called(ctx, query, password)
+	return args.String(0), args.Get(1).(time.Time), args.Error(2) //nolint:errcheck
+}
+
+func (m *mockPublicDocumentService) UpdateShared(
+	ctx context.Context,
+	query domain.ShareLinkQuery,
+	update domain.DocumentUpdate,
+) (*domain.Document, error) {
+	args := m.Called(ctx, query, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Document), args.Error(1) //nolint:errcheck
+}
+
+func TestNewShareDocumentHandler(main *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockShareDocumentService, gin.HandlerFunc) {
+		mockService := &mockShareDocumentService{}
+		handler := document.NewShareDocumentHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, documentUUID, userUUID uuid.UUID, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/documents/"+documentUUID.String()+"/share", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "uuid", Value: documentUUID.String()}}
+		c.Set("user_uid", userUUID)
+		handler(c)
+		return w
+	}
+
+	main.Run("DefaultsToViewer", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+		documentUUID, userUUID := uuid.New(), uuid.New()
+		link := &domain.ShareLink{
+			UUID:         uuid.New(),
+			DocumentUUID: documentUUID,
+			Role:         domain.RoleViewer,
+			ExpiresAt:    time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+			URL:          "http://example.com/share",
+		}
+		mockService.On("GenerateShareLink", mock.Anything, documentUUID, userUUID, domain.ShareLinkOptions{Role: domain.RoleViewer}).
+			Return(link, nil)
+
+		// Act
+		w := serve(handler, documentUUID, userUUID, "")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, domain.RoleViewer, response["role"])
+		assert.Equal(t, link.UUID.String(), response["link_uuid"])
+		assert.Equal(t, "http://example.com/share", response["url"])
+		assert.Nil(t, response["max_uses"])
+	})
+
+	main.Run("EditorLinkWithMaxUses", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+		documentUUID, userUUID := uuid.New(), uuid.New()
+		maxUses := 3
+		opts := domain.ShareLinkOptions{ExpirationDays: 7, Role: domain.RoleEditor, MaxUses: &maxUses}
+		mockService.On("GenerateShareLink", mock.Anything, documentUUID, userUUID, opts).
+			Return(&domain.ShareLink{UUID: uuid.New(), Role: domain.RoleEditor, MaxUses: &maxUses}, nil)
+
+		// Act
+		w := serve(handler, documentUUID, userUUID, `{"expiration_days": 7, "role": "editor", "max_uses": 3}`)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, domain.RoleEditor, response["role"])
+		assert.EqualValues(t, 3, response["max_uses"])
+	})
+
+	main.Run("InvalidMaxUses", func(t *testing.T) {
+		mockService, handler := setup(t)
+
+		w := serve(handler, uuid.New(), uuid.New(), `{"max_uses": 0}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GenerateShareLink")
+	})
+
+	main.Run("PasswordProtectedLink", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+		documentUUID, userUUID := uuid.New(), uuid.New()
+		opts := domain.ShareLinkOptions{Role: domain.RoleViewer, Password: "open sesame"}
+		mockService.On("GenerateShareLink", mock.Anything, documentUUID, userUUID, opts).
+			Return(&domain.ShareLink{UUID: uuid.New(), Role: domain.RoleViewer, PasswordHash: "hash"}, nil)
+
+		// Act
+		w := serve(handler, documentUUID, userUUID, `{"password": "open sesame"}`)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, true, response["password_protected"])
+		assert.NotContains(t, w.Body.String(), "hash")
+	})
+
+	main.Run("InvalidRole", func(t *testing.T) {
+		mockService, handler := setup(t)
+
+		w := serve(handler, uuid.New(), uuid.New(), `{"role": "author"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GenerateShareLink")
+	})
+}
+
+func TestNewGetPublicDocumentHandler(main *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockPublicDocumentService, gin.HandlerFunc) {
+		mockService := &mockPublicDocumentService{}
+		handler := document.NewGetPublicDocumentHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, query, userAgent string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/documents/public?"+query, nil)
+		c.Request.Header.Set("User-Agent", userAgent)
+		handler(c)
+		return w
+	}
+
+	main.Run("ReportsRoleOfLink", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+		doc := &domain.Document{UUID: uuid.New(), Name: "Shared", Content: "text"}
+		linkUUID := uuid.New().String()
+		query := domain.ShareLinkQuery{Doc: doc.UUID.String(), Sig: "sig", Exp: "123", Role: domain.RoleEditor, Link: linkUUID}
+		mockService.On("OpenShareLink", mock.Anything, query).Return(doc, domain.RoleEditor, nil, nil)
+		visit := domain.ShareLinkVisit{Channel: domain.ShareChannelREST, IP: "192.0.2.1", UserAgent: "Mozilla/5.0"}
+		mockService.On("RecordShareLinkAccess", mock.Anything, doc.UUID, query, visit).Return(nil)
+
+		// Act
+		w := serve(handler, "doc="+doc.UUID.String()+"&sig=sig&exp=123&role=editor&link="+linkUUID, "Mozilla/5.0")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Shared", response["name"])
+		assert.Equal(t, domain.RoleEditor, response["role"])
+		assert.Equal(t, true, response["can_edit"])
+	})
+
+	main.Run("ReadOnlyLink", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+		doc := &domain.Document{UUID: uuid.New(), Name: "Shared"}
+		query := domain.ShareLinkQuery{Doc: doc.UUID.String(), Sig: "sig", Exp: "123"}
+		mockService.On("OpenShareLink", mock.Anything, query).Return(doc, domain.RoleViewer, nil, nil)
+		mockService.On("RecordShareLinkAccess", mock.Anything, doc.UUID, query, mock.Anything).
+			Return(errors.New("db down"))
+
+		// Act
+		w := serve(handler, "doc="+doc.UUID.String()+"&sig=sig&exp=123", "")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, domain.RoleViewer, response["role"])
+		assert.Equal(t, false, response["can_edit"])
+	})
+
+	main.Run("PassesGuestAndVisitCookies", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+		doc := &domain.Document{UUID: uuid.New(), Name: "Shared"}
+		linkUUID := uuid.New().String()
+		query := domain.ShareLinkQuery{
+			Doc: doc.UUID.String(), Sig: "sig", Exp: "123", Link: linkUUID, Token: "guest-token", Visit: "visit-token",
+		}
+		mockService.On("OpenShareLink", mock.Anything, query).Return(doc, domain.RoleViewer, nil, nil)
+		mockService.On("RecordShareLinkAccess", mock.Anything, doc.UUID, query, mock.Anything).Return(nil)
+
+		// Act
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/documents/public?doc="+doc.UUID.String()+"&sig=sig&exp=123&link="+linkUUID, nil)
+		c.Request.AddCookie(&http.Cookie{Name: domain.ShareTokenCookiePrefix + linkUUID, Value: "guest-token"})
+		c.Request.AddCookie(&http.Cookie{Name: domain.ShareVisitCookiePrefix + linkUUID, Value: "visit-token"})
+		handler(c)
+
+		// Assert: no new visit was started
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Result().Cookies())
+	})
+
+	main.Run("SetsVisitCookieWhenUseIsCounted", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+		doc := &domain.Document{UUID: uuid.New(), Name: "Shared"}
+		linkUUID := uuid.New().String()
+		query := domain.ShareLinkQuery{Doc: doc.UUID.String(), Sig: "sig", Exp: "123", Link: linkUUID}
+		visit := &domain.ShareVisit{Token: "visit-token", ExpiresAt: time.Now().Add(time.Hour)}
+		mockService.On("OpenShareLink", mock.Anything, query).Return(doc, domain.RoleViewer, visit, nil)
+		mockService.On("RecordShareLinkAccess", mock.Anything, doc.UUID, query, mock.Anything).Return(nil)
+
+		// Act
+		w := serve(handler, "doc="+doc.UUID.String()+"&sig=sig&exp=123&link="+linkUUID, "")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, domain.ShareVisitCookiePrefix+linkUUID, cookies[0].Name)
+		assert.Equal(t, "visit-token", cookies[0].Value)
+		assert.True(t, cookies[0].HttpOnly)
+	})
+
+	main.Run("RefusedLinks", func(t *testing.T) {
+		tests := []struct {
+			err    error
+			status int
+		}{
+			{err: domain.ErrShareLinkInvalid, status: http.StatusNotFound},
+			{err: domain.ErrShareLinkExpired, status: http.StatusGone},
+			{err: domain.ErrShareLinkRevoked, status: http.StatusGone},
+			{err: domain.ErrShareLinkUsedUp, status: http.StatusGone},
+			{err: domain.ErrShareLinkLocked, status: http.StatusUnauthorized},
+		}
+		for _, tt := range tests {
+			mockService, handler := setup(t)
+			query := domain.ShareLinkQuery{Doc: "doc", Sig: "sig", Exp: "123", Link: "link"}
+			mockService.On("OpenShareLink", mock.Anything, query).Return(nil, "", nil, tt.err)
+
+			w := serve(handler, "doc=doc&sig=sig&exp=123&link=link", "")
+
+			assert.Equal(t, tt.status, w.Code, tt.err.Error())
+			mockService.AssertNotCalled(t, "RecordShareLinkAccess")
+		}
+	})
+}
+
+func TestNewUpdatePublicDocumentHandler(main *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockPublicDocumentService, gin.HandlerFunc) {
+		mockService := &mockPublicDocumentService{}
+		handler := document.NewUpdatePublicDocumentHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	edited := "edited"
+	serve := func(handler gin.HandlerFunc, query, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/documents/public?"+query, bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handler(c)
+		return w
+	}
+
+	main.Run("SuccessfulUpdate", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+		doc := &domain.Document{UUID: uuid.New(), Name: "Shared", Content: "edited"}
+		query := domain.ShareLinkQuery{Doc: doc.UUID.String(), Sig: "sig", Exp: "123", Role: domain.RoleEditor}
+		mockService.On("UpdateShared", mock.Anything, query, domain.DocumentUpdate{Name: "Shared", Content: &edited}).Return(doc, nil)
+		mockService.On("RecordShareLinkAccess", mock.Anything, doc.UUID, query,
+			mock.MatchedBy(func(visit domain.ShareLinkVisit) bool { return visit.Channel == domain.ShareChannelREST })).
+			Return(nil)
+
+		// Act
+		w := serve(handler, "doc="+doc.UUID.String()+"&sig=sig&exp=123&role=editor", `{"name": "Shared", "content": "edited"}`)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "edited", response["content"])
+	})
+
+	main.Run("ReadOnlyLink", func(t *testing.T) {
+		mockService, handler := setup(t)
+		query := domain.ShareLinkQuery{Doc: "doc", Sig: "sig", Exp: "123"}
+		mockService.On("UpdateShared", mock.Anything, query, domain.DocumentUpdate{Name: "Shared", Content: &edited}).Return(nil, domain.ErrForbidden)
+
+		w := serve(handler, "doc=doc&sig=sig&exp=123", `{"name": "Shared", "content": "edited"}`)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	main.Run("RevokedLink", func(t *testing.T) {
+		mockService, handler := setup(t)
+		query := domain.ShareLinkQuery{Doc: "doc", Sig: "sig", Exp: "123", Role: "editor", Link: "link"}
+		mockService.On("UpdateShared", mock.Anything, query, domain.DocumentUpdate{Name: "Shared"}).Return(nil, domain.ErrShareLinkRevoked)
+
+		w := serve(handler, "doc=doc&sig=sig&exp=123&role=editor&link=link", `{"name": "Shared"}`)
+
+		assert.Equal(t, http.StatusGone, w.Code)
+	})
+
+	main.Run("MissingParameters", func(t *testing.T) {
+		mockService, handler := setup(t)
+
+		w := serve(handler, "doc=doc", `{"name": "Shared"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "UpdateShared")
+	})
+}
+
+func TestNewUnlockShareLinkHandler(main *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockPublicDocumentService, gin.HandlerFunc) {
+		mockService := &mockPublicDocumentService{}
+		handler := document.NewUnlockShareLinkHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, query, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/documents/public/unlock?"+query, bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handler(c)
+		return w
+	}
+
+	main.Run("SetsGuestCookie", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+		linkUUID := uuid.New().String()
+		query := domain.ShareLinkQuery{Doc: "doc", Sig: "sig", Exp: "123", Link: linkUUID}
+		expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		mockService.On("UnlockShareLink", mock.Anything, query, "open sesame").Return("guest-token", expiresAt, nil)
+
+		// Act
+		w := serve(handler, "doc=doc&sig=sig&exp=123&link="+linkUUID, `{"password": "open sesame"}`)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, domain.ShareTokenCookiePrefix+linkUUID, cookies[0].Name)
+		assert.Equal(t, "guest-token", cookies[0].Value)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.True(t, cookies[0].Secure)
+	})
+
+	main.Run("RefusedPasswords", func(t *testing.T) {
+		tests := []struct {
+			err    error
+			status int
+		}{
+			{err: domain.ErrWrongPassword, status: http.StatusUnauthorized},
+			{err: domain.ErrTooManyAttempts, status: http.StatusTooManyRequests},
+			{err: domain.ErrShareLinkRevoked, status: http.StatusGone},
+			{err: documentservice.ErrShareLinkNotProtected, status: http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			mockService, handler := setup(t)
+			query := domain.ShareLinkQuery{Doc: "doc", Sig: "sig", Exp: "123", Link: "link"}
+			mockService.On("UnlockShareLink", mock.Anything, query, "guess").Return("", time.Time{}, tt.err)
+
+			w := serve(handler, "doc=doc&sig=sig&exp=123&link=link", `{"password": "guess"}`)
+
+			assert.Equal(t, tt.status, w.Code, tt.err.Error())
+			assert.Empty(t, w.Result().Cookies())
+		}
+	})
+
+	main.Run("MissingPassword", func(t *testing.T) {
+		mockService, handler := setup(t)
+
+		w := serve(handler, "doc=doc&sig=sig&exp=123&link=link", `{}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "UnlockShareLink")
+	})
+
+	main.Run("StatelessLink", func(t *testing.T) {
+		mockService, handler := setup(t)
+
+		w := serve(handler, "doc=doc&sig=sig&exp=123", `{"password": "guess"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "UnlockShareLink")
+	})
+}
+
+func TestNewGetShareLinksHandler(main *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockShareDocumentService, gin.HandlerFunc) {
+		mockService := &mockShareDocumentService{}
+		handler := document.NewGetShareLinksHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, documentUUID, userUUID uuid.UUID) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/documents/"+documentUUID.String()+"/share", nil)
+		c.Params = gin.Params{{Key: "uuid", Value: documentUUID.String()}}
+		c.Set("user_uid", userUUID)
+		handler(c)
+		return w
+	}
+
+	main.Run("SuccessfulGetShareLinks", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+		documentUUID, userUUID := uuid.New(), uuid.New()
+		links := []*domain.ShareLink{
+			{UUID: uuid.New(), DocumentUUID: documentUUID, Role: domain.RoleEditor, Uses: 2, Revoked: true, URL: "http://example.com/a"},
+			{UUID: uuid.New(), DocumentUUID: documentUUID, Role: domain.RoleViewer, URL: "http://example.com/b"},
+		}
+		mockService.On("GetShareLinks", mock.Anything, documentUUID, userUUID).Return(links, nil)
+
+		// Act
+		w := serve(handler, documentUUID, userUUID)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Links []map[string]interface{} `json:"links"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Links, 2)
+		assert.Equal(t, links[0].UUID.String(), response.Links[0]["uuid"])
+		assert.Equal(t, true, response.Links[0]["revoked"])
+		assert.EqualValues(t, 2, response.Links[0]["uses"])
+		assert.Equal(t, "http://example.com/b", response.Links[1]["url"])
+	})
+
+	main.Run("Forbidden", func(t *testing.T) {
+		mockService, handler := setup(t)
+		documentUUID, userUUID := uuid.New(), uuid.New()
+		mockService.On("GetShareLinks", mock.Anything, documentUUID, userUUID).Return(nil, domain.ErrForbidden)
+
+		w := serve(handler, documentUUID, userUUID)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestNewRevokeShareLinkHandler(main *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockShareDocumentService, gin.HandlerFunc) {
+		mockService := &mockShareDocumentService{}
+		handler := document.NewRevokeShareLinkHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, documentUUID uuid.UUID, linkParam string, userUUID uuid.UUID) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("DELETE", "/documents/"+documentUUID.String()+"/share/"+linkParam, nil)
+		c.Params = gin.Params{{Key: "uuid", Value: documentUUID.String()}, {Key: "link_uuid", Value: linkParam}}
+		c.Set("user_uid", userUUID)
+		handler(c)
+		return w
+	}
+
+	main.Run("SuccessfulRevoke", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+		documentUUID, linkUUID, userUUID := uuid.New(), uuid.New(), uuid.New()
+		mockService.On("RevokeShareLink", mock.Anything, documentUUID, linkUUID, userUUID).Return(nil)
+
+		// Act
+		w := serve(handler, documentUUID, linkUUID.String(), userUUID)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	main.Run("LinkNotFound", func(t *testing.T) {
+		mockService, handler := setup(t)
+		documentUUID, linkUUID, userUUID := uuid.New(), uuid.New(), uuid.New()
+		mockService.On("RevokeShareLink", mock.Anything, documentUUID, linkUUID, userUUID).Return(domain.ErrShareLinkNotFound)
+
+		w := serve(handler, documentUUID, linkUUID.String(), userUUID)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	main.Run("InvalidLinkUUID", func(t *testing.T) {
+		mockService, handler := setup(t)
+
+		w := serve(handler, uuid.New(), "not-a-uuid", uuid.New())
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "RevokeShareLink")
+	})
+}
+
+func TestNewGetShareLinkAnalyticsHandler(main *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockShareDocumentService, gin.HandlerFunc) {
+		mockService := &mockShareDocumentService{}
+		handler := document.NewGetShareLinkAnalyticsHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, documentUUID, userUUID uuid.UUID, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/documents/"+documentUUID.String()+"/share/analytics?"+query, nil)
+		c.Params = gin.Params{{Key: "uuid", Value: documentUUID.String()}}
+		c.Set("user_uid", userUUID)
+		handler(c)
+		return w
+	}
+
+	main.Run("SuccessfulGetAnalytics", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+		documentUUID, userUUID := uuid.New(), uuid.New()
+		from := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		analytics := &domain.ShareLinkAnalytics{
+			From:           from,
+			Accesses:       5,
+			UniqueVisitors: 2,
+			Days: []domain.ShareLinkDailyAccesses{
+				{Day: from, Accesses: 3, UniqueVisitors: 2, WebSocket: 1},
+				{Day: from.AddDate(0, 0, 1), Accesses: 2, UniqueVisitors: 1, WebSocket: 2},
+			},
+		}
+		mockService.On("GetShareLinkAnalytics", mock.Anything, documentUUID, userUUID, 2).Return(analytics, nil)
+
+		// Act
+		w := serve(handler, documentUUID, userUUID, "days=2")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Accesses       int                      `json:"accesses"`
+			UniqueVisitors int                      `json:"unique_visitors"`
+			Days           []map[string]interface{} `json:"days"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 5, response.Accesses)
+		assert.Equal(t, 2, response.UniqueVisitors)
+		require.Len(t, response.Days, 2)
+		assert.Equal(t, "2030-01-01", response.Days[0]["date"])
+		assert.EqualValues(t, 2, response.Days[0]["rest"])
+		assert.EqualValues(t, 1, response.Days[0]["websocket"])
+		assert.Equal(t, "2030-01-02", response.Days[1]["date"])
+	})
+
+	main.Run("DefaultsDays", func(t *testing.T) {
+		mockService, handler := setup(t)
+		documentUUID, userUUID := uuid.New(), uuid.New()
+		mockService.On("GetShareLinkAnalytics", mock.Anything, documentUUID, userUUID, 0).
+			Return(&domain.ShareLinkAnalytics{}, nil)
+
+		w := serve(handler, documentUUID, userUUID, "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	main.Run("InvalidDays", func(t *testing.T) {
+		mockService, handler := setup(t)
+
+		w := serve(handler, uuid.New(), uuid.New(), "days=week")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetShareLinkAnalytics")
+	})
+
+	main.Run("TooManyDays", func(t *testing.T) {
+		mockService, handler := setup(t)
+		documentUUID, userUUID := uuid.New(), uuid.New()
+		mockService.On("GetShareLinkAnalytics", mock.Anything, documentUUID, userUUID, 1000).
+			Return(nil, documentservice.ErrInvalidAnalyticsDays)
+
+		w := serve(handler, documentUUID, userUUID, "days=1000")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	main.Run("Forbidden", func(t *testing.T) {
+		mockService, handler := setup(t)
+		documentUUID, userUUID := uuid.New(), uuid.New()
+		mockService.On("GetShareLinkAnalytics", mock.Anything, documentUUID, userUUID, 0).Return(nil, domain.ErrForbidden)
+
+		w := serve(handler, documentUUID, userUUID, "")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -14,12 +14,14 @@ import (
 )
 
 type updateDocumentService interface {
-	Update(ctx context.Context, docUUID, userUUID uuid.UUID, name, content string) (*domain.Document, error)
+	Update(ctx context.Context, docUUID, userUUID uuid.UUID, update domain.DocumentUpdate) (*domain.Document, error)
 }
 
 // NewUpdateDocumentHandler updates a document by UUID
 // @Summary Update a document by UUID
-// @Description Update a specific document's name and content by its UUID
+// @Description Update a specific document's name, content and tags by its UUID. Leaving out content or tags keeps
+// @Description the current ones. With base_version, the content is only written if the document is still at that
+// @Description version.
 // @Tags documents
 // @Accept json
 // @Produce json
@@ -30,6 +32,7 @@ type updateDocumentService interface {
 // @Failure 401 {object} map[string]interface{} "Authentication required"
 // @Failure 403 {object} map[string]interface{} "Access forbidden"
 // @Failure 404 {object} map[string]interface{} "Document not found"
+// @Failure 409 {object} map[string]interface{} "Document changed since the base version"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /documents/{uuid} [put]
 func NewUpdateDocumentHandler(service updateDocumentService, logger *zap.Logger) gin.HandlerFunc {
@@ -77,7 +80,12 @@ func NewUpdateDocumentHandler(service updateDocumentService, logger *zap.Logger)
 			return
 		}
 
-		document, err := service.Update(c.Request.Context(), docUUID, userUUID, req.Name, req.Content)
+		document, err := service.Update(c.Request.Context(), docUUID, userUUID, domain.DocumentUpdate{
+			Name:        req.Name,
+			Content:     req.Content,
+			BaseVersion: req.BaseVersion,
+			Tags:        req.Tags,
+		})
 		if errors.Is(err, domain.ErrDocumentNotFound) {
 			logger.Warn("document not found", zap.String("uuid", uuidParam))
 			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
@@ -87,6 +95,10 @@ func NewUpdateDocumentHandler(service updateDocumentService, logger *zap.Logger)
 			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
 			return
 		}
+		if errors.Is(err, domain.ErrVersionConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "document changed, reload it and retry"})
+			return
+		}
 		if errors.Is(err, domain.ErrInternal) {
 			logger.Error("failed to update document",
 				zap.Error(err),
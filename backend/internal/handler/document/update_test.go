@@ -25,8 +25,8 @@ type mockUpdateDocumentService struct {
 }
 
 func (m *mockUpdateDocumentService) Update(ctx context.Context, docUUID, userUUID uuid.UUID,
-	name, content string) (*domain.Document, error) {
-	args := m.Called(ctx, docUUID, userUUID, name, content)
+	update domain.DocumentUpdate) (*domain.Document, error) {
+	args := m.Called(ctx, docUUID, userUUID, update)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -36,6 +36,9 @@ func (m *mockUpdateDocumentService) Update(ctx context.Context, docUUID, userUUI
 func TestNewUpdateDocumentHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
+	content := "Updated content"
+	update := domain.DocumentUpdate{Name: "Updated Document", Content: &content}
+
 	setup := func(t *testing.T) (*mockUpdateDocumentService, gin.HandlerFunc) {
 		mockService := &mockUpdateDocumentService{}
 		handler := document.NewUpdateDocumentHandler(mockService, zap.NewNop())
@@ -59,11 +62,11 @@ func TestNewUpdateDocumentHandler(t *testing.T) {
 			CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
 		}
 
-		mockService.On("Update", mock.Anything, documentUUID, userUUID, "Updated Document", "Updated content").Return(expectedDocument, nil)
+		mockService.On("Update", mock.Anything, documentUUID, userUUID, update).Return(expectedDocument, nil)
 
 		requestBody := requests.UpdateDocumentRequest{
 			Name:    "Updated Document",
-			Content: "Updated content",
+			Content: &content,
 		}
 
 		jsonBody, err := json.Marshal(requestBody)
@@ -99,7 +102,7 @@ func TestNewUpdateDocumentHandler(t *testing.T) {
 
 		requestBody := requests.UpdateDocumentRequest{
 			Name:    "Updated Document",
-			Content: "Updated content",
+			Content: &content,
 		}
 
 		jsonBody, err := json.Marshal(requestBody)
@@ -167,7 +170,7 @@ func TestNewUpdateDocumentHandler(t *testing.T) {
 		userUUID := uuid.New()
 		requestBody := requests.UpdateDocumentRequest{
 			Name:    "",
-			Content: "Updated content",
+			Content: &content,
 		}
 
 		jsonBody, err := json.Marshal(requestBody)
@@ -203,11 +206,11 @@ func TestNewUpdateDocumentHandler(t *testing.T) {
 		documentUUID := uuid.New()
 		userUUID := uuid.New()
 		mockService.On("Update", mock.Anything, documentUUID, userUUID,
-			"Updated Document", "Updated content").Return(nil, domain.ErrDocumentNotFound)
+			update).Return(nil, domain.ErrDocumentNotFound)
 
 		requestBody := requests.UpdateDocumentRequest{
 			Name:    "Updated Document",
-			Content: "Updated content",
+			Content: &content,
 		}
 
 		jsonBody, err := json.Marshal(requestBody)
@@ -243,11 +246,11 @@ func TestNewUpdateDocumentHandler(t *testing.T) {
 		documentUUID := uuid.New()
 		userUUID := uuid.New()
 		mockService.On("Update", mock.Anything, documentUUID, userUUID,
-			"Updated Document", "Updated content").Return(nil, domain.ErrInternal)
+			update).Return(nil, domain.ErrInternal)
 
 		requestBody := requests.UpdateDocumentRequest{
 			Name:    "Updated Document",
-			Content: "Updated content",
+			Content: &content,
 		}
 
 		jsonBody, err := json.Marshal(requestBody)
@@ -287,13 +290,12 @@ func TestNewUpdateDocumentHandler(t *testing.T) {
 			mock.Anything,
 			documentUUID,
 			userUUID,
-			"Updated Document",
-			"Updated content",
+			update,
 		).Return(nil, errors.New("database connection failed"))
 
 		requestBody := requests.UpdateDocumentRequest{
 			Name:    "Updated Document",
-			Content: "Updated content",
+			Content: &content,
 		}
 
 		jsonBody, err := json.Marshal(requestBody)
@@ -321,4 +323,117 @@ func TestNewUpdateDocumentHandler(t *testing.T) {
 
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("StaleBaseVersion", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		userUUID := uuid.New()
+		baseVersion := 3
+		mockService.On("Update", mock.Anything, documentUUID, userUUID, domain.DocumentUpdate{
+			Name:        "Updated Document",
+			Content:     &content,
+			BaseVersion: &baseVersion,
+		}).Return(nil, domain.ErrVersionConflict)
+
+		body := `{"name": "Updated Document", "content": "Updated content", "base_version": 3}`
+		req := httptest.NewRequest("PUT", "/documents/"+documentUUID.String(), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{{Key: "uuid", Value: documentUUID.String()}}
+		c.Set("user_uid", userUUID)
+
+		// Act
+		handler(c)
+
+		// Assert
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("RenameKeepsContent", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		userUUID := uuid.New()
+		mockService.On("Update", mock.Anything, documentUUID, userUUID, domain.DocumentUpdate{Name: "Renamed"}).
+			Return(&domain.Document{UUID: documentUUID, Name: "Renamed", Content: "kept", Version: 5}, nil)
+
+		req := httptest.NewRequest("PUT", "/documents/"+documentUUID.String(), bytes.NewBufferString(`{"name": "Renamed"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{{Key: "uuid", Value: documentUUID.String()}}
+		c.Set("user_uid", userUUID)
+
+		// Act
+		handler(c)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "kept", response["content"])
+		assert.EqualValues(t, 5, response["version"])
+	})
+
+	t.Run("ReplacesTags", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		userUUID := uuid.New()
+		tags := []string{"handbook", "draft"}
+		mockService.On("Update", mock.Anything, documentUUID, userUUID, domain.DocumentUpdate{Name: "Tagged", Tags: tags}).
+			Return(&domain.Document{UUID: documentUUID, Name: "Tagged", Tags: tags}, nil)
+
+		body := `{"name": "Tagged", "tags": ["handbook", "draft"]}`
+		req := httptest.NewRequest("PUT", "/documents/"+documentUUID.String(), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{{Key: "uuid", Value: documentUUID.String()}}
+		c.Set("user_uid", userUUID)
+
+		// Act
+		handler(c)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, []interface{}{"handbook", "draft"}, response["tags"])
+	})
+
+	t.Run("ValidationFailed_EmptyTag", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		req := httptest.NewRequest("PUT", "/documents/"+documentUUID.String(), bytes.NewBufferString(`{"name": "Tagged", "tags": [""]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{{Key: "uuid", Value: documentUUID.String()}}
+		c.Set("user_uid", uuid.New())
+
+		// Act
+		handler(c)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "Update")
+	})
 }
@@ -0,0 +1,314 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/document/requests"
+	"go.uber.org/zap"
+)
+
+type createDocumentVersionService interface {
+	CreateVersion(ctx context.Context, docUUID, userUUID uuid.UUID, name string) (*domain.DocumentVersion, error)
+}
+
+type getDocumentVersionsService interface {
+	GetVersions(ctx context.Context, docUUID, userUUID uuid.UUID) ([]*domain.DocumentVersion, error)
+}
+
+type getDocumentVersionService interface {
+	GetVersion(ctx context.Context, docUUID, versionUUID, userUUID uuid.UUID) (*domain.DocumentVersion, error)
+}
+
+type diffDocumentVersionsService interface {
+	DiffVersions(ctx context.Context, docUUID, fromUUID, toUUID, userUUID uuid.UUID) (*domain.DocumentVersionDiff, error)
+}
+
+// NewCreateDocumentVersionHandler creates a named checkpoint of a document
+// @Summary Create a document version
+// @Description Capture the current document state and Markdown as a named checkpoint. Only editors and authors may create versions.
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Document UUID"
+// @Param request body requests.CreateDocumentVersionRequest true "Version name"
+// @Success 201 {object} responses.DocumentVersionResponse "Version created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format or validation failed"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Access forbidden"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Failure 409 {object} map[string]interface{} "Version name already used"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /documents/{uuid}/versions [post]
+func NewCreateDocumentVersionHandler(service createDocumentVersionService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uuidParam := c.Param("uuid")
+		docUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("create document version handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		userUUIDValue, exists := c.Get("user_uid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+
+		userUUID, ok := userUUIDValue.(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user context"})
+			return
+		}
+
+		var req requests.CreateDocumentVersionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			err = fmt.Errorf("create document version handler: failed to bind request: %v", err)
+			logger.Error("failed to bind request", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			err = fmt.Errorf("create document version handler: validation failed: %v", err)
+			logger.Error("validation failed", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "details": err.Error()})
+			return
+		}
+
+		version, err := service.CreateVersion(c.Request.Context(), docUUID, userUUID, req.Name)
+		switch {
+		case errors.Is(err, domain.ErrDocumentNotFound):
+			logger.Warn("document not found for version", zap.String("uuid", uuidParam))
+			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
+			return
+		case errors.Is(err, domain.ErrAlreadyExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "version already exists"})
+			return
+		case err != nil:
+			logger.Error("failed to create document version", zap.Error(err), zap.String("uuid", uuidParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create document version"})
+			return
+		}
+
+		response := mapVersionToResponse(version)
+
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+// NewGetDocumentVersionsHandler lists the named checkpoints of a document
+// @Summary Get document versions
+// @Description Retrieve the named checkpoints of a document, newest first
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Document UUID"
+// @Success 200 {object} responses.GetAllDocumentVersionsResponse "Versions retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Access forbidden"
+// @Failure 404 {object} map[string]interface{} "Document not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /documents/{uuid}/versions [get]
+func NewGetDocumentVersionsHandler(service getDocumentVersionsService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUIDValue, exists := c.Get("user_uid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+
+		userUUID, ok := userUUIDValue.(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user context"})
+			return
+		}
+
+		uuidParam := c.Param("uuid")
+		docUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("get document versions handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		versions, err := service.GetVersions(c.Request.Context(), docUUID, userUUID)
+		switch {
+		case errors.Is(err, domain.ErrDocumentNotFound):
+			logger.Warn("document not found", zap.String("uuid", uuidParam))
+			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
+			return
+		case err != nil:
+			logger.Error("failed to get document versions", zap.Error(err), zap.String("uuid", uuidParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get document versions"})
+			return
+		}
+
+		response := mapVersionsToGetAllResponse(versions)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// NewGetDocumentVersionHandler returns a named checkpoint with its Markdown
+// @Summary Get a document version
+// @Description Retrieve a named checkpoint of a document together with its Markdown content
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Document UUID"
+// @Param version_uuid path string true "Version UUID"
+// @Success 200 {object} responses.GetDocumentVersionResponse "Version retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Access forbidden"
+// @Failure 404 {object} map[string]interface{} "Document or version not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /documents/{uuid}/versions/{version_uuid} [get]
+func NewGetDocumentVersionHandler(service getDocumentVersionService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUIDValue, exists := c.Get("user_uid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+
+		userUUID, ok := userUUIDValue.(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user context"})
+			return
+		}
+
+		docUUID, err := uuid.Parse(c.Param("uuid"))
+		if err != nil {
+			err = fmt.Errorf("get document version handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		versionParam := c.Param("version_uuid")
+		versionUUID, err := uuid.Parse(versionParam)
+		if err != nil {
+			err = fmt.Errorf("get document version handler: failed to parse version uuid: %v", err)
+			logger.Error("failed to parse version uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version uuid format"})
+			return
+		}
+
+		version, err := service.GetVersion(c.Request.Context(), docUUID, versionUUID, userUUID)
+		switch {
+		case errors.Is(err, domain.ErrDocumentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
+			return
+		case errors.Is(err, domain.ErrVersionNotFound):
+			logger.Warn("document version not found", zap.String("version_uuid", versionParam))
+			c.JSON(http.StatusNotFound, gin.H{"error": "version not found"})
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
+			return
+		case err != nil:
+			logger.Error("failed to get document version", zap.Error(err), zap.String("version_uuid", versionParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get document version"})
+			return
+		}
+
+		response := mapVersionToGetResponse(version)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// NewDiffDocumentVersionsHandler compares a named checkpoint with another one
+// @Summary Diff document versions
+// @Description Compare the Markdown of a checkpoint line by line with another checkpoint, or with the current document when "to" is omitted
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Document UUID"
+// @Param version_uuid path string true "Version UUID to compare from"
+// @Param to query string false "Version UUID to compare to"
+// @Success 200 {object} responses.DiffDocumentVersionsResponse "Diff computed successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Access forbidden"
+// @Failure 404 {object} map[string]interface{} "Document or version not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /documents/{uuid}/versions/{version_uuid}/diff [get]
+func NewDiffDocumentVersionsHandler(service diffDocumentVersionsService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUIDValue, exists := c.Get("user_uid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+
+		userUUID, ok := userUUIDValue.(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user context"})
+			return
+		}
+
+		docUUID, err := uuid.Parse(c.Param("uuid"))
+		if err != nil {
+			err = fmt.Errorf("diff document versions handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		fromUUID, err := uuid.Parse(c.Param("version_uuid"))
+		if err != nil {
+			err = fmt.Errorf("diff document versions handler: failed to parse version uuid: %v", err)
+			logger.Error("failed to parse version uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version uuid format"})
+			return
+		}
+
+		toUUID := uuid.Nil
+		if toParam := c.Query("to"); toParam != "" {
+			toUUID, err = uuid.Parse(toParam)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version uuid format"})
+				return
+			}
+		}
+
+		diff, err := service.DiffVersions(c.Request.Context(), docUUID, fromUUID, toUUID, userUUID)
+		switch {
+		case errors.Is(err, domain.ErrDocumentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
+			return
+		case errors.Is(err, domain.ErrVersionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "version not found"})
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
+			return
+		case err != nil:
+			logger.Error("failed to diff document versions", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to diff document versions"})
+			return
+		}
+
+		response := mapVersionDiffToResponse(diff)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,290 @@
+package document_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/document"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/document/responses"
+	"go.uber.org/zap"
+)
+
+type mockDocumentVersionService struct {
+	mock.Mock
+}
+
+func (m *mockDocumentVersionService) CreateVersion(ctx context.Context, docUUID, userUUID uuid.UUID,
+	name string) (*domain.DocumentVersion, error) {
+	args := m.Called(ctx, docUUID, userUUID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.DocumentVersion), args.Error(1) //nolint:errcheck
+}
+
+func (m *mockDocumentVersionService) GetVersion(ctx context.Context, docUUID, versionUUID,
+	userUUID uuid.UUID) (*domain.DocumentVersion, error) {
+	args := m.Called(ctx, docUUID, versionUUID, userUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.DocumentVersion), args.Error(1) //nolint:errcheck
+}
+
+func (m *mockDocumentVersionService) DiffVersions(ctx context.Context, docUUID, fromUUID, toUUID,
+	userUUID uuid.UUID) (*domain.DocumentVersionDiff, error) {
+	args := m.Called(ctx, docUUID, fromUUID, toUUID, userUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.DocumentVersionDiff), args.Error(1) //nolint:errcheck
+}
+
+func TestNewCreateDocumentVersionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockDocumentVersionService, gin.HandlerFunc) {
+		mockService := &mockDocumentVersionService{}
+		handler := document.NewCreateDocumentVersionHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, docUUID uuid.UUID, body string, userUUID uuid.UUID) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/documents/"+docUUID.String()+"/versions", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{{Key: "uuid", Value: docUUID.String()}}
+		c.Set("user_uid", userUUID)
+		handler(c)
+		return w
+	}
+
+	t.Run("SuccessfulCreate", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		documentUUID := uuid.New()
+		userUUID := uuid.New()
+		mockService.On("CreateVersion", mock.Anything, documentUUID, userUUID, "v1 sent to client").Return(&domain.DocumentVersion{
+			UUID:         uuid.New(),
+			DocumentUUID: documentUUID,
+			Name:         "v1 sent to client",
+			Version:      7,
+			CreatedBy:    userUUID,
+			CreatedAt:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		}, nil)
+
+		// Act
+		w := serve(handler, documentUUID, `{"name": "v1 sent to client"}`, userUUID)
+
+		// Assert
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response responses.DocumentVersionResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "v1 sent to client", response.Name)
+		assert.Equal(t, 7, response.Version)
+	})
+
+	t.Run("ValidationFailed", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		// Act
+		w := serve(handler, uuid.New(), `{"name": ""}`, uuid.New())
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "CreateVersion")
+	})
+
+	t.Run("ErrorMapping", func(t *testing.T) {
+		cases := map[string]struct {
+			err    error
+			status int
+		}{
+			"NotFound":      {domain.ErrDocumentNotFound, http.StatusNotFound},
+			"Forbidden":     {domain.ErrForbidden, http.StatusForbidden},
+			"AlreadyExists": {domain.ErrAlreadyExists, http.StatusConflict},
+			"Internal":      {domain.ErrInternal, http.StatusInternalServerError},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				mockService, handler := setup(t)
+
+				documentUUID := uuid.New()
+				userUUID := uuid.New()
+				mockService.On("CreateVersion", mock.Anything, documentUUID, userUUID, "v1").Return(nil, tc.err)
+
+				// Act
+				w := serve(handler, documentUUID, `{"name": "v1"}`, userUUID)
+
+				// Assert
+				assert.Equal(t, tc.status, w.Code)
+			})
+		}
+	})
+}
+
+func TestNewGetDocumentVersionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("ReturnsContent", func(t *testing.T) {
+		// Arrange
+		mockService := &mockDocumentVersionService{}
+		handler := document.NewGetDocumentVersionHandler(mockService, zap.NewNop())
+
+		documentUUID := uuid.New()
+		versionUUID := uuid.New()
+		userUUID := uuid.New()
+		mockService.On("GetVersion", mock.Anything, documentUUID, versionUUID, userUUID).Return(&domain.DocumentVersion{
+			UUID:         versionUUID,
+			DocumentUUID: documentUUID,
+			Name:         "v1",
+			Content:      "# Title",
+		}, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/documents/"+documentUUID.String()+"/versions/"+versionUUID.String(), nil)
+		c.Params = gin.Params{{Key: "uuid", Value: documentUUID.String()}, {Key: "version_uuid", Value: versionUUID.String()}}
+		c.Set("user_uid", userUUID)
+
+		// Act
+		handler(c)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response responses.GetDocumentVersionResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "# Title", response.Content)
+		assert.Equal(t, "v1", response.Name)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("VersionNotFound", func(t *testing.T) {
+		// Arrange
+		mockService := &mockDocumentVersionService{}
+		handler := document.NewGetDocumentVersionHandler(mockService, zap.NewNop())
+
+		documentUUID := uuid.New()
+		versionUUID := uuid.New()
+		userUUID := uuid.New()
+		mockService.On("GetVersion", mock.Anything, documentUUID, versionUUID, userUUID).Return(nil, domain.ErrVersionNotFound)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/documents/"+documentUUID.String()+"/versions/"+versionUUID.String(), nil)
+		c.Params = gin.Params{{Key: "uuid", Value: documentUUID.String()}, {Key: "version_uuid", Value: versionUUID.String()}}
+		c.Set("user_uid", userUUID)
+
+		// Act
+		handler(c)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestNewDiffDocumentVersionsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(handler gin.HandlerFunc, docUUID, fromUUID uuid.UUID, query string, userUUID uuid.UUID) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/documents/"+docUUID.String()+"/versions/"+fromUUID.String()+"/diff"+query, nil)
+		c.Params = gin.Params{{Key: "uuid", Value: docUUID.String()}, {Key: "version_uuid", Value: fromUUID.String()}}
+		c.Set("user_uid", userUUID)
+		handler(c)
+		return w
+	}
+
+	t.Run("DiffAgainstCurrentDocument", func(t *testing.T) {
+		// Arrange
+		mockService := &mockDocumentVersionService{}
+		handler := document.NewDiffDocumentVersionsHandler(mockService, zap.NewNop())
+
+		documentUUID := uuid.New()
+		fromUUID := uuid.New()
+		userUUID := uuid.New()
+		mockService.On("DiffVersions", mock.Anything, documentUUID, fromUUID, uuid.Nil, userUUID).Return(&domain.DocumentVersionDiff{
+			FromUUID: fromUUID,
+			Lines: []domain.DiffLine{
+				{Op: "delete", Text: "old"},
+				{Op: "insert", Text: "new"},
+			},
+			Added:   1,
+			Removed: 1,
+		}, nil)
+
+		// Act
+		w := serve(handler, documentUUID, fromUUID, "", userUUID)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response responses.DiffDocumentVersionsResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Nil(t, response.To)
+		assert.Equal(t, 1, response.Added)
+		assert.Len(t, response.Lines, 2)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("DiffAgainstVersion", func(t *testing.T) {
+		// Arrange
+		mockService := &mockDocumentVersionService{}
+		handler := document.NewDiffDocumentVersionsHandler(mockService, zap.NewNop())
+
+		documentUUID := uuid.New()
+		fromUUID := uuid.New()
+		toUUID := uuid.New()
+		userUUID := uuid.New()
+		mockService.On("DiffVersions", mock.Anything, documentUUID, fromUUID, toUUID, userUUID).Return(&domain.DocumentVersionDiff{
+			FromUUID: fromUUID,
+			ToUUID:   toUUID,
+		}, nil)
+
+		// Act
+		w := serve(handler, documentUUID, fromUUID, "?to="+toUUID.String(), userUUID)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response responses.DiffDocumentVersionsResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, &toUUID, response.To)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("InvalidToParam", func(t *testing.T) {
+		// Arrange
+		mockService := &mockDocumentVersionService{}
+		handler := document.NewDiffDocumentVersionsHandler(mockService, zap.NewNop())
+
+		// Act
+		w := serve(handler, uuid.New(), uuid.New(), "?to=nope", uuid.New())
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "DiffVersions")
+	})
+}
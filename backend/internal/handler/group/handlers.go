@@ -7,25 +7,28 @@ import (
 
 func mapGroupToCreateResponse(group *domain.Group) responses.CreateGroupResponse {
 	return responses.CreateGroupResponse{
-		UUID:      group.UUID,
-		Name:      group.Name,
-		CreatedAt: group.CreatedAt,
+		UUID:            group.UUID,
+		Name:            group.Name,
+		MaxDocumentSize: group.MaxDocumentSize,
+		CreatedAt:       group.CreatedAt,
 	}
 }
 
 func mapGroupToGetResponse(group *domain.Group) responses.GetGroupResponse {
 	return responses.GetGroupResponse{
-		UUID:      group.UUID,
-		Name:      group.Name,
-		CreatedAt: group.CreatedAt,
+		UUID:            group.UUID,
+		Name:            group.Name,
+		MaxDocumentSize: group.MaxDocumentSize,
+		CreatedAt:       group.CreatedAt,
 	}
 }
 
 func mapGroupToUpdateResponse(group *domain.Group) responses.UpdateGroupResponse {
 	return responses.UpdateGroupResponse{
-		UUID:      group.UUID,
-		Name:      group.Name,
-		CreatedAt: group.CreatedAt,
+		UUID:            group.UUID,
+		Name:            group.Name,
+		MaxDocumentSize: group.MaxDocumentSize,
+		CreatedAt:       group.CreatedAt,
 	}
 }
 
@@ -36,3 +39,66 @@ func mapGroupsToGetAllResponse(groups []*domain.Group) []responses.GetGroupRespo
 	}
 	return result
 }
+
+func mapPresenceToGetResponse(presences []*domain.Presence) responses.GetGroupPresenceResponse {
+	result := make([]responses.PresenceResponse, len(presences))
+	for i, presence := range presences {
+		result[i] = responses.PresenceResponse{
+			DocumentUUID:   presence.DocumentUUID,
+			UserUUID:       presence.UserUUID,
+			UserLogin:      presence.UserLogin,
+			CursorPosition: presence.CursorPosition,
+			LastSeen:       presence.LastSeen,
+		}
+	}
+	return responses.GetGroupPresenceResponse{Presence: result}
+}
+
+func mapSharedGroupToResponse(group *domain.Group, documents []*domain.Document) responses.GetPublicGroupResponse {
+	result := make([]responses.SharedGroupDocumentResponse, len(documents))
+	for i, document := range documents {
+		result[i] = responses.SharedGroupDocumentResponse{
+			UUID:      document.UUID,
+			Name:      document.Name,
+			Tags:      document.Tags,
+			CreatedAt: document.CreatedAt,
+		}
+	}
+	return responses.GetPublicGroupResponse{
+		UUID:      group.UUID,
+		Name:      group.Name,
+		Documents: result,
+	}
+}
+
+func mapSharedGroupDocumentToResponse(document *domain.Document) responses.GetPublicGroupDocumentResponse {
+	return responses.GetPublicGroupDocumentResponse{
+		UUID:      document.UUID,
+		GroupUUID: document.GroupUUID,
+		Name:      document.Name,
+		Content:   document.Content,
+		Tags:      document.Tags,
+		CreatedAt: document.CreatedAt,
+		Role:      domain.RoleViewer,
+		CanEdit:   false,
+	}
+}
+
+func mapGroupShareLinksToResponse(links []*domain.ShareLink) responses.GetGroupShareLinksResponse {
+	result := make([]responses.GroupShareLinkResponse, len(links))
+	for i, link := range links {
+		result[i] = responses.GroupShareLinkResponse{
+			UUID:      link.UUID,
+			GroupUUID: link.GroupUUID,
+			URL:       link.URL,
+			Tag:       link.Tag,
+			ExpiresAt: link.ExpiresAt,
+			MaxUses:   link.MaxUses,
+			Uses:      link.Uses,
+			Revoked:   link.Revoked,
+			CreatedBy: link.CreatedBy,
+			CreatedAt: link.CreatedAt,
+		}
+	}
+	return responses.GetGroupShareLinksResponse{Links: result}
+}
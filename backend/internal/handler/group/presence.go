@@ -0,0 +1,75 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"go.uber.org/zap"
+)
+
+type getGroupPresenceService interface {
+	GetPresence(ctx context.Context, groupUUID, userUUID uuid.UUID) ([]*domain.Presence, error)
+}
+
+// NewGetGroupPresenceHandler lists who currently has a document of the group open
+// @Summary Get group presence
+// @Description Retrieve the users that currently have any document of the group open in the editor
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Group UUID"
+// @Success 200 {object} responses.GetGroupPresenceResponse "Presence retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Access forbidden"
+// @Failure 404 {object} map[string]interface{} "Group not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /groups/{uuid}/presence [get]
+func NewGetGroupPresenceHandler(service getGroupPresenceService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUIDValue, exists := c.Get("user_uid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+		userUUID, ok := userUUIDValue.(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user context"})
+			return
+		}
+
+		uuidParam := c.Param("uuid")
+		groupUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("get group presence handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		presences, err := service.GetPresence(c.Request.Context(), groupUUID, userUUID)
+		if errors.Is(err, domain.ErrGroupNotFound) {
+			logger.Warn("group not found", zap.String("uuid", uuidParam))
+			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+			return
+		}
+		if errors.Is(err, domain.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
+			return
+		}
+		if err != nil {
+			logger.Error("failed to get group presence", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get group presence"})
+			return
+		}
+
+		response := mapPresenceToGetResponse(presences)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
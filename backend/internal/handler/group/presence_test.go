@@ -0,0 +1,121 @@
+package group_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/group"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/group/responses"
+	"go.uber.org/zap"
+)
+
+type mockGroupPresenceService struct {
+	mock.Mock
+}
+
+func (m *mockGroupPresenceService) GetPresence(ctx context.Context, groupUUID, userUUID uuid.UUID) ([]*domain.Presence, error) {
+	args := m.Called(ctx, groupUUID, userUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Presence), args.Error(1) //nolint:errcheck
+}
+
+func TestNewGetGroupPresenceHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockGroupPresenceService, gin.HandlerFunc) {
+		mockService := &mockGroupPresenceService{}
+		handler := group.NewGetGroupPresenceHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, groupParam string, userUUID uuid.UUID) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/groups/"+groupParam+"/presence", nil)
+		c.Params = gin.Params{{Key: "uuid", Value: groupParam}}
+		c.Set("user_uid", userUUID)
+		handler(c)
+		return w
+	}
+
+	t.Run("SuccessfulGetPresence", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		groupUUID := uuid.New()
+		userUUID := uuid.New()
+		firstDocument := uuid.New()
+		secondDocument := uuid.New()
+		cursor := 42
+		mockService.On("GetPresence", mock.Anything, groupUUID, userUUID).Return([]*domain.Presence{
+			{DocumentUUID: firstDocument, UserUUID: uuid.New(), UserLogin: "alice", LastSeen: time.Now()},
+			{DocumentUUID: secondDocument, UserUUID: uuid.New(), UserLogin: "bob", CursorPosition: &cursor, LastSeen: time.Now()},
+		}, nil)
+
+		// Act
+		w := serve(handler, groupUUID.String(), userUUID)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response responses.GetGroupPresenceResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Presence, 2)
+		assert.Equal(t, firstDocument, response.Presence[0].DocumentUUID)
+		assert.Equal(t, secondDocument, response.Presence[1].DocumentUUID)
+		assert.Equal(t, &cursor, response.Presence[1].CursorPosition)
+	})
+
+	t.Run("InvalidUUID", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		// Act
+		w := serve(handler, "invalid-uuid", uuid.New())
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetPresence")
+	})
+
+	t.Run("ErrorMapping", func(t *testing.T) {
+		cases := map[string]struct {
+			err    error
+			status int
+		}{
+			"NotFound":  {domain.ErrGroupNotFound, http.StatusNotFound},
+			"Forbidden": {domain.ErrForbidden, http.StatusForbidden},
+			"Internal":  {domain.ErrInternal, http.StatusInternalServerError},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				mockService, handler := setup(t)
+
+				groupUUID := uuid.New()
+				userUUID := uuid.New()
+				mockService.On("GetPresence", mock.Anything, groupUUID, userUUID).Return(nil, tc.err)
+
+				// Act
+				w := serve(handler, groupUUID.String(), userUUID)
+
+				// Assert
+				assert.Equal(t, tc.status, w.Code)
+			})
+		}
+	})
+}
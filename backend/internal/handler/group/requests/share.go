@@ -0,0 +1,22 @@
+package requests
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type ShareGroupRequest struct {
+	// ExpirationDays is how long the link lasts; omitted uses the default.
+	ExpirationDays int `json:"expiration_days"`
+	// MaxUses caps how often the link can be opened; omitted is unlimited.
+	MaxUses *int `json:"max_uses"`
+	// Tag limits the link to the documents carrying it; omitted shares every
+	// document of the group.
+	Tag string `json:"tag"`
+}
+
+func (r ShareGroupRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.MaxUses, validation.NilOrNotEmpty, validation.Min(1)),
+		validation.Field(&r.Tag, validation.Length(1, 50)),
+	)
+}
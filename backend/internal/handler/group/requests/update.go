@@ -6,10 +6,14 @@ import (
 
 type UpdateGroupRequest struct {
 	Name string `json:"name"`
+	// MaxDocumentSize caps the collaborative state of the group's documents,
+	// in bytes; omitted keeps the current limit.
+	MaxDocumentSize *int `json:"max_document_size"`
 }
 
 func (r UpdateGroupRequest) Validate() error {
 	return validation.ValidateStruct(&r,
 		validation.Field(&r.Name, validation.Required, validation.Length(1, 255)),
+		validation.Field(&r.MaxDocumentSize, validation.NilOrNotEmpty, validation.Min(1)),
 	)
 }
@@ -7,9 +7,10 @@ import (
 )
 
 type GetGroupResponse struct {
-	UUID      uuid.UUID `json:"uuid"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
+	UUID            uuid.UUID `json:"uuid"`
+	Name            string    `json:"name"`
+	MaxDocumentSize *int      `json:"max_document_size"`
+	CreatedAt       time.Time `json:"created_at"`
 }
 
 type GetAllGroupsResponse struct {
@@ -0,0 +1,65 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ShareGroupResponse struct {
+	GroupUUID uuid.UUID `json:"group_uuid"`
+	LinkUUID  uuid.UUID `json:"link_uuid"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+	MaxUses   *int      `json:"max_uses"`
+	// Tag is the tag the shared documents carry; empty shares them all.
+	Tag string `json:"tag"`
+}
+
+type GroupShareLinkResponse struct {
+	UUID      uuid.UUID `json:"uuid"`
+	GroupUUID uuid.UUID `json:"group_uuid"`
+	URL       string    `json:"url"`
+	Tag       string    `json:"tag"`
+	ExpiresAt time.Time `json:"expires_at"`
+	MaxUses   *int      `json:"max_uses"`
+	Uses      int       `json:"uses"`
+	Revoked   bool      `json:"revoked"`
+	CreatedBy uuid.UUID `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type GetGroupShareLinksResponse struct {
+	Links []GroupShareLinkResponse `json:"links"`
+}
+
+type RevokeGroupShareLinkResponse struct {
+	Message string `json:"message"`
+}
+
+// SharedGroupDocumentResponse lists a document of a shared group, without its content.
+type SharedGroupDocumentResponse struct {
+	UUID      uuid.UUID `json:"uuid"`
+	Name      string    `json:"name"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type GetPublicGroupResponse struct {
+	UUID      uuid.UUID                     `json:"uuid"`
+	Name      string                        `json:"name"`
+	Documents []SharedGroupDocumentResponse `json:"documents"`
+}
+
+// GetPublicGroupDocumentResponse is a document read through a group share link;
+// group links are read-only.
+type GetPublicGroupDocumentResponse struct {
+	UUID      uuid.UUID `json:"uuid"`
+	GroupUUID uuid.UUID `json:"group_uuid"`
+	Name      string    `json:"name"`
+	Content   string    `json:"content"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	Role      string    `json:"role"`
+	CanEdit   bool      `json:"can_edit"`
+}
@@ -0,0 +1,419 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/group/requests"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/group/responses"
+	documentservice "github.com/ukma-cs-ssdm-2025/team-circus/internal/service/document"
+	"go.uber.org/zap"
+)
+
+type shareGroupService interface {
+	GenerateGroupShareLink(
+		ctx context.Context,
+		groupUUID, userUUID uuid.UUID,
+		opts domain.GroupShareLinkOptions,
+	) (*domain.ShareLink, error)
+}
+
+type getGroupShareLinksService interface {
+	GetGroupShareLinks(ctx context.Context, groupUUID, userUUID uuid.UUID) ([]*domain.ShareLink, error)
+}
+
+type revokeGroupShareLinkService interface {
+	RevokeGroupShareLink(ctx context.Context, groupUUID, linkUUID, userUUID uuid.UUID) error
+}
+
+type publicGroupService interface {
+	GetSharedGroup(
+		ctx context.Context,
+		query domain.GroupShareLinkQuery,
+	) (*domain.Group, []*domain.Document, *domain.ShareVisit, error)
+	GetSharedGroupDocument(
+		ctx context.Context,
+		query domain.GroupShareLinkQuery,
+		docUUID uuid.UUID,
+	) (*domain.Document, *domain.ShareVisit, error)
+	RecordShareLinkAccess(ctx context.Context, docUUID uuid.UUID, query domain.ShareLinkQuery, visit domain.ShareLinkVisit) error
+}
+
+// groupShareLinkQuery reads the parameters of a group share link from the
+// query string, and its visit token from the link's cookie.
+func groupShareLinkQuery(c *gin.Context) domain.GroupShareLinkQuery {
+	query := domain.GroupShareLinkQuery{
+		Group: c.Query("group"),
+		Sig:   c.Query("sig"),
+		Exp:   c.Query("exp"),
+		Link:  c.Query("link"),
+	}
+	if query.Link != "" {
+		query.Visit, _ = c.Cookie(domain.ShareVisitCookiePrefix + query.Link)
+	}
+	return query
+}
+
+// setShareVisitCookie hands the guest the visit token of a counted use, so
+// that the next requests of the visit do not count again.
+func setShareVisitCookie(c *gin.Context, query domain.GroupShareLinkQuery, visit *domain.ShareVisit) {
+	if visit == nil {
+		return
+	}
+	c.SetSameSite(http.SameSiteNoneMode)
+	c.SetCookie(domain.ShareVisitCookiePrefix+query.Link, visit.Token, int(time.Until(visit.ExpiresAt).Seconds()), "/", "", true, true)
+}
+
+// writeGroupShareLinkError answers a request whose group share link was
+// refused. It reports false when err is not about the link.
+func writeGroupShareLinkError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrShareLinkExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "share link expired"})
+	case errors.Is(err, domain.ErrShareLinkRevoked):
+		c.JSON(http.StatusGone, gin.H{"error": "share link revoked"})
+	case errors.Is(err, domain.ErrShareLinkUsedUp):
+		c.JSON(http.StatusGone, gin.H{"error": "share link has no uses left"})
+	case errors.Is(err, domain.ErrShareLinkInvalid):
+		c.JSON(http.StatusNotFound, gin.H{"error": "invalid share link"})
+	case errors.Is(err, domain.ErrGroupNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+	default:
+		return false
+	}
+	return true
+}
+
+// NewShareGroupHandler signs a read-only link to the documents of a group
+// @Summary Generate a shareable link for a group
+// @Description Create a time-limited, read-only share link that lets guests list and read every document of the
+// @Description group, or only those carrying a tag. The link can be limited to a number of uses and revoked.
+// @Description Only editors and authors may share a group.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Group UUID"
+// @Param request body requests.ShareGroupRequest false "Share link options"
+// @Success 200 {object} responses.ShareGroupResponse "Share link generated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Access forbidden"
+// @Failure 404 {object} map[string]interface{} "Group not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /groups/{uuid}/share [post]
+func NewShareGroupHandler(service shareGroupService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUIDValue, exists := c.Get("user_uid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+		userUUID, ok := userUUIDValue.(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user context"})
+			return
+		}
+
+		uuidParam := c.Param("uuid")
+		groupUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("share group handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		var req requests.ShareGroupRequest
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			logger.Error("failed to bind share request", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			err = fmt.Errorf("share group handler: validation failed: %v", err)
+			logger.Error("validation failed", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "details": err.Error()})
+			return
+		}
+
+		link, err := service.GenerateGroupShareLink(c.Request.Context(), groupUUID, userUUID, domain.GroupShareLinkOptions{
+			ExpirationDays: req.ExpirationDays,
+			MaxUses:        req.MaxUses,
+			Tag:            req.Tag,
+		})
+		switch {
+		case errors.Is(err, domain.ErrGroupNotFound):
+			logger.Warn("group not found for share", zap.String("uuid", uuidParam))
+			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
+			return
+		case errors.Is(err, documentservice.ErrInvalidExpiration):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid expiration range"})
+			return
+		case err != nil:
+			logger.Error("failed to generate group share link", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate share link"})
+			return
+		}
+
+		response := responses.ShareGroupResponse{
+			GroupUUID: link.GroupUUID,
+			LinkUUID:  link.UUID,
+			URL:       link.URL,
+			ExpiresAt: link.ExpiresAt,
+			MaxUses:   link.MaxUses,
+			Tag:       link.Tag,
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// NewGetGroupShareLinksHandler lists the share links of a group
+// @Summary Get group share links
+// @Description List the share links of a group, newest first, including revoked and expired ones.
+// @Description Only editors and authors may list share links.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Group UUID"
+// @Success 200 {object} responses.GetGroupShareLinksResponse "Share links retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Access forbidden"
+// @Failure 404 {object} map[string]interface{} "Group not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /groups/{uuid}/share [get]
+func NewGetGroupShareLinksHandler(service getGroupShareLinksService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uuidParam := c.Param("uuid")
+		groupUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("get group share links handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		userUUIDValue, exists := c.Get("user_uid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+
+		userUUID, ok := userUUIDValue.(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user context"})
+			return
+		}
+
+		links, err := service.GetGroupShareLinks(c.Request.Context(), groupUUID, userUUID)
+		switch {
+		case errors.Is(err, domain.ErrGroupNotFound):
+			logger.Warn("group not found for share links", zap.String("uuid", uuidParam))
+			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
+			return
+		case err != nil:
+			logger.Error("failed to get group share links", zap.Error(err), zap.String("uuid", uuidParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get share links"})
+			return
+		}
+
+		response := mapGroupShareLinksToResponse(links)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// NewRevokeGroupShareLinkHandler revokes a share link of a group
+// @Summary Revoke a group share link
+// @Description Stop a share link of a group from working. Only editors and authors may revoke share links.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param uuid path string true "Group UUID"
+// @Param link_uuid path string true "Share link UUID"
+// @Success 200 {object} responses.RevokeGroupShareLinkResponse "Share link revoked successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid UUID format"
+// @Failure 401 {object} map[string]interface{} "Authentication required"
+// @Failure 403 {object} map[string]interface{} "Access forbidden"
+// @Failure 404 {object} map[string]interface{} "Group or share link not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /groups/{uuid}/share/{link_uuid} [delete]
+func NewRevokeGroupShareLinkHandler(service revokeGroupShareLinkService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uuidParam := c.Param("uuid")
+		groupUUID, err := uuid.Parse(uuidParam)
+		if err != nil {
+			err = fmt.Errorf("revoke group share link handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		linkUUID, err := uuid.Parse(c.Param("link_uuid"))
+		if err != nil {
+			err = fmt.Errorf("revoke group share link handler: failed to parse link uuid: %v", err)
+			logger.Error("failed to parse link uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		userUUIDValue, exists := c.Get("user_uid")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+
+		userUUID, ok := userUUIDValue.(uuid.UUID)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user context"})
+			return
+		}
+
+		err = service.RevokeGroupShareLink(c.Request.Context(), groupUUID, linkUUID, userUUID)
+		switch {
+		case errors.Is(err, domain.ErrGroupNotFound):
+			logger.Warn("group not found for share link revocation", zap.String("uuid", uuidParam))
+			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
+			return
+		case errors.Is(err, domain.ErrShareLinkNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "share link not found"})
+			return
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
+			return
+		case err != nil:
+			logger.Error("failed to revoke group share link", zap.Error(err), zap.String("uuid", uuidParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke share link"})
+			return
+		}
+
+		response := responses.RevokeGroupShareLinkResponse{
+			Message: "Share link revoked successfully",
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// NewGetPublicGroupHandler lists the documents of a shared group
+// @Summary Access a shared group
+// @Description List the documents shared by a group share link. No authentication required. Opening the link
+// @Description counts a use of it and sets a visit cookie; requests carrying the cookie do not count again.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group query string true "Group UUID from share link"
+// @Param sig query string true "HMAC signature"
+// @Param exp query string true "Expiration timestamp (unix seconds)"
+// @Param link query string true "Share link UUID"
+// @Success 200 {object} responses.GetPublicGroupResponse "Group retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Missing parameters"
+// @Failure 404 {object} map[string]interface{} "Invalid share link or group not found"
+// @Failure 410 {object} map[string]interface{} "Share link expired, revoked or used up"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /groups/public [get]
+func NewGetPublicGroupHandler(service publicGroupService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := groupShareLinkQuery(c)
+		if query.Group == "" || query.Sig == "" || query.Exp == "" || query.Link == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing required parameters"})
+			return
+		}
+
+		group, documents, visit, err := service.GetSharedGroup(c.Request.Context(), query)
+		if writeGroupShareLinkError(c, err) {
+			return
+		}
+		if err != nil {
+			logger.Error("failed to get shared group", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load group"})
+			return
+		}
+		setShareVisitCookie(c, query, visit)
+
+		response := mapSharedGroupToResponse(group, documents)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// NewGetPublicGroupDocumentHandler returns a document of a shared group
+// @Summary Access a document of a shared group
+// @Description Retrieve a document shared by a group share link. No authentication required; the document is
+// @Description read-only. Like the group index, it counts a use of the link unless the visit cookie is sent.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param doc_uuid path string true "Document UUID"
+// @Param group query string true "Group UUID from share link"
+// @Param sig query string true "HMAC signature"
+// @Param exp query string true "Expiration timestamp (unix seconds)"
+// @Param link query string true "Share link UUID"
+// @Success 200 {object} responses.GetPublicGroupDocumentResponse "Document retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Missing parameters or invalid UUID format"
+// @Failure 404 {object} map[string]interface{} "Invalid share link, or document not shared by it"
+// @Failure 410 {object} map[string]interface{} "Share link expired, revoked or used up"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /groups/public/documents/{doc_uuid} [get]
+func NewGetPublicGroupDocumentHandler(service publicGroupService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := groupShareLinkQuery(c)
+		if query.Group == "" || query.Sig == "" || query.Exp == "" || query.Link == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing required parameters"})
+			return
+		}
+
+		docParam := c.Param("doc_uuid")
+		docUUID, err := uuid.Parse(docParam)
+		if err != nil {
+			err = fmt.Errorf("get public group document handler: failed to parse uuid: %v", err)
+			logger.Error("failed to parse uuid", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid uuid format"})
+			return
+		}
+
+		document, visit, err := service.GetSharedGroupDocument(c.Request.Context(), query, docUUID)
+		if writeGroupShareLinkError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, domain.ErrDocumentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
+			return
+		case err != nil:
+			logger.Error("failed to get shared group document", zap.Error(err), zap.String("uuid", docParam))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load document"})
+			return
+		}
+
+		access := domain.ShareLinkVisit{
+			Channel:   domain.ShareChannelREST,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		linkQuery := domain.ShareLinkQuery{Sig: query.Sig, Link: query.Link}
+		if err := service.RecordShareLinkAccess(c.Request.Context(), document.UUID, linkQuery, access); err != nil {
+			logger.Warn("failed to record share link access", zap.Error(err), zap.String("uuid", docParam))
+		}
+		setShareVisitCookie(c, query, visit)
+
+		response := mapSharedGroupDocumentToResponse(document)
+
+		c.JSON(http.StatusOK, response)
+	}
+}
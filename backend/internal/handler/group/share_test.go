@@ -0,0 +1,678 @@
+package group_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/group"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/handler/group/responses"
+	documentservice "github.com/ukma-cs-ssdm-2025/team-circus/internal/service/document"
+	"go.uber.org/zap"
+)
+
+type mockShareGroupService struct {
+	mock.Mock
+}
+
+func (m *mockShareGroupService) GenerateGroupShareLink(
+	ctx context.Context,
+	groupUUID, userUUID uuid.UUID,
+	opts domain.GroupShareLinkOptions,
+) (*domain.ShareLink, error) {
+	args := m.Called(ctx, groupUUID, userUUID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ShareLink), args.Error(1) //nolint:errcheck
+}
+
+type mockGroupShareLinksService struct {
+	mock.Mock
+}
+
+func (m *mockGroupShareLinksService) GetGroupShareLinks(ctx context.Context, groupUUID, userUUID uuid.UUID) ([]*domain.ShareLink, error) {
+	args := m.Called(ctx, groupUUID, userUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ShareLink), args.Error(1) //nolint:errcheck
+}
+
+func (m *mockGroupShareLinksService) RevokeGroupShareLink(ctx context.Context, groupUUID, linkUUID, userUUID uuid.UUID) error {
+	args := m.Called(ctx, groupUUID, linkUUID, userUUID)
+	return args.Error(0)
+}
+
+type mockPublicGroupService struct {
+	mock.Mock
+}
+
+func (m *mockPublicGroupService) GetSharedGroup(
+	ctx context.Context,
+	query domain.GroupShareLinkQuery,
+) (*domain.Group, []*domain.Document, *domain.ShareVisit, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, nil, nil, args.Error(3)
+	}
+	visit, _ := args.Get(2).(*domain.ShareVisit)
+	return args.Get(0).(*domain.Group), args.Get(1).([]*domain.Document), visit, args.Error(3) //nolint:errcheck
+}
+
+func (m *mockPublicGroupService) GetSharedGroupDocument(
+	ctx context.Context,
+	query domain.GroupShareLinkQuery,
+	docUUID uuid.UUID,
+) (*domain.Document, *domain.ShareVisit, error) {
+	args := m.Called(ctx, query, docUUID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	visit, _ := args.Get(1).(*domain.ShareVisit)
+	return args.Get(0).(*domain.Document), visit, args.Error(2) //nolint:errcheck
+}
+
+func (m *mockPublicGroupService) RecordShareLinkAccess(
+	ctx context.Context,
+	docUUID uuid.UUID,
+	query domain.ShareLinkQuery,
+	visit domain.ShareLinkVisit,
+) error {
+	args := m.Called(ctx, docUUID, query, visit)
+	return args.Error(0)
+}
+
+// newGroupShareQuery returns the parameters of a group share link.
+func newGroupShareQuery() domain.GroupShareLinkQuery {
+	return domain.GroupShareLinkQuery{
+		Group: uuid.New().String(),
+		Sig:   "signature",
+		Exp:   "1700000000",
+		Link:  uuid.New().String(),
+	}
+}
+
+func groupShareQueryString(query domain.GroupShareLinkQuery) string {
+	params := url.Values{}
+	params.Set("group", query.Group)
+	params.Set("sig", query.Sig)
+	params.Set("exp", query.Exp)
+	params.Set("link", query.Link)
+	return params.Encode()
+}
+
+func TestNewShareGroupHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockShareGroupService, gin.HandlerFunc) {
+		mockService := &mockShareGroupService{}
+		handler := group.NewShareGroupHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, groupParam string, userUUID uuid.UUID, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/groups/"+groupParam+"/share", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "uuid", Value: groupParam}}
+		c.Set("user_uid", userUUID)
+		handler(c)
+		return w
+	}
+
+	t.Run("SuccessfulShare", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		groupUUID := uuid.New()
+		userUUID := uuid.New()
+		linkUUID := uuid.New()
+		maxUses := 3
+		expiresAt := time.Now().Add(7 * 24 * time.Hour).UTC().Truncate(time.Second)
+		opts := domain.GroupShareLinkOptions{ExpirationDays: 7, MaxUses: &maxUses, Tag: "handbook"}
+		mockService.On("GenerateGroupShareLink", mock.Anything, groupUUID, userUUID, opts).Return(&domain.ShareLink{
+			UUID:      linkUUID,
+			GroupUUID: groupUUID,
+			Tag:       "handbook",
+			ExpiresAt: expiresAt,
+			MaxUses:   &maxUses,
+			URL:       "https://app.example.com/groups/public?group=" + groupUUID.String() + "&link=" + linkUUID.String(),
+		}, nil)
+
+		// Act
+		w := serve(handler, groupUUID.String(), userUUID, `{"expiration_days":7,"max_uses":3,"tag":"handbook"}`)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response responses.ShareGroupResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, groupUUID, response.GroupUUID)
+		assert.Equal(t, linkUUID, response.LinkUUID)
+		assert.True(t, expiresAt.Equal(response.ExpiresAt))
+		assert.Equal(t, &maxUses, response.MaxUses)
+		assert.Equal(t, "handbook", response.Tag)
+		assert.Contains(t, response.URL, groupUUID.String())
+	})
+
+	t.Run("EmptyBodyUsesDefault", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		groupUUID := uuid.New()
+		userUUID := uuid.New()
+		mockService.On("GenerateGroupShareLink", mock.Anything, groupUUID, userUUID, domain.GroupShareLinkOptions{}).
+			Return(&domain.ShareLink{
+				GroupUUID: groupUUID,
+				ExpiresAt: time.Now().Add(24 * time.Hour),
+			}, nil)
+
+		// Act
+		w := serve(handler, groupUUID.String(), userUUID, "")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("InvalidUUID", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		// Act
+		w := serve(handler, "invalid-uuid", uuid.New(), "")
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GenerateGroupShareLink")
+	})
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		for name, body := range map[string]string{
+			"ZeroMaxUses": `{"max_uses":0}`,
+			"LongTag":     `{"tag":"` + strings.Repeat("t", 51) + `"}`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				mockService, handler := setup(t)
+
+				// Act
+				w := serve(handler, uuid.New().String(), uuid.New(), body)
+
+				// Assert
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				mockService.AssertNotCalled(t, "GenerateGroupShareLink")
+			})
+		}
+	})
+
+	t.Run("ErrorMapping", func(t *testing.T) {
+		cases := map[string]struct {
+			err    error
+			status int
+		}{
+			"NotFound":          {domain.ErrGroupNotFound, http.StatusNotFound},
+			"Forbidden":         {domain.ErrForbidden, http.StatusForbidden},
+			"InvalidExpiration": {documentservice.ErrInvalidExpiration, http.StatusBadRequest},
+			"Internal":          {domain.ErrInternal, http.StatusInternalServerError},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				mockService, handler := setup(t)
+
+				groupUUID := uuid.New()
+				userUUID := uuid.New()
+				mockService.On("GenerateGroupShareLink", mock.Anything, groupUUID, userUUID, domain.GroupShareLinkOptions{}).
+					Return(nil, tc.err)
+
+				// Act
+				w := serve(handler, groupUUID.String(), userUUID, "")
+
+				// Assert
+				assert.Equal(t, tc.status, w.Code)
+			})
+		}
+	})
+}
+
+func TestNewGetPublicGroupHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockPublicGroupService, gin.HandlerFunc) {
+		mockService := &mockPublicGroupService{}
+		handler := group.NewGetPublicGroupHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, rawQuery string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/groups/public?"+rawQuery, nil)
+		for _, cookie := range cookies {
+			c.Request.AddCookie(cookie)
+		}
+		handler(c)
+		return w
+	}
+
+	t.Run("SuccessfulGetGroup", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		query := newGroupShareQuery()
+		groupUUID := uuid.MustParse(query.Group)
+		documents := []*domain.Document{
+			{UUID: uuid.New(), GroupUUID: groupUUID, Name: "Notes", Content: "secret", Tags: []string{"handbook"}, CreatedAt: time.Now()},
+			{UUID: uuid.New(), GroupUUID: groupUUID, Name: "Plan", CreatedAt: time.Now()},
+		}
+		mockService.On("GetSharedGroup", mock.Anything, query).
+			Return(&domain.Group{UUID: groupUUID, Name: "Team"}, documents, nil, nil)
+
+		// Act
+		w := serve(handler, groupShareQueryString(query))
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response responses.GetPublicGroupResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, groupUUID, response.UUID)
+		assert.Equal(t, "Team", response.Name)
+		assert.Len(t, response.Documents, 2)
+		assert.Equal(t, documents[0].UUID, response.Documents[0].UUID)
+		assert.Equal(t, []string{"handbook"}, response.Documents[0].Tags)
+		assert.NotContains(t, w.Body.String(), "secret")
+		assert.Empty(t, w.Result().Cookies())
+	})
+
+	t.Run("SetsVisitCookieWhenUseIsCounted", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		query := newGroupShareQuery()
+		visit := &domain.ShareVisit{Token: "visit-token", ExpiresAt: time.Now().Add(time.Hour)}
+		mockService.On("GetSharedGroup", mock.Anything, query).
+			Return(&domain.Group{UUID: uuid.MustParse(query.Group)}, []*domain.Document{}, visit, nil)
+
+		// Act
+		w := serve(handler, groupShareQueryString(query))
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		cookies := w.Result().Cookies()
+		if assert.Len(t, cookies, 1) {
+			assert.Equal(t, domain.ShareVisitCookiePrefix+query.Link, cookies[0].Name)
+			assert.Equal(t, "visit-token", cookies[0].Value)
+			assert.True(t, cookies[0].HttpOnly)
+		}
+	})
+
+	t.Run("PassesVisitCookie", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		query := newGroupShareQuery()
+		cookie := &http.Cookie{Name: domain.ShareVisitCookiePrefix + query.Link, Value: "visit-token"}
+		withVisit := query
+		withVisit.Visit = "visit-token"
+		mockService.On("GetSharedGroup", mock.Anything, withVisit).
+			Return(&domain.Group{UUID: uuid.MustParse(query.Group)}, []*domain.Document{}, nil, nil)
+
+		// Act
+		w := serve(handler, groupShareQueryString(query), cookie)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("MissingParameters", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		// Act
+		w := serve(handler, "group="+uuid.New().String())
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetSharedGroup")
+	})
+
+	t.Run("ErrorMapping", func(t *testing.T) {
+		cases := map[string]struct {
+			err    error
+			status int
+		}{
+			"Invalid":  {domain.ErrShareLinkInvalid, http.StatusNotFound},
+			"Expired":  {domain.ErrShareLinkExpired, http.StatusGone},
+			"Revoked":  {domain.ErrShareLinkRevoked, http.StatusGone},
+			"UsedUp":   {domain.ErrShareLinkUsedUp, http.StatusGone},
+			"NotFound": {domain.ErrGroupNotFound, http.StatusNotFound},
+			"Internal": {domain.ErrInternal, http.StatusInternalServerError},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				mockService, handler := setup(t)
+
+				query := newGroupShareQuery()
+				mockService.On("GetSharedGroup", mock.Anything, query).Return(nil, nil, nil, tc.err)
+
+				// Act
+				w := serve(handler, groupShareQueryString(query))
+
+				// Assert
+				assert.Equal(t, tc.status, w.Code)
+			})
+		}
+	})
+}
+
+func TestNewGetPublicGroupDocumentHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockPublicGroupService, gin.HandlerFunc) {
+		mockService := &mockPublicGroupService{}
+		handler := group.NewGetPublicGroupDocumentHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, docParam, rawQuery string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/groups/public/documents/"+docParam+"?"+rawQuery, nil)
+		c.Params = gin.Params{{Key: "doc_uuid", Value: docParam}}
+		handler(c)
+		return w
+	}
+
+	t.Run("SuccessfulGetDocument", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		query := newGroupShareQuery()
+		groupUUID := uuid.MustParse(query.Group)
+		docUUID := uuid.New()
+		visit := &domain.ShareVisit{Token: "visit-token", ExpiresAt: time.Now().Add(time.Hour)}
+		mockService.On("GetSharedGroupDocument", mock.Anything, query, docUUID).Return(&domain.Document{
+			UUID:      docUUID,
+			GroupUUID: groupUUID,
+			Name:      "Notes",
+			Content:   "content",
+			CreatedAt: time.Now(),
+		}, visit, nil)
+		mockService.On("RecordShareLinkAccess", mock.Anything, docUUID,
+			domain.ShareLinkQuery{Sig: query.Sig, Link: query.Link},
+			mock.MatchedBy(func(visit domain.ShareLinkVisit) bool { return visit.Channel == domain.ShareChannelREST }),
+		).Return(nil)
+
+		// Act
+		w := serve(handler, docUUID.String(), groupShareQueryString(query))
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response responses.GetPublicGroupDocumentResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, docUUID, response.UUID)
+		assert.Equal(t, "content", response.Content)
+		assert.Equal(t, domain.RoleViewer, response.Role)
+		assert.False(t, response.CanEdit)
+		cookies := w.Result().Cookies()
+		if assert.Len(t, cookies, 1) {
+			assert.Equal(t, domain.ShareVisitCookiePrefix+query.Link, cookies[0].Name)
+		}
+	})
+
+	t.Run("AccessRecordingFailureIsIgnored", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		query := newGroupShareQuery()
+		docUUID := uuid.New()
+		mockService.On("GetSharedGroupDocument", mock.Anything, query, docUUID).
+			Return(&domain.Document{UUID: docUUID, GroupUUID: uuid.MustParse(query.Group)}, nil, nil)
+		mockService.On("RecordShareLinkAccess", mock.Anything, docUUID, mock.Anything, mock.Anything).Return(domain.ErrInternal)
+
+		// Act
+		w := serve(handler, docUUID.String(), groupShareQueryString(query))
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("InvalidUUID", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		// Act
+		w := serve(handler, "invalid-uuid", groupShareQueryString(newGroupShareQuery()))
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetSharedGroupDocument")
+	})
+
+	t.Run("ErrorMapping", func(t *testing.T) {
+		cases := map[string]struct {
+			err    error
+			status int
+		}{
+			"Invalid":       {domain.ErrShareLinkInvalid, http.StatusNotFound},
+			"Expired":       {domain.ErrShareLinkExpired, http.StatusGone},
+			"Revoked":       {domain.ErrShareLinkRevoked, http.StatusGone},
+			"UsedUp":        {domain.ErrShareLinkUsedUp, http.StatusGone},
+			"NotShared":     {domain.ErrDocumentNotFound, http.StatusNotFound},
+			"GroupNotFound": {domain.ErrGroupNotFound, http.StatusNotFound},
+			"Internal":      {domain.ErrInternal, http.StatusInternalServerError},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				mockService, handler := setup(t)
+
+				docUUID := uuid.New()
+				query := newGroupShareQuery()
+				mockService.On("GetSharedGroupDocument", mock.Anything, query, docUUID).Return(nil, nil, tc.err)
+
+				// Act
+				w := serve(handler, docUUID.String(), groupShareQueryString(query))
+
+				// Assert
+				assert.Equal(t, tc.status, w.Code)
+			})
+		}
+	})
+}
+
+func TestNewGetGroupShareLinksHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockGroupShareLinksService, gin.HandlerFunc) {
+		mockService := &mockGroupShareLinksService{}
+		handler := group.NewGetGroupShareLinksHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, groupParam string, userUUID uuid.UUID) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/groups/"+groupParam+"/share", nil)
+		c.Params = gin.Params{{Key: "uuid", Value: groupParam}}
+		c.Set("user_uid", userUUID)
+		handler(c)
+		return w
+	}
+
+	t.Run("SuccessfulGetLinks", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		groupUUID := uuid.New()
+		userUUID := uuid.New()
+		maxUses := 5
+		links := []*domain.ShareLink{
+			{UUID: uuid.New(), GroupUUID: groupUUID, Tag: "handbook", MaxUses: &maxUses, Uses: 2, URL: "https://app.example.com/groups/public"},
+			{UUID: uuid.New(), GroupUUID: groupUUID, Revoked: true},
+		}
+		mockService.On("GetGroupShareLinks", mock.Anything, groupUUID, userUUID).Return(links, nil)
+
+		// Act
+		w := serve(handler, groupUUID.String(), userUUID)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response responses.GetGroupShareLinksResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if assert.Len(t, response.Links, 2) {
+			assert.Equal(t, links[0].UUID, response.Links[0].UUID)
+			assert.Equal(t, "handbook", response.Links[0].Tag)
+			assert.Equal(t, &maxUses, response.Links[0].MaxUses)
+			assert.Equal(t, 2, response.Links[0].Uses)
+			assert.True(t, response.Links[1].Revoked)
+		}
+	})
+
+	t.Run("InvalidUUID", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		// Act
+		w := serve(handler, "invalid-uuid", uuid.New())
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetGroupShareLinks")
+	})
+
+	t.Run("ErrorMapping", func(t *testing.T) {
+		cases := map[string]struct {
+			err    error
+			status int
+		}{
+			"NotFound":  {domain.ErrGroupNotFound, http.StatusNotFound},
+			"Forbidden": {domain.ErrForbidden, http.StatusForbidden},
+			"Internal":  {domain.ErrInternal, http.StatusInternalServerError},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				mockService, handler := setup(t)
+
+				groupUUID := uuid.New()
+				userUUID := uuid.New()
+				mockService.On("GetGroupShareLinks", mock.Anything, groupUUID, userUUID).Return(nil, tc.err)
+
+				// Act
+				w := serve(handler, groupUUID.String(), userUUID)
+
+				// Assert
+				assert.Equal(t, tc.status, w.Code)
+			})
+		}
+	})
+}
+
+func TestNewRevokeGroupShareLinkHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	setup := func(t *testing.T) (*mockGroupShareLinksService, gin.HandlerFunc) {
+		mockService := &mockGroupShareLinksService{}
+		handler := group.NewRevokeGroupShareLinkHandler(mockService, zap.NewNop())
+		t.Cleanup(func() {
+			mockService.AssertExpectations(t)
+		})
+		return mockService, handler
+	}
+
+	serve := func(handler gin.HandlerFunc, groupParam, linkParam string, userUUID uuid.UUID) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("DELETE", "/groups/"+groupParam+"/share/"+linkParam, nil)
+		c.Params = gin.Params{{Key: "uuid", Value: groupParam}, {Key: "link_uuid", Value: linkParam}}
+		c.Set("user_uid", userUUID)
+		handler(c)
+		return w
+	}
+
+	t.Run("SuccessfulRevoke", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		groupUUID := uuid.New()
+		linkUUID := uuid.New()
+		userUUID := uuid.New()
+		mockService.On("RevokeGroupShareLink", mock.Anything, groupUUID, linkUUID, userUUID).Return(nil)
+
+		// Act
+		w := serve(handler, groupUUID.String(), linkUUID.String(), userUUID)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("InvalidLinkUUID", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		// Act
+		w := serve(handler, uuid.New().String(), "invalid-uuid", uuid.New())
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "RevokeGroupShareLink")
+	})
+
+	t.Run("ErrorMapping", func(t *testing.T) {
+		cases := map[string]struct {
+			err    error
+			status int
+		}{
+			"GroupNotFound": {domain.ErrGroupNotFound, http.StatusNotFound},
+			"LinkNotFound":  {domain.ErrShareLinkNotFound, http.StatusNotFound},
+			"Forbidden":     {domain.ErrForbidden, http.StatusForbidden},
+			"Internal":      {domain.ErrInternal, http.StatusInternalServerError},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				mockService, handler := setup(t)
+
+				groupUUID := uuid.New()
+				linkUUID := uuid.New()
+				userUUID := uuid.New()
+				mockService.On("RevokeGroupShareLink", mock.Anything, groupUUID, linkUUID, userUUID).Return(tc.err)
+
+				// Act
+				w := serve(handler, groupUUID.String(), linkUUID.String(), userUUID)
+
+				// Assert
+				assert.Equal(t, tc.status, w.Code)
+			})
+		}
+	})
+}
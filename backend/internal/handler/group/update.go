@@ -14,12 +14,12 @@ import (
 )
 
 type updateGroupService interface {
-	Update(ctx context.Context, userUUID, uuid uuid.UUID, name string) (*domain.Group, error)
+	Update(ctx context.Context, userUUID, uuid uuid.UUID, name string, maxDocumentSize *int) (*domain.Group, error)
 }
 
 // NewUpdateGroupHandler updates a group by UUID
 // @Summary Update a group by UUID
-// @Description Update a specific group's name by its UUID
+// @Description Update a specific group's name, and optionally the size limit of its documents, by its UUID
 // @Tags groups
 // @Accept json
 // @Produce json
@@ -69,7 +69,7 @@ func NewUpdateGroupHandler(service updateGroupService, logger *zap.Logger) gin.H
 			return
 		}
 
-		group, err := service.Update(c.Request.Context(), userUUID, parsedUUID, req.Name)
+		group, err := service.Update(c.Request.Context(), userUUID, parsedUUID, req.Name, req.MaxDocumentSize)
 		if errors.Is(err, domain.ErrGroupNotFound) {
 			logger.Warn("group not found", zap.String("uuid", uuidParam))
 			c.JSON(http.StatusNotFound, gin.H{"error": "group not found"})
@@ -24,8 +24,13 @@ type mockUpdateGroupService struct {
 	mock.Mock
 }
 
-func (m *mockUpdateGroupService) Update(ctx context.Context, userUUID, uuid uuid.UUID, name string) (*domain.Group, error) {
-	args := m.Called(ctx, userUUID, uuid, name)
+func (m *mockUpdateGroupService) Update(
+	ctx context.Context,
+	userUUID, uuid uuid.UUID,
+	name string,
+	maxDocumentSize *int,
+) (*domain.Group, error) {
+	args := m.Called(ctx, userUUID, uuid, name, maxDocumentSize)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -56,7 +61,7 @@ func TestNewUpdateGroupHandler(t *testing.T) {
 			CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
 		}
 
-		mockService.On("Update", mock.Anything, userUUID, groupUUID, "Updated Group").Return(expectedGroup, nil)
+		mockService.On("Update", mock.Anything, userUUID, groupUUID, "Updated Group", (*int)(nil)).Return(expectedGroup, nil)
 
 		requestBody := requests.UpdateGroupRequest{
 			Name: "Updated Group",
@@ -88,6 +93,83 @@ func TestNewUpdateGroupHandler(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("SuccessfulUpdateWithMaxDocumentSize", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		userUUID := uuid.New()
+		groupUUID := uuid.New()
+		maxDocumentSize := 1 << 20
+		expectedGroup := &domain.Group{
+			UUID:            groupUUID,
+			Name:            "Updated Group",
+			MaxDocumentSize: &maxDocumentSize,
+			CreatedAt:       time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		mockService.On("Update", mock.Anything, userUUID, groupUUID, "Updated Group", &maxDocumentSize).Return(expectedGroup, nil)
+
+		requestBody := requests.UpdateGroupRequest{
+			Name:            "Updated Group",
+			MaxDocumentSize: &maxDocumentSize,
+		}
+
+		jsonBody, err := json.Marshal(requestBody)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest("PUT", "/groups/"+groupUUID.String(), bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{{Key: "uuid", Value: groupUUID.String()}}
+		c.Set("user_uid", userUUID)
+
+		// Act
+		handler(c)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.EqualValues(t, maxDocumentSize, response["max_document_size"])
+	})
+
+	t.Run("InvalidMaxDocumentSize", func(t *testing.T) {
+		// Arrange
+		mockService, handler := setup(t)
+
+		userUUID := uuid.New()
+		groupUUID := uuid.New()
+		maxDocumentSize := 0
+		requestBody := requests.UpdateGroupRequest{
+			Name:            "Updated Group",
+			MaxDocumentSize: &maxDocumentSize,
+		}
+
+		jsonBody, err := json.Marshal(requestBody)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest("PUT", "/groups/"+groupUUID.String(), bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{{Key: "uuid", Value: groupUUID.String()}}
+		c.Set("user_uid", userUUID)
+
+		// Act
+		handler(c)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "Update")
+	})
+
 	t.Run("InvalidUUID", func(t *testing.T) {
 		// Arrange
 		mockService, handler := setup(t)
@@ -224,7 +306,7 @@ func TestNewUpdateGroupHandler(t *testing.T) {
 
 		userUUID := uuid.New()
 		groupUUID := uuid.New()
-		mockService.On("Update", mock.Anything, userUUID, groupUUID, "Updated Group").Return(nil, domain.ErrGroupNotFound)
+		mockService.On("Update", mock.Anything, userUUID, groupUUID, "Updated Group", (*int)(nil)).Return(nil, domain.ErrGroupNotFound)
 
 		requestBody := requests.UpdateGroupRequest{
 			Name: "Updated Group",
@@ -262,7 +344,7 @@ func TestNewUpdateGroupHandler(t *testing.T) {
 
 		userUUID := uuid.New()
 		groupUUID := uuid.New()
-		mockService.On("Update", mock.Anything, userUUID, groupUUID, "Updated Group").Return(nil, domain.ErrInternal)
+		mockService.On("Update", mock.Anything, userUUID, groupUUID, "Updated Group", (*int)(nil)).Return(nil, domain.ErrInternal)
 
 		requestBody := requests.UpdateGroupRequest{
 			Name: "Updated Group",
@@ -300,7 +382,7 @@ func TestNewUpdateGroupHandler(t *testing.T) {
 
 		userUUID := uuid.New()
 		groupUUID := uuid.New()
-		mockService.On("Update", mock.Anything, userUUID, groupUUID, "Updated Group").Return(nil, errors.New("database connection failed"))
+		mockService.On("Update", mock.Anything, userUUID, groupUUID, "Updated Group", (*int)(nil)).Return(nil, errors.New("database connection failed"))
 
 		requestBody := requests.UpdateGroupRequest{
 			Name: "Updated Group",
@@ -338,7 +420,7 @@ func TestNewUpdateGroupHandler(t *testing.T) {
 
 		userUUID := uuid.New()
 		groupUUID := uuid.New()
-		mockService.On("Update", mock.Anything, userUUID, groupUUID, "Updated Group").Return(nil, domain.ErrForbidden)
+		mockService.On("Update", mock.Anything, userUUID, groupUUID, "Updated Group", (*int)(nil)).Return(nil, domain.ErrForbidden)
 
 		requestBody := requests.UpdateGroupRequest{
 			Name: "Updated Group",
@@ -0,0 +1,321 @@
+package websocket
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+)
+
+// awarenessEntry is one client state of a y-protocols/awareness update.
+type awarenessEntry struct {
+	ClientID uint64
+	Clock    uint64
+	// State is the JSON encoded state; "null" marks a client going offline.
+	State string
+}
+
+func decodeAwarenessUpdate(payload []byte) ([]awarenessEntry, error) {
+	dec := yjs.NewDecoder(payload)
+	count, err := dec.ReadVarUint()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]awarenessEntry, 0, min(count, 16))
+	for range count {
+		var entry awarenessEntry
+		if entry.ClientID, err = dec.ReadVarUint(); err != nil {
+			return nil, err
+		}
+		if entry.Clock, err = dec.ReadVarUint(); err != nil {
+			return nil, err
+		}
+		if entry.State, err = dec.ReadVarString(); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func encodeAwarenessMessage(entries []awarenessEntry) []byte {
+	update := yjs.NewEncoder()
+	update.WriteVarUint(uint64(len(entries)))
+	for _, entry := range entries {
+		update.WriteVarUint(entry.ClientID)
+		update.WriteVarUint(entry.Clock)
+		update.WriteVarString(entry.State)
+	}
+
+	enc := yjs.NewEncoder()
+	enc.WriteVarUint(MessageTypeAwareness)
+	enc.WriteVarUint8Array(update.Bytes())
+	return enc.Bytes()
+}
+
+// stampAwareness rewrites an awareness update so that it only carries the
+// client's own state with the identity of the authenticated user. The first
+// awareness client id announced on a connection is bound to it; states of any
+// other client id are dropped. Whether the connection may use that id at all
+// is decided by the hub, see storeAwareness. It returns nil when nothing is
+// left to relay.
+func stampAwareness(client *ClientConnection, payload []byte) ([]byte, error) {
+	entries, err := decodeAwarenessUpdate(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	stamped := make([]awarenessEntry, 0, 1)
+	for _, entry := range entries {
+		if !client.awarenessBound {
+			client.AwarenessID = uint32(entry.ClientID)
+			client.awarenessBound = true
+		}
+		if entry.ClientID != uint64(client.AwarenessID) {
+			continue
+		}
+
+		if entry.State != "null" {
+			if entry.State, err = stampAwarenessState(client, entry.State); err != nil {
+				return nil, err
+			}
+		}
+		stamped = append(stamped, entry)
+	}
+
+	if len(stamped) == 0 {
+		return nil, nil
+	}
+	return encodeAwarenessMessage(stamped), nil
+}
+
+// stampAwarenessState overrides the "user" field of a JSON awareness state.
+// Other fields, such as the cursor, are kept as sent by the client.
+func stampAwarenessState(client *ClientConnection, state string) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(state), &fields); err != nil {
+		return "", fmt.Errorf("awareness state: %w", err)
+	}
+	if fields == nil {
+		fields = make(map[string]json.RawMessage)
+	}
+
+	user := map[string]any{}
+	if raw, ok := fields["user"]; ok {
+		// A malformed user field is replaced as a whole.
+		_ = json.Unmarshal(raw, &user)
+		if user == nil {
+			user = map[string]any{}
+		}
+	}
+	user["id"] = client.UserID.String()
+	user["name"] = client.UserName
+	user["color"] = awarenessColor(client.UserID.String())
+	if role, _ := client.access(); role != "" {
+		user["role"] = role
+	} else {
+		delete(user, "role")
+	}
+
+	raw, err := json.Marshal(user)
+	if err != nil {
+		return "", fmt.Errorf("awareness state: %w", err)
+	}
+	fields["user"] = raw
+
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("awareness state: %w", err)
+	}
+	return string(out), nil
+}
+
+// awarenessColor derives a stable cursor color from a user id. It matches the
+// randomColor helper of the frontend so colors do not change once stamped.
+func awarenessColor(seed string) string {
+	var hash int32
+	for i := 0; i < len(seed); i++ {
+		hash = int32(seed[i]) + (hash<<5 - hash)
+	}
+
+	hue := int64(hash)
+	if hue < 0 {
+		hue = -hue
+	}
+	return fmt.Sprintf("hsl(%d, 70%%, 60%%)", hue%360)
+}
+
+const (
+	// defaultAwarenessInterval is how often merged awareness states are relayed.
+	defaultAwarenessInterval = 100 * time.Millisecond
+	// awarenessTimeout expires awareness states that were not renewed. Clients
+	// renew their state every 15 seconds, matching y-protocols/awareness.
+	awarenessTimeout = 30 * time.Second
+)
+
+// awarenessState is the latest awareness entry the hub knows for a client id.
+type awarenessState struct {
+	entry awarenessEntry
+	// client is the local connection that announced the state; nil for peers.
+	client *ClientConnection
+	// owner is the user the state belongs to; uuid.Nil when unknown.
+	owner uuid.UUID
+	seen  time.Time
+}
+
+// broadcastAwareness records the pending awareness state of a client that is
+// still connected to the hub. It is relayed on the next awareness flush.
+func (m *HubManager) broadcastAwareness(hub *DocumentHub, client *ClientConnection) {
+	message := client.takeAwareness()
+	if _, ok := hub.Clients[client]; !ok || message == nil {
+		return
+	}
+
+	msg, err := decodeMessage(message)
+	if err != nil {
+		return
+	}
+	entries, err := decodeAwarenessUpdate(msg.Payload)
+	if err != nil {
+		return
+	}
+	m.storeAwareness(hub, client, entries)
+}
+
+// storeAwareness keeps the newest state per awareness client id and marks it
+// for the next flush. States replaced before they were flushed are coalesced.
+// Entries for a client id owned by another user are ignored, offline states
+// included, so nobody can move or clear someone else's cursor.
+func (m *HubManager) storeAwareness(hub *DocumentHub, client *ClientConnection, entries []awarenessEntry) {
+	now := time.Now()
+	for _, entry := range entries {
+		owner := awarenessOwner(client, entry)
+		current, ok := hub.awareness[entry.ClientID]
+		if ok {
+			if !current.ownedBy(client, owner) || current.entry.Clock > entry.Clock {
+				continue
+			}
+			if owner == uuid.Nil {
+				owner = current.owner
+			}
+		}
+		if _, pending := hub.awarenessChanged[entry.ClientID]; pending {
+			m.delivery.coalesced.Add(1)
+		}
+		hub.awareness[entry.ClientID] = &awarenessState{entry: entry, client: client, owner: owner, seen: now}
+		hub.awarenessChanged[entry.ClientID] = client != nil
+	}
+}
+
+// ownedBy reports whether an entry from client, on behalf of owner, may
+// replace the state. The connection that announced a state may change it, and
+// so may another connection of the same user, which is how a client that
+// reconnects keeps its awareness client id. Peers check their own clients, so
+// the states they announced may be changed by any peer.
+func (s *awarenessState) ownedBy(client *ClientConnection, owner uuid.UUID) bool {
+	if s.client == client {
+		return true
+	}
+	return s.owner != uuid.Nil && s.owner == owner
+}
+
+// awarenessOwner returns the user an awareness entry is sent for: the user of
+// a local connection, or the user stamped into the state by a peer.
+func awarenessOwner(client *ClientConnection, entry awarenessEntry) uuid.UUID {
+	if client != nil {
+		return client.UserID
+	}
+
+	var state struct {
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal([]byte(entry.State), &state); err != nil {
+		return uuid.Nil
+	}
+	owner, err := uuid.Parse(state.User.ID)
+	if err != nil {
+		return uuid.Nil
+	}
+	return owner
+}
+
+// flushAwareness relays the awareness states changed since the last flush as
+// one merged update. Only states of local clients are published to peers.
+func (m *HubManager) flushAwareness(hub *DocumentHub) {
+	m.expireAwareness(hub, time.Now().Add(-awarenessTimeout))
+	if len(hub.awarenessChanged) == 0 {
+		return
+	}
+
+	entries := make([]awarenessEntry, 0, len(hub.awarenessChanged))
+	local := make([]awarenessEntry, 0, len(hub.awarenessChanged))
+	for clientID, publish := range hub.awarenessChanged {
+		state := hub.awareness[clientID]
+		entries = append(entries, state.entry)
+		if publish {
+			local = append(local, state.entry)
+		}
+		if state.entry.State == "null" {
+			delete(hub.awareness, clientID)
+		}
+	}
+	clear(hub.awarenessChanged)
+
+	if len(local) > 0 {
+		m.publish(hub, encodeAwarenessMessage(sortAwareness(local)))
+	}
+	m.fanOut(hub, encodeAwarenessMessage(sortAwareness(entries)))
+}
+
+// sendAwareness gives a newly registered client every known awareness state.
+func (m *HubManager) sendAwareness(hub *DocumentHub, client *ClientConnection) {
+	entries := make([]awarenessEntry, 0, len(hub.awareness))
+	for _, state := range hub.awareness {
+		if state.entry.State != "null" {
+			entries = append(entries, state.entry)
+		}
+	}
+	if len(entries) > 0 {
+		m.deliver(hub, client, encodeAwarenessMessage(sortAwareness(entries)))
+	}
+}
+
+// removeAwareness marks the awareness states of a disconnected client offline.
+func (m *HubManager) removeAwareness(hub *DocumentHub, client *ClientConnection) {
+	for clientID, state := range hub.awareness {
+		if state.client == client {
+			m.markAwarenessOffline(hub, clientID, state)
+		}
+	}
+}
+
+// expireAwareness marks states that were not renewed since before offline.
+func (m *HubManager) expireAwareness(hub *DocumentHub, before time.Time) {
+	for clientID, state := range hub.awareness {
+		if state.entry.State != "null" && state.seen.Before(before) {
+			m.markAwarenessOffline(hub, clientID, state)
+		}
+	}
+}
+
+func (m *HubManager) markAwarenessOffline(hub *DocumentHub, clientID uint64, state *awarenessState) {
+	if state.entry.State == "null" {
+		return
+	}
+	state.entry.Clock++
+	state.entry.State = "null"
+	hub.awarenessChanged[clientID] = state.client != nil
+}
+
+func sortAwareness(entries []awarenessEntry) []awarenessEntry {
+	slices.SortFunc(entries, func(a, b awarenessEntry) int { return cmp.Compare(a.ClientID, b.ClientID) })
+	return entries
+}
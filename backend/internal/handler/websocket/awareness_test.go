@@ -0,0 +1,264 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"go.uber.org/zap"
+)
+
+// awarenessPayload encodes the payload of an awareness frame.
+func awarenessPayload(entries ...awarenessEntry) []byte {
+	msg, err := decodeMessage(encodeAwarenessMessage(entries))
+	if err != nil {
+		panic(err)
+	}
+	return msg.Payload
+}
+
+// stampedStates decodes a relayed awareness frame into its JSON states.
+func stampedStates(t *testing.T, message []byte) map[uint64]map[string]any {
+	t.Helper()
+
+	msg, err := decodeMessage(message)
+	require.NoError(t, err)
+	require.EqualValues(t, MessageTypeAwareness, msg.Type)
+
+	entries, err := decodeAwarenessUpdate(msg.Payload)
+	require.NoError(t, err)
+
+	states := make(map[uint64]map[string]any, len(entries))
+	for _, entry := range entries {
+		var state map[string]any
+		require.NoError(t, json.Unmarshal([]byte(entry.State), &state))
+		states[entry.ClientID] = state
+	}
+	return states
+}
+
+func TestStampAwareness(t *testing.T) {
+	newClient := func() *ClientConnection {
+		return &ClientConnection{
+			UserID:   uuid.MustParse("6f1c2b8e-3d4a-4b5c-9e7f-0a1b2c3d4e5f"),
+			UserName: "alice",
+			Role:     domain.RoleEditor,
+		}
+	}
+
+	t.Run("OverridesSpoofedIdentity", func(t *testing.T) {
+		client := newClient()
+
+		message, err := stampAwareness(client, awarenessPayload(awarenessEntry{
+			ClientID: 42,
+			Clock:    3,
+			State:    `{"user":{"id":"someone-else","name":"bob","color":"red","role":"author"},"cursor":7}`,
+		}))
+		require.NoError(t, err)
+
+		states := stampedStates(t, message)
+		require.Contains(t, states, uint64(42))
+		user := states[42]["user"].(map[string]any)
+		assert.Equal(t, client.UserID.String(), user["id"])
+		assert.Equal(t, "alice", user["name"])
+		assert.Equal(t, awarenessColor(client.UserID.String()), user["color"])
+		assert.Equal(t, domain.RoleEditor, user["role"])
+		assert.EqualValues(t, 7, states[42]["cursor"])
+	})
+
+	t.Run("BindsFirstClientID", func(t *testing.T) {
+		client := newClient()
+
+		_, err := stampAwareness(client, awarenessPayload(awarenessEntry{ClientID: 42, Clock: 1, State: `{}`}))
+		require.NoError(t, err)
+		assert.EqualValues(t, 42, client.AwarenessID)
+
+		message, err := stampAwareness(client, awarenessPayload(awarenessEntry{ClientID: 99, Clock: 1, State: `{}`}))
+		require.NoError(t, err)
+		assert.Nil(t, message)
+	})
+
+	t.Run("DropsForeignEntries", func(t *testing.T) {
+		client := newClient()
+
+		message, err := stampAwareness(client, awarenessPayload(
+			awarenessEntry{ClientID: 42, Clock: 1, State: `{}`},
+			awarenessEntry{ClientID: 99, Clock: 5, State: `{"user":{"name":"mallory"}}`},
+		))
+		require.NoError(t, err)
+
+		states := stampedStates(t, message)
+		assert.Len(t, states, 1)
+		assert.Contains(t, states, uint64(42))
+	})
+
+	t.Run("RelaysOfflineState", func(t *testing.T) {
+		client := newClient()
+
+		message, err := stampAwareness(client, awarenessPayload(awarenessEntry{ClientID: 42, Clock: 2, State: "null"}))
+		require.NoError(t, err)
+
+		msg, err := decodeMessage(message)
+		require.NoError(t, err)
+		entries, err := decodeAwarenessUpdate(msg.Payload)
+		require.NoError(t, err)
+		assert.Equal(t, []awarenessEntry{{ClientID: 42, Clock: 2, State: "null"}}, entries)
+	})
+
+	t.Run("GuestHasNoRole", func(t *testing.T) {
+		client := &ClientConnection{UserID: uuid.New(), UserName: "guest", Guest: true}
+
+		message, err := stampAwareness(client, awarenessPayload(awarenessEntry{
+			ClientID: 7,
+			State:    `{"user":{"name":"admin","role":"author"}}`,
+		}))
+		require.NoError(t, err)
+
+		user := stampedStates(t, message)[7]["user"].(map[string]any)
+		assert.Equal(t, "guest", user["name"])
+		assert.NotContains(t, user, "role")
+	})
+
+	t.Run("RejectsMalformedUpdate", func(t *testing.T) {
+		client := newClient()
+
+		_, err := stampAwareness(client, []byte{0x01, 0x2a})
+		assert.Error(t, err)
+
+		_, err = stampAwareness(client, awarenessPayload(awarenessEntry{ClientID: 42, State: "not json"}))
+		assert.Error(t, err)
+	})
+}
+
+func TestAwarenessColor(t *testing.T) {
+	// Values computed with randomColor from the frontend.
+	assert.Equal(t, "hsl(0, 70%, 60%)", awarenessColor(""))
+	assert.Equal(t, "hsl(97, 70%, 60%)", awarenessColor("a"))
+	assert.Equal(t, "hsl(224, 70%, 60%)", awarenessColor("6f1c2b8e-3d4a-4b5c-9e7f-0a1b2c3d4e5f"))
+}
+
+func TestHubAwareness(t *testing.T) {
+	// The long interval leaves flushing to the test.
+	manager := NewHubManager(zap.NewNop(), nil, nil, Config{AwarenessInterval: time.Hour})
+	documentID := uuid.New()
+	hub := openHub(t, manager, documentID)
+	t.Cleanup(func() { manager.CloseHub(documentID) })
+
+	inHub := func(t *testing.T, fn func(hub *DocumentHub)) {
+		t.Helper()
+		ok, err := manager.runInHub(context.Background(), documentID, fn)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	// stateOf reads the awareness state the hub holds for a client id.
+	stateOf := func(t *testing.T, clientID uint64) (entry awarenessEntry) {
+		inHub(t, func(hub *DocumentHub) {
+			if state, ok := hub.awareness[clientID]; ok {
+				entry = state.entry
+			}
+		})
+		return entry
+	}
+
+	sender := newTestClient(documentID)
+	sender.UserName = "alice"
+	watcher := newTestClient(documentID)
+	register(t, hub, sender)
+	register(t, hub, watcher)
+
+	t.Run("RelaysLatestStateOnFlush", func(t *testing.T) {
+		// Act
+		for clock := uint64(1); clock <= 3; clock++ {
+			handleAwareness(context.Background(), manager, hub, sender, zap.NewNop(), awarenessPayload(awarenessEntry{
+				ClientID: 42,
+				Clock:    clock,
+				State:    `{"cursor":1}`,
+			}))
+		}
+		require.Eventually(t, func() bool { return stateOf(t, 42).Clock == 3 }, time.Second, 10*time.Millisecond)
+		assert.Empty(t, watcher.Send)
+		inHub(t, manager.flushAwareness)
+
+		// Assert
+		states := stampedStates(t, <-watcher.Send)
+		require.Len(t, states, 1)
+		assert.Equal(t, "alice", states[42]["user"].(map[string]any)["name"])
+		receive(t, sender)
+	})
+
+	t.Run("SendsKnownStatesToNewClients", func(t *testing.T) {
+		late := newTestClient(documentID)
+		register(t, hub, late)
+
+		states := stampedStates(t, <-late.Send)
+		assert.Contains(t, states, uint64(42))
+	})
+
+	t.Run("MarksDisconnectedClientsOffline", func(t *testing.T) {
+		// Act
+		hub.Unregister <- sender
+		require.Eventually(t, func() bool { return stateOf(t, 42).State == "null" }, time.Second, 10*time.Millisecond)
+		inHub(t, manager.flushAwareness)
+
+		// Assert
+		msg := receive(t, watcher)
+		entries, err := decodeAwarenessUpdate(msg.Payload)
+		require.NoError(t, err)
+		assert.Equal(t, []awarenessEntry{{ClientID: 42, Clock: 4, State: "null"}}, entries)
+		assert.Zero(t, stateOf(t, 42))
+	})
+
+	t.Run("ExpiresStatesThatAreNotRenewed", func(t *testing.T) {
+		// Arrange
+		inHub(t, func(hub *DocumentHub) {
+			manager.storeAwareness(hub, nil, []awarenessEntry{{ClientID: 7, Clock: 1, State: `{}`}})
+			manager.flushAwareness(hub)
+			hub.awareness[7].seen = time.Now().Add(-2 * awarenessTimeout)
+		})
+		receive(t, watcher)
+
+		// Act
+		inHub(t, manager.flushAwareness)
+
+		// Assert
+		msg := receive(t, watcher)
+		entries, err := decodeAwarenessUpdate(msg.Payload)
+		require.NoError(t, err)
+		assert.Equal(t, []awarenessEntry{{ClientID: 7, Clock: 2, State: "null"}}, entries)
+	})
+	t.Run("IgnoresClientIDsOfOtherUsers", func(t *testing.T) {
+		// Arrange
+		owner := newTestClient(documentID)
+		spoofer := newTestClient(documentID)
+		reconnected := newTestClient(documentID)
+		reconnected.UserID = owner.UserID
+		inHub(t, func(hub *DocumentHub) {
+			manager.storeAwareness(hub, owner, []awarenessEntry{{ClientID: 50, Clock: 1, State: `{"cursor":1}`}})
+		})
+
+		// Act
+		inHub(t, func(hub *DocumentHub) {
+			manager.storeAwareness(hub, spoofer, []awarenessEntry{{ClientID: 50, Clock: 9, State: `{"cursor":2}`}})
+			manager.storeAwareness(hub, spoofer, []awarenessEntry{{ClientID: 50, Clock: 10, State: "null"}})
+			manager.storeAwareness(hub, nil, []awarenessEntry{{
+				ClientID: 50,
+				Clock:    11,
+				State:    `{"user":{"id":"` + spoofer.UserID.String() + `"}}`,
+			}})
+			manager.removeAwareness(hub, spoofer)
+		})
+
+		// Assert
+		assert.Equal(t, awarenessEntry{ClientID: 50, Clock: 1, State: `{"cursor":1}`}, stateOf(t, 50))
+
+		inHub(t, func(hub *DocumentHub) {
+			manager.storeAwareness(hub, reconnected, []awarenessEntry{{ClientID: 50, Clock: 2, State: `{"cursor":3}`}})
+		})
+		assert.Equal(t, awarenessEntry{ClientID: 50, Clock: 2, State: `{"cursor":3}`}, stateOf(t, 50))
+	})
+}
@@ -0,0 +1,105 @@
+package websocket
+
+import (
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+	"go.uber.org/zap"
+)
+
+// Broker relays hub messages between backend instances serving the same document.
+// Publish must not block the hub goroutine. A subscription replaces the earlier
+// one of its document; unsubscribing with the ID of a replaced subscription
+// does nothing, so a stopping hub leaves alone the hub that replaced it.
+type Broker interface {
+	Subscribe(documentID uuid.UUID, deliver func(message []byte), resync func()) (uint64, error)
+	Unsubscribe(documentID uuid.UUID, subscription uint64) error
+	Publish(documentID uuid.UUID, message []byte)
+}
+
+// subscribeHub connects a new hub to its peers on other instances and asks them
+// for updates that are not yet part of the loaded snapshot.
+func (m *HubManager) subscribeHub(hub *DocumentHub) {
+	if m.broker == nil {
+		return
+	}
+
+	deliver := func(message []byte) {
+		select {
+		case hub.Remote <- message:
+		default:
+			m.logger.Warn("dropping remote message; channel full", zap.String("document_id", hub.DocumentID.String()))
+		}
+	}
+	// Messages may have been lost both ways: announce our state vector so that
+	// peers send what we miss, and ask them to announce theirs so that we send
+	// what they miss.
+	resync := func() {
+		deliver(encodeSyncMessage(YjsSyncStep1, nil))
+		m.broker.Publish(hub.DocumentID, encodeSyncMessage(YjsSyncStep1, nil))
+	}
+
+	subscription, err := m.broker.Subscribe(hub.DocumentID, deliver, resync)
+	hub.subscription = subscription
+	if err != nil {
+		m.logger.Warn("failed to subscribe hub to broker", zap.String("document_id", hub.DocumentID.String()), zap.Error(err))
+		return
+	}
+	m.broker.Publish(hub.DocumentID, encodeSyncMessage(YjsSyncStep1, hub.YjsDoc.EncodeStateVector()))
+}
+
+func (m *HubManager) unsubscribeHub(hub *DocumentHub) {
+	if m.broker == nil {
+		return
+	}
+	if err := m.broker.Unsubscribe(hub.DocumentID, hub.subscription); err != nil {
+		m.logger.Warn("failed to unsubscribe hub from broker", zap.String("document_id", hub.DocumentID.String()), zap.Error(err))
+	}
+}
+
+func (m *HubManager) publish(hub *DocumentHub, message []byte) {
+	if m.broker == nil {
+		return
+	}
+	m.broker.Publish(hub.DocumentID, message)
+}
+
+// handleRemoteMessage applies a message received from another instance and fans
+// it out to local clients without publishing it again.
+func (m *HubManager) handleRemoteMessage(hub *DocumentHub, message []byte) {
+	msg, err := decodeMessage(message)
+	if err != nil {
+		return
+	}
+
+	if msg.Type == MessageTypeControl {
+		m.handleRemoteControl(hub, msg)
+		return
+	}
+
+	if msg.Type == MessageTypeAwareness {
+		if entries, err := decodeAwarenessUpdate(msg.Payload); err == nil {
+			m.storeAwareness(hub, nil, entries)
+		}
+		return
+	}
+
+	if msg.Type == MessageTypeSync && msg.Step == YjsSyncStep1 {
+		// An empty payload is a resync request, local or from a peer that may
+		// have lost messages: announce our own state vector.
+		if len(msg.Payload) == 0 {
+			m.publish(hub, encodeSyncMessage(YjsSyncStep1, hub.YjsDoc.EncodeStateVector()))
+			return
+		}
+		sv, err := yjs.DecodeStateVector(msg.Payload)
+		if err != nil || hub.YjsDoc.IsEmpty() {
+			return
+		}
+		m.publish(hub, encodeSyncMessage(YjsSyncStep2, hub.YjsDoc.EncodeStateAsUpdate(sv)))
+		return
+	}
+
+	if !m.applyMessage(hub, msg) {
+		return
+	}
+	m.fanOut(hub, message)
+}
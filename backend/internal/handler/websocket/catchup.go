@@ -0,0 +1,82 @@
+package websocket
+
+import (
+	"context"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// resumeVersion reads the version query parameter a reconnecting client sends
+// with the version of its last handshake. Missing or invalid values yield 0.
+func resumeVersion(c *gin.Context) int {
+	version, err := strconv.Atoi(c.Query("version"))
+	if err != nil || version < 0 {
+		return 0
+	}
+	return version
+}
+
+// newSyncRequest prepares a client's SyncStep1 for the hub. The first request
+// of a reconnecting client carries the updates persisted after the version it
+// resumes from, so that updates the hub never merged still reach it. The
+// updates are loaded here to keep database reads off the hub goroutine.
+func (m *HubManager) newSyncRequest(ctx context.Context, client *ClientConnection, stateVector []byte) syncRequest {
+	req := syncRequest{client: client, stateVector: stateVector}
+	if client.ResumeVersion == 0 || m.persistence == nil {
+		return req
+	}
+
+	fromVersion := client.ResumeVersion
+	client.ResumeVersion = 0
+
+	updates, err := m.persistence.GetUpdates(ctx, client.DocumentID, fromVersion)
+	if err != nil {
+		m.logger.Warn(
+			"failed to load updates for reconnecting client",
+			zap.String("document_id", client.DocumentID.String()),
+			zap.Int("from_version", fromVersion),
+			zap.Error(err),
+		)
+		return req
+	}
+	req.updates = updates
+	return req
+}
+
+// catchUp merges the persisted updates of a sync request into the hub state.
+// Updates the hub already holds change nothing; anything new, e.g. an update
+// stored by a hub that did not reach this one, is relayed to the clients and
+// peers. The SyncStep2 answer then covers it for the requester.
+func (m *HubManager) catchUp(hub *DocumentHub, req syncRequest) {
+	if len(req.updates) == 0 {
+		return
+	}
+
+	before := hub.YjsDoc.StateVector()
+	for _, update := range req.updates {
+		if err := hub.YjsDoc.ApplyUpdate(update.YjsUpdate); err != nil {
+			m.logger.Debug(
+				"skipping undecodable persisted update",
+				zap.String("document_id", hub.DocumentID.String()),
+				zap.Int64("update_id", update.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		hub.persistedVersion = max(hub.persistedVersion, update.Version)
+		hub.Version = max(hub.Version, update.Version)
+	}
+
+	if maps.Equal(before, hub.YjsDoc.StateVector()) {
+		return
+	}
+	hub.LastUpdated = time.Now()
+
+	message := encodeSyncMessage(YjsUpdate, hub.YjsDoc.EncodeStateAsUpdate(before))
+	m.publish(hub, message)
+	m.fanOut(hub, message)
+}
@@ -0,0 +1,43 @@
+package websocket
+
+import (
+	"encoding/json"
+
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+)
+
+// Control message kinds sent to clients in MessageTypeControl frames.
+const (
+	controlPermissionChanged = "permission_changed"
+	controlAccessRevoked     = "access_revoked"
+	controlDocumentDeleted   = "document_deleted"
+	controlHandshake         = "handshake"
+	controlDocumentTooLarge  = "document_too_large"
+)
+
+type controlMessage struct {
+	Type    string `json:"type"`
+	Role    string `json:"role,omitempty"`
+	CanEdit bool   `json:"can_edit"`
+	// Version is the stored version a client resumes from after reconnecting,
+	// sent back as the version query parameter.
+	Version int `json:"version,omitempty"`
+	// Limit is the maximum document size, in bytes, an update was refused for.
+	Limit int `json:"limit,omitempty"`
+}
+
+// sendControl queues a notice for a client. It runs on the hub goroutine.
+func (m *HubManager) sendControl(hub *DocumentHub, client *ClientConnection, control controlMessage) {
+	m.deliver(hub, client, encodeControl(control))
+}
+
+// encodeControl wraps a notice into a MessageTypeControl frame.
+func encodeControl(control controlMessage) []byte {
+	// Marshalling a struct of strings, bools and ints cannot fail.
+	payload, _ := json.Marshal(control) //nolint:errchkjson
+
+	enc := yjs.NewEncoder()
+	enc.WriteVarUint(MessageTypeControl)
+	enc.WriteVarString(string(payload))
+	return enc.Bytes()
+}
@@ -0,0 +1,58 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// CloseRoom shuts down the room of a deleted document on this instance and on
+// its peers. Clients are told about the deletion and disconnected, and the
+// state of the room is dropped instead of being persisted. It returns once the
+// hub on this instance stopped, so that it writes nothing afterwards.
+func (m *HubManager) CloseRoom(ctx context.Context, documentID uuid.UUID) {
+	var dropped *DocumentHub
+	_, err := m.runInHub(ctx, documentID, func(hub *DocumentHub) {
+		m.dropHub(hub)
+		dropped = hub
+	})
+	if err == nil && dropped != nil {
+		select {
+		case <-dropped.stopped:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	if err != nil {
+		m.logger.Warn("failed to close room of deleted document", zap.String("document_id", documentID.String()), zap.Error(err))
+	}
+
+	if m.broker != nil {
+		m.broker.Publish(documentID, encodeControl(controlMessage{Type: controlDocumentDeleted}))
+	}
+}
+
+// dropHub disconnects every client of a deleted document and closes its hub.
+// It runs on the hub goroutine.
+func (m *HubManager) dropHub(hub *DocumentHub) {
+	hub.deleted = true
+	for client := range hub.Clients {
+		client.setAccess("", false)
+		m.disconnect(hub, client, controlMessage{Type: controlDocumentDeleted})
+		delete(hub.Clients, client)
+	}
+	m.closeHub(hub)
+}
+
+// handleRemoteControl handles a notice relayed by another instance.
+func (m *HubManager) handleRemoteControl(hub *DocumentHub, msg protocolMessage) {
+	var control controlMessage
+	if err := json.Unmarshal(msg.Payload, &control); err != nil {
+		return
+	}
+	if control.Type == controlDocumentDeleted {
+		m.dropHub(hub)
+	}
+}
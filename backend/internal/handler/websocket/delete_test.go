@@ -0,0 +1,107 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHubCloseRoom(t *testing.T) {
+	setup := func(t *testing.T) (*HubManager, *recordingBroker, uuid.UUID, *ClientConnection) {
+		broker := &recordingBroker{}
+		manager := NewHubManager(zap.NewNop(), nil, broker, Config{})
+		documentID := uuid.New()
+		hub := openHub(t, manager, documentID)
+		t.Cleanup(func() { manager.CloseHub(documentID) })
+
+		client := newTestClient(documentID)
+		register(t, hub, client)
+		hub.Broadcast <- encodeSyncMessage(YjsUpdate, textInsert(7, "hello"))
+		receive(t, client)
+		return manager, broker, documentID, client
+	}
+
+	assertDropped := func(t *testing.T, manager *HubManager, documentID uuid.UUID, client *ClientConnection) {
+		t.Helper()
+		assert.Equal(t, controlMessage{Type: controlDocumentDeleted}, receiveControl(t, client))
+		_, open := <-client.Send
+		assert.False(t, open)
+		_, canEdit := client.access()
+		assert.False(t, canEdit)
+
+		require.Eventually(t, func() bool {
+			_, _, ok, err := manager.EncodeState(context.Background(), documentID)
+			return err == nil && !ok
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	t.Run("DisconnectsClientsAndNotifiesPeers", func(t *testing.T) {
+		// Arrange
+		manager, broker, documentID, client := setup(t)
+
+		// Act
+		manager.CloseRoom(context.Background(), documentID)
+
+		// Assert
+		assertDropped(t, manager, documentID, client)
+		messages := broker.messages()
+		control, err := decodeMessage(messages[len(messages)-1])
+		require.NoError(t, err)
+		assert.EqualValues(t, MessageTypeControl, control.Type)
+	})
+
+	t.Run("ReturnsOnceTheHubStopped", func(t *testing.T) {
+		// Arrange
+		manager, _, documentID, _ := setup(t)
+		hub, ok := manager.lookupHub(documentID)
+		require.True(t, ok)
+
+		// Act
+		manager.CloseRoom(context.Background(), documentID)
+
+		// Assert
+		select {
+		case <-hub.stopped:
+		default:
+			t.Fatal("hub still running")
+		}
+	})
+
+	t.Run("FollowsDeletionOnPeer", func(t *testing.T) {
+		// Arrange
+		manager, broker, documentID, client := setup(t)
+		published := len(broker.messages())
+
+		// Act
+		broker.deliver(encodeControl(controlMessage{Type: controlDocumentDeleted}))
+
+		// Assert
+		assertDropped(t, manager, documentID, client)
+		assert.Len(t, broker.messages(), published)
+	})
+
+	t.Run("KeepsNoticeForFullSendBuffer", func(t *testing.T) {
+		// Arrange
+		manager, _, documentID, client := setup(t)
+		fillSend(client)
+
+		// Act
+		manager.CloseRoom(context.Background(), documentID)
+
+		// Assert
+		assert.Equal(t, controlMessage{Type: controlDocumentDeleted}, finalControl(t, client))
+	})
+
+	t.Run("IgnoresMissingRoom", func(t *testing.T) {
+		manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+
+		assert.NotPanics(t, func() {
+			manager.CloseRoom(context.Background(), uuid.New())
+		})
+	})
+}
@@ -0,0 +1,191 @@
+package websocket
+
+import (
+	"maps"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxQueuedUpdates is how many document messages a slow client may fall
+	// behind before they are replaced by a resync with the full state.
+	maxQueuedUpdates = 256
+	// flushInterval is how often the hub retries queued messages.
+	flushInterval = 50 * time.Millisecond
+)
+
+// DeliveryStats counts how messages to slow clients were handled.
+type DeliveryStats struct {
+	// Dropped messages were superseded by a full-state resync.
+	Dropped int64 `json:"dropped"`
+	// Coalesced awareness states were replaced by a newer state of the same
+	// client before they were sent.
+	Coalesced int64 `json:"coalesced"`
+	// Resynced counts full states sent to clients that fell too far behind.
+	Resynced int64 `json:"resynced"`
+}
+
+type deliveryCounters struct {
+	dropped   atomic.Int64
+	coalesced atomic.Int64
+	resynced  atomic.Int64
+}
+
+// DeliveryStats returns the delivery counters of all hubs on this instance.
+func (m *HubManager) DeliveryStats() DeliveryStats {
+	return DeliveryStats{
+		Dropped:   m.delivery.dropped.Load(),
+		Coalesced: m.delivery.coalesced.Load(),
+		Resynced:  m.delivery.resynced.Load(),
+	}
+}
+
+// outbox holds the messages a client could not take yet. It is owned by the
+// hub goroutine.
+type outbox struct {
+	// control holds server notices; they are never dropped since the full
+	// state sent on resync does not carry them.
+	control  [][]byte
+	messages [][]byte
+	// awareness holds queued awareness states, the latest one per client id.
+	awareness map[uint64]awarenessEntry
+	// resync replaces every dropped document message with the full state.
+	resync bool
+}
+
+// deliver sends a message to a client, queueing it while the client's send
+// buffer is full. Queued control notices go first; queued document messages
+// keep their order and queued awareness states are merged and sent after them.
+func (m *HubManager) deliver(hub *DocumentHub, client *ClientConnection, message []byte) {
+	if m.flushClient(hub, client) {
+		select {
+		case client.Send <- message:
+			return
+		default:
+		}
+	}
+
+	queue := &client.outbox
+	if msg, err := decodeMessage(message); err == nil {
+		switch msg.Type {
+		case MessageTypeAwareness:
+			m.queueAwareness(queue, msg.Payload)
+			return
+		case MessageTypeControl:
+			queue.control = append(queue.control, message)
+			return
+		}
+	}
+
+	if queue.resync {
+		// The full state sent on resync already covers the message.
+		m.delivery.dropped.Add(1)
+		return
+	}
+	if len(queue.messages) == maxQueuedUpdates {
+		m.delivery.dropped.Add(int64(len(queue.messages)) + 1)
+		queue.messages = nil
+		queue.resync = true
+		return
+	}
+	queue.messages = append(queue.messages, message)
+}
+
+// queueAwareness merges an awareness update into the queued states.
+func (m *HubManager) queueAwareness(queue *outbox, payload []byte) {
+	entries, err := decodeAwarenessUpdate(payload)
+	if err != nil {
+		return
+	}
+	if queue.awareness == nil {
+		queue.awareness = make(map[uint64]awarenessEntry, len(entries))
+	}
+
+	for _, entry := range entries {
+		if queued, ok := queue.awareness[entry.ClientID]; ok {
+			if queued.Clock > entry.Clock {
+				continue
+			}
+			m.delivery.coalesced.Add(1)
+		}
+		queue.awareness[entry.ClientID] = entry
+	}
+}
+
+// flushClient moves queued messages into the client's send buffer and reports
+// whether the queue is empty afterwards.
+func (m *HubManager) flushClient(hub *DocumentHub, client *ClientConnection) bool {
+	queue := &client.outbox
+	for len(queue.control) > 0 {
+		select {
+		case client.Send <- queue.control[0]:
+			queue.control = queue.control[1:]
+		default:
+			return false
+		}
+	}
+	queue.control = nil
+
+	if queue.resync {
+		select {
+		case client.Send <- encodeSyncMessage(YjsSyncStep2, hub.YjsDoc.EncodeStateAsUpdate(nil)):
+			queue.resync = false
+			m.delivery.resynced.Add(1)
+		default:
+			return false
+		}
+	}
+
+	for len(queue.messages) > 0 {
+		select {
+		case client.Send <- queue.messages[0]:
+			queue.messages = queue.messages[1:]
+		default:
+			return false
+		}
+	}
+	queue.messages = nil
+
+	if len(queue.awareness) > 0 {
+		entries := sortAwareness(slices.Collect(maps.Values(queue.awareness)))
+		select {
+		case client.Send <- encodeAwarenessMessage(entries):
+			queue.awareness = nil
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// disconnect closes the connection of a client with a final notice. When the
+// send buffer has no room for it, the notice and the control notices still
+// queued are left to the write goroutine, which writes them once it drained
+// Send, so that closing Send right away cannot lose them.
+func (m *HubManager) disconnect(hub *DocumentHub, client *ClientConnection, notice controlMessage) {
+	message := encodeControl(notice)
+	sent := false
+	if m.flushClient(hub, client) {
+		select {
+		case client.Send <- message:
+			sent = true
+		default:
+		}
+	}
+	if !sent {
+		client.finalMessages = append(client.outbox.control, message)
+	}
+	client.outbox = outbox{}
+	close(client.Send)
+}
+
+// flushQueues retries the queued messages of every client of the hub.
+func (m *HubManager) flushQueues(hub *DocumentHub) {
+	for client := range hub.Clients {
+		queue := &client.outbox
+		if len(queue.control) > 0 || queue.resync || len(queue.messages) > 0 || len(queue.awareness) > 0 {
+			m.flushClient(hub, client)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHubDelivery(t *testing.T) {
+	setup := func(t *testing.T) (*HubManager, *DocumentHub, *ClientConnection) {
+		manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+		documentID := uuid.New()
+		hub := openHub(t, manager, documentID)
+		t.Cleanup(func() { manager.CloseHub(documentID) })
+
+		client := newTestClient(documentID)
+		register(t, hub, client)
+		return manager, hub, client
+	}
+
+	// fill takes every free slot of the client's send buffer.
+	fill := func(client *ClientConnection) {
+		for len(client.Send) < cap(client.Send) {
+			client.Send <- []byte{MessageTypeSync}
+		}
+	}
+
+	// drain empties the send buffer and returns the messages after the first skip.
+	drain := func(client *ClientConnection, skip int) [][]byte {
+		var messages [][]byte
+		for len(client.Send) > 0 {
+			message := <-client.Send
+			if skip > 0 {
+				skip--
+				continue
+			}
+			messages = append(messages, message)
+		}
+		return messages
+	}
+
+	inHub := func(t *testing.T, manager *HubManager, hub *DocumentHub, fn func(hub *DocumentHub)) {
+		t.Helper()
+		ok, err := manager.runInHub(context.Background(), hub.DocumentID, fn)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	t.Run("QueuesForSlowClientsInOrder", func(t *testing.T) {
+		manager, hub, client := setup(t)
+		fill(client)
+		first := encodeSyncMessage(YjsUpdate, textInsert(7, "a"))
+		second := encodeSyncMessage(YjsUpdate, textInsert(8, "b"))
+
+		// Act
+		inHub(t, manager, hub, func(hub *DocumentHub) {
+			manager.fanOut(hub, first)
+			manager.fanOut(hub, second)
+		})
+		messages := drain(client, cap(client.Send))
+		inHub(t, manager, hub, manager.flushQueues)
+
+		// Assert: the client stays connected and gets both updates
+		assert.Equal(t, [][]byte{first, second}, append(messages, drain(client, 0)...))
+		inHub(t, manager, hub, func(hub *DocumentHub) {
+			assert.Contains(t, hub.Clients, client)
+		})
+	})
+
+	t.Run("CoalescesAwarenessPerSender", func(t *testing.T) {
+		manager, hub, client := setup(t)
+		fill(client)
+		older := encodeAwarenessMessage([]awarenessEntry{{ClientID: 1, Clock: 1, State: `{"cursor":1}`}})
+		other := encodeAwarenessMessage([]awarenessEntry{{ClientID: 2, Clock: 1, State: `{"cursor":5}`}})
+		newer := encodeAwarenessMessage([]awarenessEntry{{ClientID: 1, Clock: 2, State: `{"cursor":2}`}})
+
+		// Act
+		inHub(t, manager, hub, func(hub *DocumentHub) {
+			manager.fanOut(hub, older)
+			manager.fanOut(hub, other)
+			manager.fanOut(hub, newer)
+		})
+		messages := drain(client, cap(client.Send))
+		inHub(t, manager, hub, manager.flushQueues)
+
+		// Assert: one merged update with the latest state of each sender
+		merged := encodeAwarenessMessage([]awarenessEntry{
+			{ClientID: 1, Clock: 2, State: `{"cursor":2}`},
+			{ClientID: 2, Clock: 1, State: `{"cursor":5}`},
+		})
+		assert.Equal(t, [][]byte{merged}, append(messages, drain(client, 0)...))
+		assert.EqualValues(t, 1, manager.DeliveryStats().Coalesced)
+	})
+
+	t.Run("ResyncsClientsThatFallTooFarBehind", func(t *testing.T) {
+		manager, hub, client := setup(t)
+		fill(client)
+
+		// Act
+		inHub(t, manager, hub, func(hub *DocumentHub) {
+			for i := range maxQueuedUpdates + 2 {
+				update := textInsert(uint64(i+1), "x")
+				require.NoError(t, hub.YjsDoc.ApplyUpdate(update))
+				manager.fanOut(hub, encodeSyncMessage(YjsUpdate, update))
+			}
+		})
+		messages := drain(client, cap(client.Send))
+		inHub(t, manager, hub, manager.flushQueues)
+
+		// Assert: a single full state replaces the queued updates
+		messages = append(messages, drain(client, 0)...)
+		require.Len(t, messages, 1)
+		msg, err := decodeMessage(messages[0])
+		require.NoError(t, err)
+		assert.Equal(t, uint64(YjsSyncStep2), msg.Step)
+		inHub(t, manager, hub, func(hub *DocumentHub) {
+			assert.Equal(t, hub.YjsDoc.EncodeStateAsUpdate(nil), msg.Payload)
+		})
+
+		stats := manager.DeliveryStats()
+		assert.EqualValues(t, maxQueuedUpdates+2, stats.Dropped)
+		assert.EqualValues(t, 1, stats.Resynced)
+	})
+	t.Run("KeepsControlNoticesOfClientsThatFellBehind", func(t *testing.T) {
+		manager, hub, client := setup(t)
+		fill(client)
+		control := controlMessage{Type: controlPermissionChanged, Role: "viewer"}
+
+		// Act: the notice comes after the queue overflowed
+		inHub(t, manager, hub, func(hub *DocumentHub) {
+			for i := range maxQueuedUpdates + 1 {
+				update := textInsert(uint64(i+1), "x")
+				require.NoError(t, hub.YjsDoc.ApplyUpdate(update))
+				manager.fanOut(hub, encodeSyncMessage(YjsUpdate, update))
+			}
+			manager.sendControl(hub, client, control)
+		})
+		messages := drain(client, cap(client.Send))
+		inHub(t, manager, hub, manager.flushQueues)
+
+		// Assert: the notice is sent ahead of the full state
+		messages = append(messages, drain(client, 0)...)
+		require.Len(t, messages, 2)
+		assert.Equal(t, encodeControl(control), messages[0])
+		msg, err := decodeMessage(messages[1])
+		require.NoError(t, err)
+		assert.Equal(t, uint64(YjsSyncStep2), msg.Step)
+		assert.EqualValues(t, maxQueuedUpdates+1, manager.DeliveryStats().Dropped)
+	})
+}
@@ -2,12 +2,9 @@ package websocket
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/binary"
 	"errors"
-	"hash/crc32"
+	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
@@ -25,13 +22,8 @@ const (
 	maxMessageSize = 65536
 )
 
-func generateAwarenessID() (uint32, error) {
-	var buf [4]byte
-	if _, err := rand.Read(buf[:]); err != nil {
-		return 0, err
-	}
-	return binary.LittleEndian.Uint32(buf[:]), nil
-}
+// protocolErrorCloseMessage tells a client that it sent a malformed frame.
+var protocolErrorCloseMessage = websocket.FormatCloseMessage(websocket.CloseProtocolError, "malformed message")
 
 // createUpgrader creates a websocket upgrader with secure origin checking.
 func createUpgrader(allowedOrigins []string) websocket.Upgrader {
@@ -110,7 +102,7 @@ func NewWebSocketHandler(
 			return
 		}
 
-		_, err = documentService.GetByUUIDForUser(c.Request.Context(), documentID, userUUID)
+		document, err := documentService.GetByUUIDForUser(c.Request.Context(), documentID, userUUID)
 		if errors.Is(err, domain.ErrForbidden) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "access forbidden"})
 			return
@@ -131,12 +123,6 @@ func NewWebSocketHandler(
 			return
 		}
 
-		awarenessID, err := generateAwarenessID()
-		if err != nil {
-			logger.Warn("failed to generate awareness id, falling back to timestamp", zap.Error(err))
-			awarenessID = crc32.ChecksumIEEE([]byte(strconv.FormatInt(time.Now().UnixNano(), 10)))
-		}
-
 		role, err := documentService.GetMemberRole(c.Request.Context(), documentID, userUUID)
 		if err != nil {
 			if errors.Is(err, domain.ErrForbidden) {
@@ -151,23 +137,37 @@ func NewWebSocketHandler(
 		}
 		canEdit := role != domain.RoleViewer
 
+		userName := c.GetString("user_login")
+		if userName == "" {
+			userName = userUUID.String()
+		}
+
 		client := &ClientConnection{
-			ID:          uuid.New(),
-			UserID:      userUUID,
-			UserName:    userUUID.String(),
-			DocumentID:  documentID,
-			Conn:        conn,
-			Send:        make(chan []byte, initialSendBufferSize),
-			Done:        make(chan struct{}),
-			LastSeen:    time.Now(),
-			AwarenessID: awarenessID,
-			CanEdit:     canEdit,
+			ID:            uuid.New(),
+			UserID:        userUUID,
+			UserName:      userName,
+			DocumentID:    documentID,
+			GroupID:       document.GroupUUID,
+			Conn:          conn,
+			Send:          make(chan []byte, initialSendBufferSize),
+			Done:          make(chan struct{}),
+			LastSeen:      time.Now(),
+			Role:          role,
+			CanEdit:       canEdit,
+			ResumeVersion: resumeVersion(c),
 		}
 
-		hub := hubManager.GetOrCreateHub(documentID)
+		hub, err := hubManager.GetOrCreateHub(c.Request.Context(), documentID)
+		if err != nil {
+			logger.Warn("failed to open collaboration hub", zap.Error(err), zap.String("document_id", documentID.String()))
+			closeRefused(conn, err, logger)
+			return
+		}
+		hubManager.addPresence(c.Request.Context(), client)
 		select {
 		case hub.Register <- client:
 		case <-c.Request.Context().Done():
+			hubManager.removePresence(client)
 			if err := conn.Close(); err != nil {
 				logger.Warn("failed to close websocket connection on context cancellation",
 					zap.Error(err),
@@ -179,12 +179,35 @@ func NewWebSocketHandler(
 			return
 		}
 
-		go writePump(client, logger)
+		go writePump(hubManager, hub, client, logger)
 		readPump(c.Request.Context(), hubManager, hub, client, logger)
 	}
 }
 
-func writePump(client *ClientConnection, logger *zap.Logger) {
+// closeRefused turns away a connection whose document hub could not be opened:
+// because the server shuts down, or because its state failed to load.
+func closeRefused(conn *websocket.Conn, err error, logger *zap.Logger) {
+	message := unavailableCloseMessage
+	if errors.Is(err, ErrShuttingDown) {
+		message = restartCloseMessage
+	}
+	if err := conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(writeWait)); err != nil {
+		logger.Debug("failed to write refusal close message", zap.Error(err))
+	}
+	if err := conn.Close(); err != nil {
+		logger.Debug("failed to close websocket of refused connection", zap.Error(err))
+	}
+}
+
+// closeProtocolError tells a client that it sent a frame the server cannot
+// decode. The connection is closed by the caller.
+func closeProtocolError(conn *websocket.Conn, logger *zap.Logger) {
+	if err := conn.WriteControl(websocket.CloseMessage, protocolErrorCloseMessage, time.Now().Add(writeWait)); err != nil {
+		logger.Debug("failed to write protocol error close message", zap.Error(err))
+	}
+}
+
+func writePump(hubManager *HubManager, hub *DocumentHub, client *ClientConnection, logger *zap.Logger) {
 	ticker := time.NewTicker(pingPeriod)
 	defer func() {
 		ticker.Stop()
@@ -200,7 +223,18 @@ func writePump(client *ClientConnection, logger *zap.Logger) {
 		select {
 		case message, ok := <-client.Send:
 			if !ok {
-				if err := client.Conn.WriteMessage(websocket.CloseMessage, []byte{}); err != nil {
+				for _, message := range client.finalMessages {
+					if err := client.Conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+						logger.Debug("failed to set write deadline for final message", zap.Error(err))
+						return
+					}
+					if err := client.Conn.WriteMessage(websocket.BinaryMessage, message); err != nil {
+						logger.Debug("failed to write final message", zap.Error(err))
+						return
+					}
+					hubManager.countSent(hub, message)
+				}
+				if err := client.Conn.WriteMessage(websocket.CloseMessage, client.closeMessage); err != nil {
 					logger.Debug("failed to write close message when channel closed", zap.Error(err))
 				}
 				return
@@ -219,6 +253,7 @@ func writePump(client *ClientConnection, logger *zap.Logger) {
 				logger.Warn("failed to write websocket message", zap.Error(err))
 				return
 			}
+			hubManager.countSent(hub, message)
 		case <-ticker.C:
 			if err := client.Conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
 				logger.Warn("failed to set write deadline for ping message",
@@ -289,7 +324,18 @@ func readPump(
 			break
 		}
 
-		handleClientMessage(requestCtx, hubManager, hub, client, logger, message)
+		hubManager.countReceived(hub, message)
+		if err := handleClientMessage(requestCtx, hubManager, hub, client, logger, message); err != nil {
+			hubManager.countRejected(hub)
+			logger.Debug(
+				"closing connection after malformed websocket message",
+				zap.Error(err),
+				zap.String("document_id", hub.DocumentID.String()),
+				zap.String("client_id", client.ID.String()),
+			)
+			closeProtocolError(client.Conn, logger)
+			break
+		}
 
 		select {
 		case <-requestCtx.Done():
@@ -299,12 +345,35 @@ func readPump(
 	}
 }
 
-func handleAwareness(hub *DocumentHub, client *ClientConnection, message []byte) {
+func handleAwareness(
+	requestCtx context.Context,
+	hubManager *HubManager,
+	hub *DocumentHub,
+	client *ClientConnection,
+	logger *zap.Logger,
+	payload []byte,
+) error {
 	client.LastSeen = time.Now()
+	hubManager.touchPresence(requestCtx, client)
+
+	message, err := stampAwareness(client, payload)
+	if err != nil {
+		return fmt.Errorf("websocket: malformed awareness update: %w", err)
+	}
+	if message == nil {
+		return nil
+	}
+
+	if !client.queueAwareness(message) {
+		hubManager.delivery.coalesced.Add(1)
+		return nil
+	}
 	select {
-	case hub.Broadcast <- message:
-	default:
+	case hub.Awareness <- client:
+	case <-hub.Done:
+	case <-requestCtx.Done():
 	}
+	return nil
 }
 
 func handleClientMessage(
@@ -314,53 +383,50 @@ func handleClientMessage(
 	client *ClientConnection,
 	logger *zap.Logger,
 	message []byte,
-) {
+) error {
 	client.LastSeen = time.Now()
 	if len(message) == 0 {
-		return
+		return nil
+	}
+
+	msg, err := decodeMessage(message)
+	if err != nil {
+		return err
+	}
+	// The span of an update is bounded like the size of the document: each
+	// clock of visible text takes at least a byte of the state.
+	if err := validateClientMessage(msg, hub.maxSize); err != nil {
+		return err
 	}
 
-	msgType := message[0]
-	switch msgType {
-	case MessageTypeAwareness:
-		handleAwareness(hub, client, message)
+	switch {
+	case msg.Type == MessageTypeQueryAwareness:
+	case msg.Type == MessageTypeAwareness:
+		return handleAwareness(requestCtx, hubManager, hub, client, logger, msg.Payload)
+	case msg.Type == MessageTypeSync && msg.Step == YjsSyncStep1:
+		// A client whose SyncStep1 is lost never syncs, so like edits it
+		// waits for a busy hub.
+		select {
+		case hub.SyncStep1 <- hubManager.newSyncRequest(requestCtx, client, msg.Payload):
+		case <-hub.Done:
+		case <-requestCtx.Done():
+		}
 	default:
-		if msgType == YjsUpdate && !client.CanEdit {
+		if _, canEdit := client.access(); !canEdit {
 			logger.Debug(
 				"blocking update from read-only client",
 				zap.String("document_id", hub.DocumentID.String()),
 				zap.String("client_id", client.ID.String()),
 			)
-			return
+			return nil
 		}
 
+		// Edits are never dropped; a busy hub slows down the sender instead.
 		select {
-		case hub.Broadcast <- message:
-		default:
-			logger.Warn(
-				"dropping broadcast message; channel full",
-				zap.String("document_id", hub.DocumentID.String()),
-			)
-		}
-
-		if msgType == YjsUpdate && hubManager.persistence != nil {
-			versionToStore := hub.Version
-			if versionToStore < 1 {
-				versionToStore = defaultHubVersion
-			}
-			if err := hubManager.persistence.SaveUpdate(
-				requestCtx,
-				hub.DocumentID,
-				client.UserID,
-				message,
-				versionToStore+1,
-			); err != nil {
-				logger.Warn(
-					"failed to persist update",
-					zap.Error(err),
-					zap.String("document_id", hub.DocumentID.String()),
-				)
-			}
+		case hub.Updates <- clientUpdate{client: client, message: message}:
+		case <-hub.Done:
+		case <-requestCtx.Done():
 		}
 	}
+	return nil
 }
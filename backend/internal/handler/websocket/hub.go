@@ -2,11 +2,14 @@ package websocket
 
 import (
 	"context"
-	"sync"
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
 	"go.uber.org/zap"
 )
 
@@ -16,79 +19,89 @@ const (
 	registerBufferSize    = 32
 	unregisterBufferSize  = 32
 	broadcastBufferSize   = 128
+	syncBufferSize        = 32
+	remoteBufferSize      = 128
+	awarenessBufferSize   = 128
 	initialSendBufferSize = 128
+	// expiryInterval is how often clients are checked for expired access.
+	expiryInterval = time.Second
+	// defaultMaxDocumentSize caps the encoded state of documents whose group
+	// sets no limit of its own.
+	defaultMaxDocumentSize = 4 << 20
 )
 
+// Config tunes the collaboration hubs.
+type Config struct {
+	// AwarenessInterval is how often awareness changes are relayed to clients.
+	AwarenessInterval time.Duration
+	// MaxDocumentSize caps the encoded state of a document, in bytes, unless
+	// its group sets a limit of its own.
+	MaxDocumentSize int
+}
+
 // HubManager coordinates hubs per document and handles persistence.
 type HubManager struct {
-	hubs        map[uuid.UUID]*DocumentHub
-	mu          sync.RWMutex
+	shards      []*hubShard
 	logger      *zap.Logger
 	persistence *repo.DocumentPersistence
+	broker      Broker
+	delivery    deliveryCounters
+	cfg         Config
+	// updates persists document updates; nil when persistence is disabled.
+	updates updateStore
+	// states loads the persisted state of new hubs; nil when persistence is
+	// disabled.
+	states stateStore
+	// presence records connected members; nil when persistence is disabled.
+	presence         presenceStore
+	presenceReleases *presenceReleases
+	// closing is set by Shutdown; no hubs are opened afterwards.
+	closing atomic.Bool
+	// traffic totals the traffic of every hub since startedAt.
+	traffic   trafficCounters
+	startedAt time.Time
 }
 
-// NewHubManager constructs a manager for collaboration hubs.
-func NewHubManager(logger *zap.Logger, persistence *repo.DocumentPersistence) *HubManager {
-	return &HubManager{
-		hubs:        make(map[uuid.UUID]*DocumentHub),
-		logger:      logger,
-		persistence: persistence,
-	}
-}
-
-// GetOrCreateHub returns an existing hub for a document or initializes a new one, loading persisted state if available.
-func (m *HubManager) GetOrCreateHub(documentID uuid.UUID) *DocumentHub {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if hub, ok := m.hubs[documentID]; ok {
-		return hub
+// NewHubManager constructs a manager for collaboration hubs. A nil broker keeps
+// hubs local to this instance.
+func NewHubManager(logger *zap.Logger, persistence *repo.DocumentPersistence, broker Broker, cfg Config) *HubManager {
+	if cfg.AwarenessInterval <= 0 {
+		cfg.AwarenessInterval = defaultAwarenessInterval
 	}
-
-	hub := &DocumentHub{
-		DocumentID:  documentID,
-		Clients:     make(map[*ClientConnection]bool),
-		Broadcast:   make(chan []byte, broadcastBufferSize),
-		Register:    make(chan *ClientConnection, registerBufferSize),
-		Unregister:  make(chan *ClientConnection, unregisterBufferSize),
-		Done:        make(chan struct{}),
-		Version:     defaultHubVersion,
-		LastUpdated: time.Now(),
+	if cfg.MaxDocumentSize <= 0 {
+		cfg.MaxDocumentSize = defaultMaxDocumentSize
 	}
+	m := &HubManager{
+		shards:      newHubShards(),
+		logger:      logger,
+		persistence: persistence,
+		broker:      broker,
+		cfg:         cfg,
+		startedAt:   time.Now(),
 
-	if m.persistence != nil {
-		record, err := m.persistence.LoadSnapshot(context.Background(), documentID)
-		if err != nil {
-			m.logger.Warn("failed to load snapshot for document", zap.String("document_id", documentID.String()), zap.Error(err))
-		} else if record != nil {
-			hub.YjsDoc = record.YjsSnapshot
-			hub.Version = record.Version
-			hub.LastUpdated = record.LastModified
-		}
-	}
-
-	m.hubs[documentID] = hub
-	go m.run(hub)
-	return hub
-}
-
-// CloseHub terminates a hub and removes it from manager.
-func (m *HubManager) CloseHub(documentID uuid.UUID) {
-	m.mu.Lock()
-	hub, ok := m.hubs[documentID]
-	if ok {
-		delete(m.hubs, documentID)
+		presenceReleases: newPresenceReleases(),
 	}
-	m.mu.Unlock()
-
-	if ok {
-		close(hub.Done)
+	if persistence != nil {
+		m.updates = persistence
+		m.states = persistence
+		m.presence = persistence
 	}
+	go m.releasePresence()
+	m.subscribePermissions()
+	return m
 }
 
 func (m *HubManager) run(hub *DocumentHub) {
 	ticker := time.NewTicker(persistenceInterval)
 	defer ticker.Stop()
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+	awareness := time.NewTicker(m.cfg.AwarenessInterval)
+	defer awareness.Stop()
+	updates := time.NewTicker(updateFlushInterval)
+	defer updates.Stop()
+	expiry := time.NewTicker(expiryInterval)
+	defer expiry.Stop()
 
 	for {
 		select {
@@ -98,10 +111,31 @@ func (m *HubManager) run(hub *DocumentHub) {
 			m.unregisterClient(hub, client)
 		case message := <-hub.Broadcast:
 			m.broadcastMessage(hub, message)
+		case update := <-hub.Updates:
+			m.applyClientUpdate(hub, update)
+		case client := <-hub.Awareness:
+			m.broadcastAwareness(hub, client)
+		case req := <-hub.SyncStep1:
+			m.answerSyncStep1(hub, req)
+		case message := <-hub.Remote:
+			m.handleRemoteMessage(hub, message)
+		case task := <-hub.Tasks:
+			task.run(hub)
+			close(task.done)
 		case <-ticker.C:
 			m.persistHubState(hub)
+		case <-flush.C:
+			m.flushQueues(hub)
+		case <-awareness.C:
+			m.flushAwareness(hub)
+		case <-updates.C:
+			m.flushUpdates(hub)
+		case <-expiry.C:
+			m.expireClients(hub)
 		case <-hub.Done:
 			m.cleanupHub(hub)
+			close(hub.stopped)
+			m.forgetHub(hub)
 			return
 		}
 	}
@@ -110,69 +144,242 @@ func (m *HubManager) run(hub *DocumentHub) {
 func (m *HubManager) registerClient(hub *DocumentHub, client *ClientConnection) {
 	hub.Clients[client] = true
 	client.LastSeen = time.Now()
+	client.connectedAt = client.LastSeen
 
-	if len(hub.YjsDoc) > 0 {
-		select {
-		case client.Send <- hub.YjsDoc:
-		default:
-			m.logger.Warn(
-				"dropping initial state, client send buffer full",
-				zap.String("document_id", hub.DocumentID.String()),
-				zap.String("user_id", client.UserID.String()),
-			)
-		}
+	// Ask the client for everything the server is missing; the client's own
+	// SyncStep1 is answered in answerSyncStep1.
+	m.deliver(hub, client, encodeSyncMessage(YjsSyncStep1, hub.YjsDoc.EncodeStateVector()))
+
+	role, canEdit := client.access()
+	m.sendControl(hub, client, controlMessage{Type: controlHandshake, Role: role, CanEdit: canEdit, Version: m.persistedVersion(hub)})
+	m.sendAwareness(hub, client)
+}
+
+// answerSyncStep1 replies to a client's state vector with the updates it is missing.
+func (m *HubManager) answerSyncStep1(hub *DocumentHub, req syncRequest) {
+	if _, ok := hub.Clients[req.client]; !ok {
+		return
+	}
+
+	sv, err := yjs.DecodeStateVector(req.stateVector)
+	if err != nil {
+		m.logger.Debug(
+			"ignoring malformed state vector",
+			zap.String("document_id", hub.DocumentID.String()),
+			zap.String("client_id", req.client.ID.String()),
+			zap.Error(err),
+		)
+		return
 	}
+
+	m.catchUp(hub, req)
+	m.deliver(hub, req.client, encodeSyncMessage(YjsSyncStep2, hub.YjsDoc.EncodeStateAsUpdate(sv)))
 }
 
 func (m *HubManager) unregisterClient(hub *DocumentHub, client *ClientConnection) {
 	if _, ok := hub.Clients[client]; ok {
 		delete(hub.Clients, client)
 		close(client.Send)
+		m.removePresence(client)
+		m.removeAwareness(hub, client)
 	}
 
 	if len(hub.Clients) == 0 {
-		m.persistHubState(hub)
+		// cleanupHub saves the state once the hub stops.
 		m.CloseHub(hub.DocumentID)
 	}
 }
 
 func (m *HubManager) broadcastMessage(hub *DocumentHub, message []byte) {
+	if msg, err := decodeMessage(message); err == nil && !m.applyMessage(hub, msg) {
+		return
+	}
+
+	m.publish(hub, message)
+	m.fanOut(hub, message)
+}
+
+// applyMessage merges a document update into the hub state. It reports false
+// when the update could not be decoded and must not be relayed.
+func (m *HubManager) applyMessage(hub *DocumentHub, msg protocolMessage) bool {
 	hub.LastUpdated = time.Now()
+	if !msg.isDocumentUpdate() {
+		return true
+	}
 
-	if len(message) > 0 && message[0] == YjsUpdate {
-		hub.Version++
-		hub.YjsDoc = message
+	if err := hub.YjsDoc.ApplyUpdate(msg.Payload); err != nil {
+		m.logger.Warn(
+			"dropping undecodable document update",
+			zap.String("document_id", hub.DocumentID.String()),
+			zap.Error(err),
+		)
+		return false
 	}
+	hub.Version++
+	hub.stateSize += len(msg.Payload)
+	return true
+}
 
-	for client := range hub.Clients {
-		select {
-		case client.Send <- message:
-		default:
-			// Drop messages to slow clients to keep hub responsive.
-			close(client.Send)
-			delete(hub.Clients, client)
+// runInHub runs fn on the goroutine of a document's hub on this instance and
+// waits for it to return. It reports false when the document has no hub here.
+func (m *HubManager) runInHub(ctx context.Context, documentID uuid.UUID, fn func(hub *DocumentHub)) (bool, error) {
+	hub, ok := m.lookupHub(documentID)
+	if !ok {
+		return false, nil
+	}
+
+	task := hubTask{run: fn, done: make(chan struct{})}
+	select {
+	case hub.Tasks <- task:
+	case <-hub.Done:
+		return false, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+
+	// The hub finishes every accepted task before it looks at Done again.
+	<-task.done
+	return true, nil
+}
+
+// ReplaceText rewrites the text of a document whose hub runs on this instance
+// and relays the resulting update to its clients and peers. It returns the hub
+// version after the change, or false when the document has no hub here. With
+// a base version, the text is only replaced while the hub is still at that
+// version; otherwise it fails with domain.ErrVersionConflict.
+func (m *HubManager) ReplaceText(ctx context.Context, documentID, userID uuid.UUID, text string, baseVersion *int) (int, bool, error) {
+	var (
+		version  int
+		conflict bool
+	)
+	ok, err := m.runInHub(ctx, documentID, func(hub *DocumentHub) {
+		if baseVersion != nil && *baseVersion != hub.Version {
+			conflict = true
+			return
 		}
+		m.replaceText(hub, userID, text)
+		version = hub.Version
+	})
+	if conflict {
+		return 0, true, domain.ErrVersionConflict
 	}
+	return version, ok, err
 }
 
-func (m *HubManager) persistHubState(hub *DocumentHub) {
-	if m.persistence == nil {
+// Version returns the version of a document whose hub runs on this instance,
+// or false when the document has no hub here.
+func (m *HubManager) Version(ctx context.Context, documentID uuid.UUID) (int, bool, error) {
+	var version int
+	ok, err := m.runInHub(ctx, documentID, func(hub *DocumentHub) {
+		version = hub.Version
+	})
+	return version, ok, err
+}
+
+// EncodeState returns the merged state and version of a document whose hub
+// runs on this instance, or false when the document has no hub here.
+func (m *HubManager) EncodeState(ctx context.Context, documentID uuid.UUID) ([]byte, int, bool, error) {
+	var (
+		state   []byte
+		version int
+	)
+	ok, err := m.runInHub(ctx, documentID, func(hub *DocumentHub) {
+		state = hub.YjsDoc.EncodeStateAsUpdate(nil)
+		version = hub.Version
+	})
+	return state, version, ok, err
+}
+
+// RelayUpdate publishes an update that was stored without going through a hub
+// to the hubs of the document on other instances.
+func (m *HubManager) RelayUpdate(documentID uuid.UUID, update []byte) {
+	if m.broker == nil {
+		return
+	}
+	m.broker.Publish(documentID, encodeSyncMessage(YjsUpdate, update))
+}
+
+func (m *HubManager) replaceText(hub *DocumentHub, userID uuid.UUID, text string) {
+	update := hub.YjsDoc.ReplaceText(domain.DocumentTextName, text, yjs.NewClientID())
+	if update == nil {
 		return
 	}
 
-	if len(hub.YjsDoc) == 0 {
+	msg := protocolMessage{Type: MessageTypeSync, Step: YjsUpdate, Payload: update}
+	if !m.applyMessage(hub, msg) {
 		return
 	}
 
-	err := m.persistence.SaveSnapshot(context.Background(), hub.DocumentID, hub.YjsDoc, hub.Version, uuid.Nil)
+	m.queueUpdate(hub, userID, update)
+	m.persistHubState(hub)
+
+	message := encodeSyncMessage(YjsUpdate, update)
+	m.publish(hub, message)
+	m.fanOut(hub, message)
+}
+
+func (m *HubManager) fanOut(hub *DocumentHub, message []byte) {
+	for client := range hub.Clients {
+		m.deliver(hub, client, message)
+	}
+}
+
+func (m *HubManager) persistHubState(hub *DocumentHub) {
+	if err := m.saveHub(hub); err != nil {
+		m.logger.Warn("failed to persist hub state", zap.String("document_id", hub.DocumentID.String()), zap.Error(err))
+	}
+}
+
+// saveHub writes the snapshot of the hub state and its Markdown text.
+func (m *HubManager) saveHub(hub *DocumentHub) error {
+	if m.persistence == nil || hub.deleted {
+		return nil
+	}
+
+	if hub.YjsDoc.IsEmpty() {
+		return nil
+	}
+
+	snapshot := hub.YjsDoc.EncodeStateAsUpdate(nil)
+	err := m.persistence.SaveSnapshot(context.Background(), hub.DocumentID, snapshot, m.persistedVersion(hub), uuid.Nil)
 	if err != nil {
-		m.logger.Warn("failed to persist snapshot", zap.String("document_id", hub.DocumentID.String()), zap.Error(err))
+		m.countPersistFailure(hub)
+		return fmt.Errorf("save snapshot: %w", err)
 	}
+	hub.snapshotSize = len(snapshot)
+	hub.stateSize = len(snapshot)
+
+	return m.persistContent(hub)
+}
+
+// persistContent materializes the Markdown text of the hub state into
+// documents.content when it changed since the last write.
+func (m *HubManager) persistContent(hub *DocumentHub) error {
+	content := hub.YjsDoc.Text(domain.DocumentTextName)
+	if content == hub.savedContent {
+		return nil
+	}
+
+	if err := m.persistence.SaveContent(context.Background(), hub.DocumentID, content); err != nil {
+		m.countPersistFailure(hub)
+		return fmt.Errorf("save content: %w", err)
+	}
+	hub.savedContent = content
+	return nil
 }
 
 func (m *HubManager) cleanupHub(hub *DocumentHub) {
+	m.stopWriter(hub)
+	m.persistHubState(hub)
+	m.unsubscribeHub(hub)
+	closeMessage := hub.closeMessage
+	if m.isClosing() {
+		closeMessage = restartCloseMessage
+	}
 	for client := range hub.Clients {
+		client.closeMessage = closeMessage
 		close(client.Send)
 		delete(hub.Clients, client)
+		m.removePresence(client)
 	}
 }
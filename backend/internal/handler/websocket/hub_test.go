@@ -0,0 +1,409 @@
+package websocket
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+	"go.uber.org/zap"
+)
+
+// textInsert encodes a Yjs update inserting text at the start of the "content" root type.
+func textInsert(client uint64, text string) []byte {
+	enc := yjs.NewEncoder()
+	enc.WriteVarUint(1)
+	enc.WriteVarUint(1)
+	enc.WriteVarUint(client)
+	enc.WriteVarUint(0)
+	enc.WriteUint8(4)
+	enc.WriteVarUint(1)
+	enc.WriteVarString("content")
+	enc.WriteVarString(text)
+	enc.WriteVarUint(0)
+	return enc.Bytes()
+}
+
+func newTestClient(documentID uuid.UUID) *ClientConnection {
+	return &ClientConnection{
+		ID:         uuid.New(),
+		UserID:     uuid.New(),
+		DocumentID: documentID,
+		Send:       make(chan []byte, initialSendBufferSize),
+		Done:       make(chan struct{}),
+		CanEdit:    true,
+	}
+}
+
+func openHub(t *testing.T, manager *HubManager, documentID uuid.UUID) *DocumentHub {
+	t.Helper()
+	hub, err := manager.GetOrCreateHub(context.Background(), documentID)
+	require.NoError(t, err)
+	return hub
+}
+
+func receive(t *testing.T, client *ClientConnection) protocolMessage {
+	t.Helper()
+	select {
+	case message := <-client.Send:
+		msg, err := decodeMessage(message)
+		require.NoError(t, err)
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for message")
+		return protocolMessage{}
+	}
+}
+
+// register joins client to hub and returns the SyncStep1 it is sent, after
+// consuming the handshake that follows it.
+func register(t *testing.T, hub *DocumentHub, client *ClientConnection) protocolMessage {
+	t.Helper()
+	hub.Register <- client
+	step1 := receive(t, client)
+	require.Equal(t, controlHandshake, receiveControl(t, client).Type)
+	return step1
+}
+
+func TestHandleClientMessageWaitsForBusyHub(t *testing.T) {
+	manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+	documentID := uuid.New()
+	hub := &DocumentHub{
+		DocumentID: documentID,
+		SyncStep1:  make(chan syncRequest, 1),
+		Done:       make(chan struct{}),
+	}
+	hub.SyncStep1 <- syncRequest{}
+
+	client := newTestClient(documentID)
+	message := encodeSyncMessage(YjsSyncStep1, yjs.StateVector{7: 5}.Encode())
+	handled := make(chan error, 1)
+	go func() {
+		handled <- handleClientMessage(context.Background(), manager, hub, client, zap.NewNop(), message)
+	}()
+
+	select {
+	case <-handled:
+		t.Fatal("sync step 1 was not queued behind the busy hub")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	<-hub.SyncStep1
+	require.NoError(t, <-handled)
+	req := <-hub.SyncStep1
+	assert.Equal(t, client, req.client)
+	assert.Equal(t, yjs.StateVector{7: 5}.Encode(), req.stateVector)
+
+	t.Run("StopsWaitingWhenRequestEnds", func(t *testing.T) {
+		hub.SyncStep1 <- syncRequest{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.NoError(t, handleClientMessage(ctx, manager, hub, client, zap.NewNop(), message))
+	})
+}
+
+func TestHubSyncHandshake(t *testing.T) {
+	manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+	documentID := uuid.New()
+	hub := openHub(t, manager, documentID)
+	t.Cleanup(func() { manager.CloseHub(documentID) })
+
+	writer := newTestClient(documentID)
+	hub.Register <- writer
+	step1 := receive(t, writer)
+	assert.Equal(t, uint64(YjsSyncStep1), step1.Step)
+	assert.Equal(t, yjs.StateVector{}.Encode(), step1.Payload)
+	handshake := receiveControl(t, writer)
+	assert.Equal(t, controlMessage{Type: controlHandshake, CanEdit: true}, handshake)
+
+	hub.Broadcast <- encodeSyncMessage(YjsUpdate, textInsert(7, "hello"))
+	update := receive(t, writer)
+	assert.Equal(t, uint64(YjsUpdate), update.Step)
+
+	t.Run("AnswersEmptyStateVectorWithFullState", func(t *testing.T) {
+		reader := newTestClient(documentID)
+		register(t, hub, reader)
+
+		hub.SyncStep1 <- syncRequest{client: reader, stateVector: yjs.StateVector{}.Encode()}
+		step2 := receive(t, reader)
+		assert.Equal(t, uint64(YjsSyncStep2), step2.Step)
+		assert.Equal(t, textInsert(7, "hello"), step2.Payload)
+	})
+
+	t.Run("AnswersUpToDateStateVectorWithEmptyDiff", func(t *testing.T) {
+		reader := newTestClient(documentID)
+		step1 := register(t, hub, reader)
+
+		hub.SyncStep1 <- syncRequest{client: reader, stateVector: step1.Payload}
+		step2 := receive(t, reader)
+		assert.Equal(t, uint64(YjsSyncStep2), step2.Step)
+		assert.Equal(t, []byte{0, 0}, step2.Payload)
+	})
+
+	t.Run("HandshakeCarriesRole", func(t *testing.T) {
+		reader := newTestClient(documentID)
+		reader.setAccess(domain.RoleViewer, false)
+		hub.Register <- reader
+		receive(t, reader)
+
+		handshake := receiveControl(t, reader)
+		assert.Equal(t, controlMessage{Type: controlHandshake, Role: domain.RoleViewer}, handshake)
+	})
+}
+
+func TestHubCatchUp(t *testing.T) {
+	manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+	documentID := uuid.New()
+	hub := openHub(t, manager, documentID)
+	t.Cleanup(func() { manager.CloseHub(documentID) })
+
+	watcher := newTestClient(documentID)
+	register(t, hub, watcher)
+	hub.Broadcast <- encodeSyncMessage(YjsUpdate, textInsert(7, "seen"))
+	receive(t, watcher)
+
+	reconnecting := newTestClient(documentID)
+	step1 := register(t, hub, reconnecting)
+
+	// Arrange: one persisted update the hub already merged, and one that was
+	// stored without ever reaching it.
+	dropped := textInsert(8, "dropped")
+	req := syncRequest{
+		client:      reconnecting,
+		stateVector: step1.Payload,
+		updates: []repo.UpdateRecord{
+			{ID: 1, YjsUpdate: textInsert(7, "seen"), Version: defaultHubVersion + 1},
+			{ID: 2, YjsUpdate: dropped, Version: defaultHubVersion + 2},
+		},
+	}
+
+	// Act
+	hub.SyncStep1 <- req
+
+	// Assert: the requester gets exactly what it is missing
+	relayed := receive(t, reconnecting)
+	assert.Equal(t, uint64(YjsUpdate), relayed.Step)
+	step2 := receive(t, reconnecting)
+	assert.Equal(t, uint64(YjsSyncStep2), step2.Step)
+	assert.Equal(t, dropped, step2.Payload)
+
+	// and the other clients learn about the dropped update too
+	update := receive(t, watcher)
+	assert.Equal(t, dropped, update.Payload)
+
+	_, version, ok, err := manager.EncodeState(context.Background(), documentID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, defaultHubVersion+2, version)
+}
+
+type recordingBroker struct {
+	mu        sync.Mutex
+	deliver   func(message []byte)
+	resync    func()
+	published [][]byte
+}
+
+func (b *recordingBroker) Subscribe(_ uuid.UUID, deliver func(message []byte), resync func()) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deliver = deliver
+	b.resync = resync
+	return 1, nil
+}
+
+func (b *recordingBroker) Unsubscribe(uuid.UUID, uint64) error { return nil }
+
+func (b *recordingBroker) Publish(_ uuid.UUID, message []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = append(b.published, message)
+}
+
+func (b *recordingBroker) messages() [][]byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([][]byte(nil), b.published...)
+}
+
+func TestHubBrokerRelay(t *testing.T) {
+	broker := &recordingBroker{}
+	manager := NewHubManager(zap.NewNop(), nil, broker, Config{})
+	documentID := uuid.New()
+	hub := openHub(t, manager, documentID)
+	t.Cleanup(func() { manager.CloseHub(documentID) })
+
+	client := newTestClient(documentID)
+	register(t, hub, client)
+
+	// Arrange: a new hub announces its state vector to its peers
+	require.Len(t, broker.messages(), 1)
+	announce, err := decodeMessage(broker.messages()[0])
+	require.NoError(t, err)
+	assert.Equal(t, uint64(YjsSyncStep1), announce.Step)
+
+	t.Run("PublishesLocalUpdates", func(t *testing.T) {
+		// Act
+		local := encodeSyncMessage(YjsUpdate, textInsert(7, "local"))
+		hub.Broadcast <- local
+		receive(t, client)
+
+		// Assert
+		assert.Equal(t, local, broker.messages()[len(broker.messages())-1])
+	})
+
+	t.Run("AppliesRemoteUpdatesWithoutRepublishing", func(t *testing.T) {
+		// Arrange
+		published := len(broker.messages())
+
+		// Act
+		broker.deliver(encodeSyncMessage(YjsUpdate, textInsert(8, "remote")))
+		update := receive(t, client)
+
+		// Assert
+		assert.Equal(t, textInsert(8, "remote"), update.Payload)
+		assert.Len(t, broker.messages(), published)
+	})
+
+	t.Run("AnswersRemoteSyncStep1", func(t *testing.T) {
+		// Act
+		broker.deliver(encodeSyncMessage(YjsSyncStep1, yjs.StateVector{7: 5}.Encode()))
+
+		// Assert
+		require.Eventually(t, func() bool {
+			messages := broker.messages()
+			msg, err := decodeMessage(messages[len(messages)-1])
+			return err == nil && msg.Step == YjsSyncStep2
+		}, time.Second, 10*time.Millisecond)
+		messages := broker.messages()
+		step2, err := decodeMessage(messages[len(messages)-1])
+		require.NoError(t, err)
+		assert.Equal(t, textInsert(8, "remote"), step2.Payload)
+	})
+
+	t.Run("ResyncExchangesStateVectorsWithPeers", func(t *testing.T) {
+		// Arrange
+		published := len(broker.messages())
+
+		// Act
+		broker.resync()
+
+		// Assert: peers are asked for their state vectors and get ours
+		require.Eventually(t, func() bool {
+			return len(broker.messages()) >= published+2
+		}, time.Second, 10*time.Millisecond)
+		messages := broker.messages()[published:]
+		assert.Contains(t, messages, encodeSyncMessage(YjsSyncStep1, nil))
+		assert.Contains(t, messages, encodeSyncMessage(YjsSyncStep1, hub.YjsDoc.EncodeStateVector()))
+	})
+}
+
+func TestHubReplaceText(t *testing.T) {
+	manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+	documentID := uuid.New()
+
+	t.Run("ReportsMissingHub", func(t *testing.T) {
+		_, ok, err := manager.ReplaceText(context.Background(), documentID, uuid.New(), "text", nil)
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	hub := openHub(t, manager, documentID)
+	t.Cleanup(func() { manager.CloseHub(documentID) })
+
+	client := newTestClient(documentID)
+	register(t, hub, client)
+	hub.Broadcast <- encodeSyncMessage(YjsUpdate, textInsert(7, "hello world"))
+	receive(t, client)
+
+	t.Run("RelaysUpdateToClients", func(t *testing.T) {
+		// Act
+		version, ok, err := manager.ReplaceText(context.Background(), documentID, uuid.New(), "hello", nil)
+
+		// Assert
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, defaultHubVersion+2, version)
+
+		update := receive(t, client)
+		assert.Equal(t, uint64(YjsUpdate), update.Step)
+		doc := yjs.NewDoc()
+		require.NoError(t, doc.ApplyUpdate(textInsert(7, "hello world")))
+		require.NoError(t, doc.ApplyUpdate(update.Payload))
+		assert.Equal(t, "hello", doc.Text("content"))
+	})
+
+	t.Run("KeepsVersionWhenTextIsUnchanged", func(t *testing.T) {
+		version, ok, err := manager.ReplaceText(context.Background(), documentID, uuid.New(), "hello", nil)
+
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, defaultHubVersion+2, version)
+	})
+
+	t.Run("RefusesStaleBaseVersion", func(t *testing.T) {
+		// Arrange: a REST writer read the text, then a live editor changed it
+		base, ok, err := manager.Version(context.Background(), documentID)
+		require.NoError(t, err)
+		require.True(t, ok)
+		hub.Updates <- clientUpdate{client: client, message: encodeSyncMessage(YjsUpdate, textInsert(9, "live "))}
+		receive(t, client)
+
+		// Act
+		_, _, staleErr := manager.ReplaceText(context.Background(), documentID, uuid.New(), "hello there", &base)
+		current, _, err := manager.Version(context.Background(), documentID)
+		require.NoError(t, err)
+		version, _, currentErr := manager.ReplaceText(context.Background(), documentID, uuid.New(), "live hello there", &current)
+
+		// Assert
+		assert.ErrorIs(t, staleErr, domain.ErrVersionConflict)
+		require.NoError(t, currentErr)
+		assert.Equal(t, current+1, version)
+
+		state, _, _, err := manager.EncodeState(context.Background(), documentID)
+		require.NoError(t, err)
+		doc, err := yjs.NewDocFromUpdate(state)
+		require.NoError(t, err)
+		assert.Equal(t, "live hello there", doc.Text("content"))
+	})
+}
+
+func TestHubEncodeState(t *testing.T) {
+	manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+	documentID := uuid.New()
+	hub := openHub(t, manager, documentID)
+	t.Cleanup(func() { manager.CloseHub(documentID) })
+
+	client := newTestClient(documentID)
+	register(t, hub, client)
+	hub.Broadcast <- encodeSyncMessage(YjsUpdate, textInsert(7, "hello"))
+	receive(t, client)
+
+	state, version, ok, err := manager.EncodeState(context.Background(), documentID)
+
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, textInsert(7, "hello"), state)
+	assert.Equal(t, defaultHubVersion+1, version)
+}
+
+func TestHubRelayUpdate(t *testing.T) {
+	broker := &recordingBroker{}
+	manager := NewHubManager(zap.NewNop(), nil, broker, Config{})
+
+	manager.RelayUpdate(uuid.New(), textInsert(7, "stored"))
+
+	require.Len(t, broker.messages(), 1)
+	msg, err := decodeMessage(broker.messages()[0])
+	require.NoError(t, err)
+	assert.Equal(t, uint64(YjsUpdate), msg.Step)
+	assert.Equal(t, textInsert(7, "stored"), msg.Payload)
+}
@@ -0,0 +1,271 @@
+package websocket
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+)
+
+// messageKind groups websocket frames for the traffic counters.
+type messageKind int
+
+const (
+	kindSync messageKind = iota
+	kindUpdate
+	kindAwareness
+	kindControl
+	messageKinds
+)
+
+// messageKindNames are the keys of the message kinds in TrafficStats.
+var messageKindNames = [messageKinds]string{"sync", "update", "awareness", "control"}
+
+// kindOf classifies a frame by its header alone. SyncStep2 counts as an
+// update, as it carries document content.
+func kindOf(message []byte) messageKind {
+	dec := yjs.NewDecoder(message)
+	msgType, err := dec.ReadVarUint()
+	if err != nil {
+		return kindSync
+	}
+	switch msgType {
+	case MessageTypeAwareness:
+		return kindAwareness
+	case MessageTypeControl:
+		return kindControl
+	}
+	if step, err := dec.ReadVarUint(); err == nil && step != YjsSyncStep1 {
+		return kindUpdate
+	}
+	return kindSync
+}
+
+// trafficCounters count the frames and bytes of a hub, or of all hubs.
+type trafficCounters struct {
+	received        [messageKinds]atomic.Int64
+	sent            [messageKinds]atomic.Int64
+	bytesIn         atomic.Int64
+	bytesOut        atomic.Int64
+	persistFailures atomic.Int64
+	rejected        atomic.Int64
+}
+
+// MessageStats counts the frames of one kind.
+type MessageStats struct {
+	Count int64 `json:"count"`
+	// PerSecond is the average rate since the hub, or the manager, started.
+	PerSecond float64 `json:"per_second"`
+}
+
+// TrafficStats summarizes the websocket traffic of a hub or of all hubs.
+type TrafficStats struct {
+	Received map[string]MessageStats `json:"received"`
+	Sent     map[string]MessageStats `json:"sent"`
+	BytesIn  int64                   `json:"bytes_in"`
+	BytesOut int64                   `json:"bytes_out"`
+	// PersistenceFailures counts failed snapshot, content and update writes.
+	PersistenceFailures int64 `json:"persistence_failures"`
+	// Rejected counts malformed frames and updates refused for outgrowing the
+	// document size limit.
+	Rejected int64 `json:"rejected"`
+}
+
+func (c *trafficCounters) stats(since time.Time) TrafficStats {
+	seconds := time.Since(since).Seconds()
+	rate := func(count int64) float64 {
+		if seconds <= 0 {
+			return 0
+		}
+		return float64(count) / seconds
+	}
+
+	stats := TrafficStats{
+		Received:            make(map[string]MessageStats, messageKinds),
+		Sent:                make(map[string]MessageStats, messageKinds),
+		BytesIn:             c.bytesIn.Load(),
+		BytesOut:            c.bytesOut.Load(),
+		PersistenceFailures: c.persistFailures.Load(),
+		Rejected:            c.rejected.Load(),
+	}
+	for kind, name := range messageKindNames {
+		received, sent := c.received[kind].Load(), c.sent[kind].Load()
+		stats.Received[name] = MessageStats{Count: received, PerSecond: rate(received)}
+		stats.Sent[name] = MessageStats{Count: sent, PerSecond: rate(sent)}
+	}
+	return stats
+}
+
+// countReceived records a frame read from a client of hub.
+func (m *HubManager) countReceived(hub *DocumentHub, message []byte) {
+	kind := kindOf(message)
+	for _, counters := range []*trafficCounters{&m.traffic, &hub.traffic} {
+		counters.received[kind].Add(1)
+		counters.bytesIn.Add(int64(len(message)))
+	}
+}
+
+// countSent records a frame written to a client of hub.
+func (m *HubManager) countSent(hub *DocumentHub, message []byte) {
+	kind := kindOf(message)
+	for _, counters := range []*trafficCounters{&m.traffic, &hub.traffic} {
+		counters.sent[kind].Add(1)
+		counters.bytesOut.Add(int64(len(message)))
+	}
+}
+
+func (m *HubManager) countPersistFailure(hub *DocumentHub) {
+	m.traffic.persistFailures.Add(1)
+	hub.traffic.persistFailures.Add(1)
+}
+
+func (m *HubManager) countRejected(hub *DocumentHub) {
+	m.traffic.rejected.Add(1)
+	hub.traffic.rejected.Add(1)
+}
+
+// ClientStats describes a client connected to a hub.
+type ClientStats struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	UserName    string    `json:"user_name"`
+	Role        string    `json:"role"`
+	CanEdit     bool      `json:"can_edit"`
+	Guest       bool      `json:"guest"`
+	ConnectedAt time.Time `json:"connected_at"`
+	// Queued is how many messages wait for the client's send buffer.
+	Queued int `json:"queued"`
+}
+
+// HubStats describes a hub open on this instance.
+type HubStats struct {
+	DocumentID  uuid.UUID     `json:"document_id"`
+	Version     int           `json:"version"`
+	OpenedAt    time.Time     `json:"opened_at"`
+	LastUpdated time.Time     `json:"last_updated"`
+	Clients     []ClientStats `json:"clients"`
+	// StateSize is the size of the encoded document state.
+	StateSize int `json:"state_size"`
+	// SnapshotSize is the size of the snapshot last saved by the hub; zero
+	// until it saved one.
+	SnapshotSize int          `json:"snapshot_size"`
+	Traffic      TrafficStats `json:"traffic"`
+}
+
+// Stats summarizes all hubs on this instance.
+type Stats struct {
+	Hubs     int           `json:"hubs"`
+	Clients  int           `json:"clients"`
+	Traffic  TrafficStats  `json:"traffic"`
+	Delivery DeliveryStats `json:"delivery"`
+}
+
+// Stats returns the totals of this instance since the manager started.
+func (m *HubManager) Stats(ctx context.Context) (Stats, error) {
+	hubs, err := m.Hubs(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	stats := Stats{
+		Hubs:     len(hubs),
+		Traffic:  m.traffic.stats(m.startedAt),
+		Delivery: m.DeliveryStats(),
+	}
+	for _, hub := range hubs {
+		stats.Clients += len(hub.Clients)
+	}
+	return stats, nil
+}
+
+// Hubs describes every hub open on this instance, ordered by document.
+func (m *HubManager) Hubs(ctx context.Context) ([]HubStats, error) {
+	hubs := make([]HubStats, 0)
+	for _, open := range m.openHubs() {
+		stats, ok, err := m.Hub(ctx, open.DocumentID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			hubs = append(hubs, stats)
+		}
+	}
+	slices.SortFunc(hubs, func(a, b HubStats) int {
+		return slices.Compare(a.DocumentID[:], b.DocumentID[:])
+	})
+	return hubs, nil
+}
+
+// Hub describes the hub of a document. It reports false when the
+// document has no hub on this instance.
+func (m *HubManager) Hub(ctx context.Context, documentID uuid.UUID) (HubStats, bool, error) {
+	var stats HubStats
+	ok, err := m.runInHub(ctx, documentID, func(hub *DocumentHub) {
+		stats = HubStats{
+			DocumentID:   hub.DocumentID,
+			Version:      hub.Version,
+			OpenedAt:     hub.openedAt,
+			LastUpdated:  hub.LastUpdated,
+			Clients:      make([]ClientStats, 0, len(hub.Clients)),
+			StateSize:    len(hub.YjsDoc.EncodeStateAsUpdate(nil)),
+			SnapshotSize: hub.snapshotSize,
+			Traffic:      hub.traffic.stats(hub.openedAt),
+		}
+		for client := range hub.Clients {
+			role, canEdit := client.access()
+			stats.Clients = append(stats.Clients, ClientStats{
+				ID:          client.ID,
+				UserID:      client.UserID,
+				UserName:    client.UserName,
+				Role:        role,
+				CanEdit:     canEdit,
+				Guest:       client.Guest,
+				ConnectedAt: client.connectedAt,
+				Queued:      len(client.outbox.control) + len(client.outbox.messages),
+			})
+		}
+		slices.SortFunc(stats.Clients, func(a, b ClientStats) int {
+			return a.ConnectedAt.Compare(b.ConnectedAt)
+		})
+	})
+	return stats, ok, err
+}
+
+// PersistHub saves the state of a document's hub right away, instead of at the
+// next persistence tick. It reports false when the document has no hub on this
+// instance.
+func (m *HubManager) PersistHub(ctx context.Context, documentID uuid.UUID) (bool, error) {
+	var err error
+	ok, runErr := m.runInHub(ctx, documentID, func(hub *DocumentHub) {
+		m.flushUpdates(hub)
+		err = m.saveHub(hub)
+	})
+	if runErr != nil {
+		return false, runErr
+	}
+	return ok, err
+}
+
+// ForceCloseHub saves and closes the hub of a document and asks its clients to
+// reconnect. It reports false when the document has no hub on this instance.
+func (m *HubManager) ForceCloseHub(ctx context.Context, documentID uuid.UUID) (bool, error) {
+	var closing *DocumentHub
+	ok, err := m.runInHub(ctx, documentID, func(hub *DocumentHub) {
+		hub.closeMessage = restartCloseMessage
+		closing = hub
+	})
+	if !ok || err != nil {
+		return false, err
+	}
+
+	m.closeHub(closing)
+	select {
+	case <-closing.stopped:
+		return true, nil
+	case <-ctx.Done():
+		return true, ctx.Err()
+	}
+}
@@ -0,0 +1,102 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"go.uber.org/zap"
+)
+
+func TestHubManagerStats(t *testing.T) {
+	setup := func(t *testing.T) (*HubManager, *DocumentHub, *ClientConnection) {
+		manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+		documentID := uuid.New()
+		hub := openHub(t, manager, documentID)
+		t.Cleanup(func() { manager.CloseHub(documentID) })
+
+		client := newTestClient(documentID)
+		client.UserName = "alice"
+		client.Role = domain.RoleEditor
+		register(t, hub, client)
+		return manager, hub, client
+	}
+
+	t.Run("DescribesHubsAndTraffic", func(t *testing.T) {
+		// Arrange
+		manager, hub, client := setup(t)
+		update := encodeSyncMessage(YjsUpdate, textInsert(7, "hello"))
+		step1 := encodeSyncMessage(YjsSyncStep1, []byte{0})
+		manager.countReceived(hub, update)
+		manager.countReceived(hub, step1)
+		manager.countSent(hub, update)
+
+		// Act
+		stats, ok, err := manager.Hub(context.Background(), hub.DocumentID)
+
+		// Assert
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, hub.DocumentID, stats.DocumentID)
+		require.Len(t, stats.Clients, 1)
+		assert.Equal(t, client.UserID, stats.Clients[0].UserID)
+		assert.Equal(t, "alice", stats.Clients[0].UserName)
+		assert.Equal(t, domain.RoleEditor, stats.Clients[0].Role)
+		assert.EqualValues(t, 1, stats.Traffic.Received["update"].Count)
+		assert.EqualValues(t, 1, stats.Traffic.Received["sync"].Count)
+		assert.EqualValues(t, 1, stats.Traffic.Sent["update"].Count)
+		assert.EqualValues(t, len(update), stats.Traffic.BytesOut)
+
+		summary, err := manager.Stats(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, summary.Hubs)
+		assert.Equal(t, 1, summary.Clients)
+		assert.EqualValues(t, len(update)+len(step1), summary.Traffic.BytesIn)
+	})
+
+	t.Run("ReportsMissingHub", func(t *testing.T) {
+		manager, _, _ := setup(t)
+
+		_, ok, err := manager.Hub(context.Background(), uuid.New())
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = manager.PersistHub(context.Background(), uuid.New())
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		ok, err = manager.ForceCloseHub(context.Background(), uuid.New())
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("ForceCloseAsksClientsToReconnect", func(t *testing.T) {
+		// Arrange
+		store := &recordingStore{}
+		manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+		manager.updates = store
+		documentID := uuid.New()
+		hub := openHub(t, manager, documentID)
+
+		client := newTestClient(documentID)
+		register(t, hub, client)
+		hub.Updates <- clientUpdate{client: client, message: encodeSyncMessage(YjsUpdate, textInsert(7, "unsaved"))}
+		receive(t, client)
+
+		// Act
+		ok, err := manager.ForceCloseHub(context.Background(), documentID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Len(t, store.saved(), 1)
+		_, open := <-client.Send
+		assert.False(t, open)
+		assert.Equal(t, restartCloseMessage, client.closeMessage)
+		_, ok = manager.lookupHub(documentID)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,123 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"go.uber.org/zap"
+)
+
+// permissionTopic is the broker topic on which instances share permission
+// changes. No document uses the nil UUID.
+var permissionTopic = uuid.Nil
+
+type permissionEvent struct {
+	GroupID     uuid.UUID `json:"group_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Role        string    `json:"role"`
+	ShareLinkID uuid.UUID `json:"share_link_id"`
+}
+
+// matches reports whether the event is about the access of client.
+func (e permissionEvent) matches(client *ClientConnection) bool {
+	if e.ShareLinkID != uuid.Nil {
+		return client.Guest && client.ShareLinkID == e.ShareLinkID
+	}
+	return !client.Guest && client.UserID == e.UserID && client.GroupID == e.GroupID
+}
+
+// NotifyPermissionChange applies a role change to the open sessions of the user
+// on this instance and relays it to the other instances.
+func (m *HubManager) NotifyPermissionChange(ctx context.Context, change domain.PermissionChange) {
+	event := permissionEvent{
+		GroupID:     change.GroupUUID,
+		UserID:      change.UserUUID,
+		Role:        change.Role,
+		ShareLinkID: change.ShareLinkUUID,
+	}
+	m.applyPermissionChange(ctx, event)
+
+	if m.broker == nil {
+		return
+	}
+	message, err := json.Marshal(event)
+	if err != nil {
+		m.logger.Warn("failed to encode permission change", zap.Error(err))
+		return
+	}
+	m.broker.Publish(permissionTopic, message)
+}
+
+// subscribePermissions listens for permission changes made on other instances.
+func (m *HubManager) subscribePermissions() {
+	if m.broker == nil {
+		return
+	}
+
+	deliver := func(message []byte) {
+		var event permissionEvent
+		if err := json.Unmarshal(message, &event); err != nil {
+			m.logger.Debug("ignoring malformed permission change", zap.Error(err))
+			return
+		}
+		// Hub tasks must not run on the broker's receive goroutine.
+		go m.applyPermissionChange(context.Background(), event)
+	}
+	if _, err := m.broker.Subscribe(permissionTopic, deliver, func() {}); err != nil {
+		m.logger.Warn("failed to subscribe to permission changes", zap.Error(err))
+	}
+}
+
+func (m *HubManager) applyPermissionChange(ctx context.Context, event permissionEvent) {
+	for _, open := range m.openHubs() {
+		if _, err := m.runInHub(ctx, open.DocumentID, func(hub *DocumentHub) {
+			m.updateClientAccess(hub, event)
+		}); err != nil {
+			m.logger.Warn("failed to apply permission change", zap.String("document_id", open.DocumentID.String()), zap.Error(err))
+			continue
+		}
+	}
+}
+
+// updateClientAccess downgrades or upgrades the clients the event is about,
+// or disconnects them when they lost access to the document.
+func (m *HubManager) updateClientAccess(hub *DocumentHub, event permissionEvent) {
+	for client := range hub.Clients {
+		if !event.matches(client) {
+			continue
+		}
+
+		if event.Role == "" {
+			m.revokeClient(hub, client)
+			continue
+		}
+
+		canEdit := event.Role != domain.RoleViewer
+		client.setAccess(event.Role, canEdit)
+		m.sendControl(hub, client, controlMessage{Type: controlPermissionChanged, Role: event.Role, CanEdit: canEdit})
+	}
+}
+
+// revokeClient tells a client that it lost access to the document and
+// disconnects it.
+func (m *HubManager) revokeClient(hub *DocumentHub, client *ClientConnection) {
+	// Updates still in flight from the read goroutine must be refused too.
+	client.setAccess("", false)
+	delete(hub.Clients, client)
+	m.disconnect(hub, client, controlMessage{Type: controlAccessRevoked})
+	m.removePresence(client)
+	m.removeAwareness(hub, client)
+}
+
+// expireClients disconnects the clients whose access has expired.
+func (m *HubManager) expireClients(hub *DocumentHub) {
+	now := time.Now()
+	for client := range hub.Clients {
+		if !client.ExpiresAt.IsZero() && now.After(client.ExpiresAt) {
+			m.revokeClient(hub, client)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"go.uber.org/zap"
+)
+
+func receiveControl(t *testing.T, client *ClientConnection) controlMessage {
+	t.Helper()
+	msg := receive(t, client)
+	require.EqualValues(t, MessageTypeControl, msg.Type)
+
+	var control controlMessage
+	require.NoError(t, json.Unmarshal(msg.Payload, &control))
+	return control
+}
+
+// fillSend fills the send buffer of a client, as for a client that stopped
+// reading.
+func fillSend(client *ClientConnection) {
+	for len(client.Send) < cap(client.Send) {
+		client.Send <- encodeSyncMessage(YjsUpdate, textInsert(1, "x"))
+	}
+}
+
+// finalControl drains the send buffer of a client until it is closed and
+// returns the final notice left for the write goroutine.
+func finalControl(t *testing.T, client *ClientConnection) controlMessage {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for open := true; open; {
+		select {
+		case _, open = <-client.Send:
+		case <-timeout:
+			t.Fatal("send buffer was not closed")
+		}
+	}
+	require.NotEmpty(t, client.finalMessages)
+
+	msg, err := decodeMessage(client.finalMessages[len(client.finalMessages)-1])
+	require.NoError(t, err)
+	require.EqualValues(t, MessageTypeControl, msg.Type)
+	var control controlMessage
+	require.NoError(t, json.Unmarshal(msg.Payload, &control))
+	return control
+}
+
+func TestHubPermissionChange(t *testing.T) {
+	setup := func(t *testing.T) (*HubManager, *recordingBroker, *ClientConnection, *ClientConnection) {
+		broker := &recordingBroker{}
+		manager := NewHubManager(zap.NewNop(), nil, broker, Config{})
+		documentID := uuid.New()
+		hub := openHub(t, manager, documentID)
+		t.Cleanup(func() { manager.CloseHub(documentID) })
+
+		groupID := uuid.New()
+		target := newTestClient(documentID)
+		target.GroupID = groupID
+		target.Role = domain.RoleEditor
+		other := newTestClient(documentID)
+		other.GroupID = groupID
+		for _, client := range []*ClientConnection{target, other} {
+			register(t, hub, client)
+		}
+		return manager, broker, target, other
+	}
+
+	t.Run("DowngradesToViewer", func(t *testing.T) {
+		// Arrange
+		manager, broker, target, other := setup(t)
+		published := len(broker.messages())
+
+		// Act
+		manager.NotifyPermissionChange(context.Background(), domain.PermissionChange{
+			GroupUUID: target.GroupID,
+			UserUUID:  target.UserID,
+			Role:      domain.RoleViewer,
+		})
+
+		// Assert
+		control := receiveControl(t, target)
+		assert.Equal(t, controlMessage{Type: controlPermissionChanged, Role: domain.RoleViewer}, control)
+		role, canEdit := target.access()
+		assert.Equal(t, domain.RoleViewer, role)
+		assert.False(t, canEdit)
+
+		_, canEdit = other.access()
+		assert.True(t, canEdit)
+		assert.Empty(t, other.Send)
+
+		require.Len(t, broker.messages(), published+1)
+		var event permissionEvent
+		require.NoError(t, json.Unmarshal(broker.messages()[published], &event))
+		assert.Equal(t, permissionEvent{GroupID: target.GroupID, UserID: target.UserID, Role: domain.RoleViewer}, event)
+	})
+
+	t.Run("DisconnectsRemovedMember", func(t *testing.T) {
+		// Arrange
+		manager, _, target, other := setup(t)
+
+		// Act
+		manager.NotifyPermissionChange(context.Background(), domain.PermissionChange{
+			GroupUUID: target.GroupID,
+			UserUUID:  target.UserID,
+		})
+
+		// Assert
+		assert.Equal(t, controlMessage{Type: controlAccessRevoked}, receiveControl(t, target))
+		_, open := <-target.Send
+		assert.False(t, open)
+		_, canEdit := target.access()
+		assert.False(t, canEdit)
+		assert.Empty(t, other.Send)
+	})
+
+	t.Run("KeepsRevocationForFullSendBuffer", func(t *testing.T) {
+		// Arrange
+		manager, _, target, _ := setup(t)
+		fillSend(target)
+
+		// Act
+		manager.NotifyPermissionChange(context.Background(), domain.PermissionChange{
+			GroupUUID: target.GroupID,
+			UserUUID:  target.UserID,
+		})
+
+		// Assert
+		assert.Equal(t, controlMessage{Type: controlAccessRevoked}, finalControl(t, target))
+	})
+
+	t.Run("IgnoresOtherGroups", func(t *testing.T) {
+		// Arrange
+		manager, _, target, _ := setup(t)
+
+		// Act
+		manager.NotifyPermissionChange(context.Background(), domain.PermissionChange{
+			GroupUUID: uuid.New(),
+			UserUUID:  target.UserID,
+		})
+
+		// Assert
+		assert.Empty(t, target.Send)
+	})
+
+	t.Run("DisconnectsGuestsOfRevokedShareLink", func(t *testing.T) {
+		// Arrange
+		manager, _, target, other := setup(t)
+		hub := openHub(t, manager, target.DocumentID)
+		linkID := uuid.New()
+		guest := newTestClient(target.DocumentID)
+		guest.Guest = true
+		guest.ShareLinkID = linkID
+		otherGuest := newTestClient(target.DocumentID)
+		otherGuest.Guest = true
+		otherGuest.ShareLinkID = uuid.New()
+		register(t, hub, guest)
+		register(t, hub, otherGuest)
+
+		// Act
+		manager.NotifyPermissionChange(context.Background(), domain.PermissionChange{ShareLinkUUID: linkID})
+
+		// Assert
+		assert.Equal(t, controlMessage{Type: controlAccessRevoked}, receiveControl(t, guest))
+		_, open := <-guest.Send
+		assert.False(t, open)
+		assert.Empty(t, otherGuest.Send)
+		assert.Empty(t, target.Send)
+		assert.Empty(t, other.Send)
+	})
+
+	t.Run("DisconnectsGuestsOfExpiredShareLinks", func(t *testing.T) {
+		// Arrange
+		manager, _, target, _ := setup(t)
+		hub := openHub(t, manager, target.DocumentID)
+		expired := newTestClient(target.DocumentID)
+		expired.Guest = true
+		expired.ExpiresAt = time.Now().Add(-time.Second)
+		valid := newTestClient(target.DocumentID)
+		valid.Guest = true
+		valid.ExpiresAt = time.Now().Add(time.Hour)
+		register(t, hub, expired)
+		register(t, hub, valid)
+
+		// Act
+		ran, err := manager.runInHub(context.Background(), hub.DocumentID, manager.expireClients)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, ran)
+		assert.Equal(t, controlMessage{Type: controlAccessRevoked}, receiveControl(t, expired))
+		_, open := <-expired.Send
+		assert.False(t, open)
+		assert.Empty(t, valid.Send)
+		assert.Empty(t, target.Send)
+	})
+
+	t.Run("AppliesChangesFromPeers", func(t *testing.T) {
+		// Arrange
+		broker := &recordingBroker{}
+		manager := NewHubManager(zap.NewNop(), nil, broker, Config{})
+		// The manager subscribes to permission changes before any hub exists.
+		fromPeers := broker.deliver
+		documentID := uuid.New()
+		hub := openHub(t, manager, documentID)
+		t.Cleanup(func() { manager.CloseHub(documentID) })
+
+		client := newTestClient(documentID)
+		client.GroupID = uuid.New()
+		register(t, hub, client)
+
+		message, err := json.Marshal(permissionEvent{GroupID: client.GroupID, UserID: client.UserID, Role: domain.RoleViewer})
+		require.NoError(t, err)
+
+		// Act
+		fromPeers(message)
+
+		// Assert
+		assert.Equal(t, domain.RoleViewer, receiveControl(t, client).Role)
+		require.Eventually(t, func() bool {
+			_, canEdit := client.access()
+			return !canEdit
+		}, time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,155 @@
+package websocket
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// presenceRefreshInterval throttles presence writes caused by awareness pings.
+	presenceRefreshInterval = 30 * time.Second
+	presenceTimeout         = 5 * time.Second
+	// presenceQueueSize bounds the presence releases waiting to be written.
+	presenceQueueSize = 256
+)
+
+// presenceStore records which members are connected to a document. Every
+// connection is counted, so a member stays present while any instance still
+// serves one of their connections.
+type presenceStore interface {
+	AddPresenceConnection(ctx context.Context, documentID, userID uuid.UUID) error
+	UpsertPresence(ctx context.Context, documentID, userID uuid.UUID, cursorPosition *int) error
+	RemovePresenceConnection(ctx context.Context, documentID, userID uuid.UUID) error
+}
+
+// presenceRelease is a closed member connection whose presence is yet to be
+// released.
+type presenceRelease struct {
+	documentID uuid.UUID
+	userID     uuid.UUID
+}
+
+// presenceReleases hands presence releases from hub goroutines to the
+// goroutine writing them, so a slow database never stalls a hub.
+type presenceReleases struct {
+	mu     sync.RWMutex
+	closed bool
+	queue  chan presenceRelease
+	done   chan struct{}
+}
+
+func newPresenceReleases() *presenceReleases {
+	return &presenceReleases{
+		queue: make(chan presenceRelease, presenceQueueSize),
+		done:  make(chan struct{}),
+	}
+}
+
+// push queues a release without blocking. It reports false when the queue is
+// full or closed.
+func (r *presenceReleases) push(release presenceRelease) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return false
+	}
+	select {
+	case r.queue <- release:
+		return true
+	default:
+		return false
+	}
+}
+
+// close stops accepting releases; the writer exits once the queue is drained.
+func (r *presenceReleases) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+}
+
+// addPresence counts a new connection of a member. It runs on the request
+// goroutine before the client joins the hub, so the release queued when the
+// client leaves always follows it.
+func (m *HubManager) addPresence(ctx context.Context, client *ClientConnection) {
+	if m.presence == nil || client.Guest {
+		return
+	}
+	client.presenceSavedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, presenceTimeout)
+	defer cancel()
+	if err := m.presence.AddPresenceConnection(ctx, client.DocumentID, client.UserID); err != nil {
+		m.logger.Warn(
+			"failed to add presence",
+			zap.String("document_id", client.DocumentID.String()),
+			zap.String("user_id", client.UserID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// touchPresence records that a member is connected to the document, at most
+// once per presenceRefreshInterval. It runs on the client's read goroutine.
+func (m *HubManager) touchPresence(ctx context.Context, client *ClientConnection) {
+	if m.presence == nil || client.Guest {
+		return
+	}
+	if time.Since(client.presenceSavedAt) < presenceRefreshInterval {
+		return
+	}
+	client.presenceSavedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, presenceTimeout)
+	defer cancel()
+	if err := m.presence.UpsertPresence(ctx, client.DocumentID, client.UserID, nil); err != nil {
+		m.logger.Warn(
+			"failed to save presence",
+			zap.String("document_id", client.DocumentID.String()),
+			zap.String("user_id", client.UserID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// removePresence queues the release of a closed member connection. It runs on
+// the hub goroutine and never waits: a release that does not fit in the queue
+// is dropped, and the presence then expires once it is no longer refreshed.
+func (m *HubManager) removePresence(client *ClientConnection) {
+	if m.presence == nil || client.Guest {
+		return
+	}
+	if !m.presenceReleases.push(presenceRelease{documentID: client.DocumentID, userID: client.UserID}) {
+		m.logger.Warn(
+			"dropped presence release",
+			zap.String("document_id", client.DocumentID.String()),
+			zap.String("user_id", client.UserID.String()),
+		)
+	}
+}
+
+// releasePresence writes queued presence releases until the queue is closed.
+func (m *HubManager) releasePresence() {
+	defer close(m.presenceReleases.done)
+
+	for release := range m.presenceReleases.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), presenceTimeout)
+		err := m.presence.RemovePresenceConnection(ctx, release.documentID, release.userID)
+		cancel()
+		if err != nil {
+			m.logger.Warn(
+				"failed to remove presence",
+				zap.String("document_id", release.documentID.String()),
+				zap.String("user_id", release.userID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
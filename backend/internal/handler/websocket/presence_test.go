@@ -0,0 +1,82 @@
+package websocket
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// blockingPresenceStore counts presence connections per user and holds every
+// release until it is unblocked.
+type blockingPresenceStore struct {
+	mu          sync.Mutex
+	connections map[uuid.UUID]int
+	unblock     chan struct{}
+}
+
+func (s *blockingPresenceStore) AddPresenceConnection(_ context.Context, _, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connections[userID]++
+	return nil
+}
+
+func (s *blockingPresenceStore) UpsertPresence(context.Context, uuid.UUID, uuid.UUID, *int) error {
+	return nil
+}
+
+func (s *blockingPresenceStore) RemovePresenceConnection(_ context.Context, _, userID uuid.UUID) error {
+	<-s.unblock
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connections[userID]--
+	return nil
+}
+
+func (s *blockingPresenceStore) count(userID uuid.UUID) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections[userID]
+}
+
+func TestHubPresence(t *testing.T) {
+	// Arrange
+	store := &blockingPresenceStore{connections: make(map[uuid.UUID]int), unblock: make(chan struct{})}
+	manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+	manager.presence = store
+	documentID := uuid.New()
+	hub := openHub(t, manager, documentID)
+
+	first := newTestClient(documentID)
+	second := newTestClient(documentID)
+	second.UserID = first.UserID
+	stayer := newTestClient(documentID)
+	for _, client := range []*ClientConnection{first, second, stayer} {
+		manager.addPresence(context.Background(), client)
+		register(t, hub, client)
+	}
+
+	// Act: the store holds the release of first while the hub keeps going
+	hub.Unregister <- first
+	ran, err := manager.runInHub(context.Background(), documentID, func(*DocumentHub) {})
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, ran)
+	assert.Equal(t, 2, store.count(first.UserID))
+
+	close(store.unblock)
+	assert.Eventually(t, func() bool { return store.count(first.UserID) == 1 }, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, manager.Shutdown(ctx))
+	assert.Equal(t, 0, store.count(first.UserID))
+	assert.Equal(t, 0, store.count(stayer.UserID))
+}
@@ -0,0 +1,105 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+)
+
+var (
+	errEmptyMessage    = errors.New("websocket: empty message")
+	errTrailingContent = errors.New("websocket: trailing bytes after message")
+)
+
+// protocolMessage is a decoded y-websocket frame.
+type protocolMessage struct {
+	Type uint64
+	// Step is the y-protocols sync step, only set for sync messages.
+	Step    uint64
+	Payload []byte
+}
+
+// decodeMessage parses the envelope of a y-websocket frame. Frames of unknown
+// types are returned with an empty payload so they can still be relayed.
+func decodeMessage(data []byte) (protocolMessage, error) {
+	if len(data) == 0 {
+		return protocolMessage{}, errEmptyMessage
+	}
+
+	dec := yjs.NewDecoder(data)
+	msgType, err := dec.ReadVarUint()
+	if err != nil {
+		return protocolMessage{}, err
+	}
+
+	msg := protocolMessage{Type: msgType}
+	switch msgType {
+	case MessageTypeSync:
+		if msg.Step, err = dec.ReadVarUint(); err != nil {
+			return protocolMessage{}, err
+		}
+		if msg.Payload, err = dec.ReadVarUint8Array(); err != nil {
+			return protocolMessage{}, err
+		}
+	case MessageTypeAwareness:
+		if msg.Payload, err = dec.ReadVarUint8Array(); err != nil {
+			return protocolMessage{}, err
+		}
+	case MessageTypeControl:
+		notice, err := dec.ReadVarString()
+		if err != nil {
+			return protocolMessage{}, err
+		}
+		msg.Payload = []byte(notice)
+	}
+	if msg.Type <= MessageTypeAwareness && dec.HasContent() {
+		return protocolMessage{}, errTrailingContent
+	}
+
+	return msg, nil
+}
+
+// validateClientMessage checks that a frame sent by a client is a well-formed
+// y-protocols message: a known message type and sync step, and a payload that
+// decodes. Updates may not span more clocks than maxSpan. Awareness states are
+// checked when they are stamped.
+func validateClientMessage(msg protocolMessage, maxSpan int) error {
+	switch msg.Type {
+	case MessageTypeSync:
+		switch msg.Step {
+		case YjsSyncStep1:
+			if _, err := yjs.DecodeStateVector(msg.Payload); err != nil {
+				return fmt.Errorf("websocket: malformed state vector: %w", err)
+			}
+		case YjsSyncStep2, YjsUpdate:
+			span, err := yjs.UpdateSpan(msg.Payload)
+			if err != nil {
+				return fmt.Errorf("websocket: malformed update: %w", err)
+			}
+			if span > uint64(maxSpan) {
+				return fmt.Errorf("websocket: update spans %d clocks, over the limit of %d", span, maxSpan)
+			}
+		default:
+			return fmt.Errorf("websocket: unknown sync step %d", msg.Step)
+		}
+	case MessageTypeAwareness, MessageTypeQueryAwareness:
+	default:
+		return fmt.Errorf("websocket: unknown message type %d", msg.Type)
+	}
+	return nil
+}
+
+// isDocumentUpdate reports whether the message carries a Yjs update.
+func (m protocolMessage) isDocumentUpdate() bool {
+	return m.Type == MessageTypeSync && (m.Step == YjsSyncStep2 || m.Step == YjsUpdate)
+}
+
+// encodeSyncMessage wraps a sync step payload into a y-websocket frame.
+func encodeSyncMessage(step uint64, payload []byte) []byte {
+	enc := yjs.NewEncoder()
+	enc.WriteVarUint(MessageTypeSync)
+	enc.WriteVarUint(step)
+	enc.WriteVarUint8Array(payload)
+	return enc.Bytes()
+}
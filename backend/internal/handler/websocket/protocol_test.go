@@ -0,0 +1,69 @@
+package websocket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+)
+
+func TestValidateClientMessage(t *testing.T) {
+	encode := func(values ...uint64) []byte {
+		enc := yjs.NewEncoder()
+		for _, value := range values {
+			enc.WriteVarUint(value)
+		}
+		return enc.Bytes()
+	}
+	// gcRuns encodes an update of count GC ranges of length each.
+	gcRuns := func(count int, length uint64) []byte {
+		enc := yjs.NewEncoder()
+		enc.WriteVarUint(1)
+		enc.WriteVarUint(uint64(count))
+		enc.WriteVarUint(7)
+		enc.WriteVarUint(0)
+		for range count {
+			enc.WriteUint8(0)
+			enc.WriteVarUint(length)
+		}
+		enc.WriteVarUint(0)
+		return enc.Bytes()
+	}
+
+	tests := []struct {
+		name    string
+		message []byte
+		valid   bool
+	}{
+		{name: "Update", message: encodeSyncMessage(YjsUpdate, textInsert(7, "hello")), valid: true},
+		{name: "SyncStep1", message: encodeSyncMessage(YjsSyncStep1, yjs.StateVector{7: 5}.Encode()), valid: true},
+		{name: "SyncStep2", message: encodeSyncMessage(YjsSyncStep2, []byte{0, 0}), valid: true},
+		{name: "QueryAwareness", message: encode(MessageTypeQueryAwareness), valid: true},
+		{name: "UpdateWithinSpanLimit", message: encodeSyncMessage(YjsUpdate, gcRuns(4, 1<<10)), valid: true},
+		{name: "UpdateOverSpanLimit", message: encodeSyncMessage(YjsUpdate, gcRuns(4, 1<<20))},
+		{name: "StateOverSpanLimit", message: encodeSyncMessage(YjsSyncStep2, gcRuns(4, 1<<20))},
+		{name: "Control", message: encodeControl(controlMessage{Type: controlPermissionChanged})},
+		{name: "MalformedUpdate", message: encodeSyncMessage(YjsUpdate, []byte{1, 1, 7})},
+		{name: "MalformedStateVector", message: encodeSyncMessage(YjsSyncStep1, []byte{2, 7})},
+		{name: "UnknownSyncStep", message: encodeSyncMessage(5, []byte{0, 0})},
+		{name: "UnknownType", message: encode(7)},
+		{name: "TrailingBytes", message: append(encodeSyncMessage(YjsUpdate, []byte{0, 0}), 1)},
+		{name: "TruncatedHeader", message: []byte{MessageTypeSync}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := decodeMessage(tt.message)
+			if err == nil {
+				err = validateClientMessage(msg, 1<<20)
+			}
+
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
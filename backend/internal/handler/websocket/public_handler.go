@@ -3,7 +3,6 @@ package websocket
 import (
 	"context"
 	"errors"
-	"hash/crc32"
 	"net/http"
 	"strconv"
 	"time"
@@ -15,10 +14,14 @@ import (
 )
 
 type publicDocumentService interface {
-	ValidateShareLink(ctx context.Context, docParam, sigParam, expParam string) (*domain.Document, error)
+	OpenShareLink(ctx context.Context, query domain.ShareLinkQuery) (*domain.Document, string, *domain.ShareVisit, error)
+	RecordShareLinkAccess(ctx context.Context, docUUID uuid.UUID, query domain.ShareLinkQuery, visit domain.ShareLinkVisit) error
 }
 
-// NewPublicWebSocketHandler upgrades WebSocket connections for shared links (read-only guests).
+// NewPublicWebSocketHandler upgrades WebSocket connections for shared links. Guests get the role the link
+// grants; only editor links may change the document. Password-protected links need the guest cookie set when
+// the link was unlocked. A connection without the visit cookie of a stored link counts as a use of it. Guests
+// are disconnected once the link expires.
 func NewPublicWebSocketHandler(
 	shareService publicDocumentService,
 	hubManager *HubManager,
@@ -28,23 +31,39 @@ func NewPublicWebSocketHandler(
 	upgrader := createUpgrader(allowedOrigins)
 
 	return func(c *gin.Context) {
-		docParam := c.Param("uuid")
-		sigParam := c.Query("sig")
-		expParam := c.Query("exp")
-
-		if docParam == "" || sigParam == "" || expParam == "" {
+		query := domain.ShareLinkQuery{
+			Doc:  c.Param("uuid"),
+			Sig:  c.Query("sig"),
+			Exp:  c.Query("exp"),
+			Role: c.Query("role"),
+			Link: c.Query("link"),
+		}
+		if query.Link != "" {
+			query.Token, _ = c.Cookie(domain.ShareTokenCookiePrefix + query.Link)
+			query.Visit, _ = c.Cookie(domain.ShareVisitCookiePrefix + query.Link)
+		}
+		if query.Doc == "" || query.Sig == "" || query.Exp == "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "missing required parameters"})
 			return
 		}
 
-		document, err := shareService.ValidateShareLink(c.Request.Context(), docParam, sigParam, expParam)
+		document, role, opened, err := shareService.OpenShareLink(c.Request.Context(), query)
 		switch {
 		case errors.Is(err, domain.ErrShareLinkExpired):
 			c.JSON(http.StatusGone, gin.H{"error": "share link expired"})
 			return
+		case errors.Is(err, domain.ErrShareLinkRevoked):
+			c.JSON(http.StatusGone, gin.H{"error": "share link revoked"})
+			return
+		case errors.Is(err, domain.ErrShareLinkUsedUp):
+			c.JSON(http.StatusGone, gin.H{"error": "share link has no uses left"})
+			return
 		case errors.Is(err, domain.ErrShareLinkInvalid):
 			c.JSON(http.StatusNotFound, gin.H{"error": "invalid share link"})
 			return
+		case errors.Is(err, domain.ErrShareLinkLocked):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "share link requires a password"})
+			return
 		case errors.Is(err, domain.ErrDocumentNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
 			return
@@ -54,32 +73,64 @@ func NewPublicWebSocketHandler(
 			return
 		}
 
-		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		// Reconnects of the socket then do not count as further uses.
+		var header http.Header
+		if opened != nil {
+			cookie := &http.Cookie{
+				Name:     domain.ShareVisitCookiePrefix + query.Link,
+				Value:    opened.Token,
+				Path:     "/",
+				Expires:  opened.ExpiresAt,
+				Secure:   true,
+				HttpOnly: true,
+				SameSite: http.SameSiteNoneMode,
+			}
+			header = http.Header{"Set-Cookie": {cookie.String()}}
+		}
+
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, header)
 		if err != nil {
 			logger.Error("failed to upgrade public websocket connection", zap.Error(err))
 			return
 		}
 
-		awarenessID, err := generateAwarenessID()
-		if err != nil {
-			logger.Warn("failed to generate awareness id for guest, falling back to timestamp", zap.Error(err))
-			awarenessID = crc32.ChecksumIEEE([]byte(strconv.FormatInt(time.Now().UnixNano(), 10)))
+		visit := domain.ShareLinkVisit{
+			Channel:   domain.ShareChannelWebSocket,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		if err := shareService.RecordShareLinkAccess(c.Request.Context(), document.UUID, query, visit); err != nil {
+			logger.Warn("failed to record share link access", zap.Error(err), zap.String("document_id", document.UUID.String()))
 		}
 
+		// The link parameters were checked with the link; the link UUID is
+		// empty for links that are not stored.
+		shareLinkID, _ := uuid.Parse(query.Link)
+		expiresAt, _ := strconv.ParseInt(query.Exp, 10, 64)
 		client := &ClientConnection{
-			ID:          uuid.New(),
-			UserID:      uuid.New(),
-			UserName:    "guest",
-			DocumentID:  document.UUID,
-			Conn:        conn,
-			Send:        make(chan []byte, initialSendBufferSize),
-			Done:        make(chan struct{}),
-			LastSeen:    time.Now(),
-			AwarenessID: awarenessID,
-			CanEdit:     false,
+			ID:            uuid.New(),
+			UserID:        uuid.New(),
+			UserName:      "guest",
+			DocumentID:    document.UUID,
+			GroupID:       document.GroupUUID,
+			Conn:          conn,
+			Send:          make(chan []byte, initialSendBufferSize),
+			Done:          make(chan struct{}),
+			LastSeen:      time.Now(),
+			Role:          role,
+			CanEdit:       role == domain.RoleEditor,
+			Guest:         true,
+			ShareLinkID:   shareLinkID,
+			ExpiresAt:     time.Unix(expiresAt, 0),
+			ResumeVersion: resumeVersion(c),
 		}
 
-		hub := hubManager.GetOrCreateHub(document.UUID)
+		hub, err := hubManager.GetOrCreateHub(c.Request.Context(), document.UUID)
+		if err != nil {
+			logger.Warn("failed to open collaboration hub", zap.Error(err), zap.String("document_id", document.UUID.String()))
+			closeRefused(conn, err, logger)
+			return
+		}
 
 		select {
 		case hub.Register <- client:
@@ -94,7 +145,7 @@ func NewPublicWebSocketHandler(
 			return
 		}
 
-		go writePump(client, logger)
+		go writePump(hubManager, hub, client, logger)
 		readPump(c.Request.Context(), hubManager, hub, client, logger)
 	}
 }
@@ -0,0 +1,318 @@
+package websocket
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+	"go.uber.org/zap"
+)
+
+const (
+	// hubShardCount is how many independently locked parts the hub registry
+	// is split into.
+	hubShardCount = 32
+	// hubLoadTimeout bounds loading the persisted state of a new hub.
+	hubLoadTimeout = 10 * time.Second
+)
+
+// unavailableCloseMessage tells clients that the document could not be loaded
+// and that they should retry later.
+var unavailableCloseMessage = websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "document unavailable, retry later")
+
+// stateStore loads the persisted state of a document.
+type stateStore interface {
+	LoadSnapshot(ctx context.Context, documentID uuid.UUID) (*repo.SnapshotRecord, error)
+	GetUpdates(ctx context.Context, documentID uuid.UUID, fromVersion int) ([]repo.UpdateRecord, error)
+	GetMaxDocumentSize(ctx context.Context, documentID uuid.UUID) (int, error)
+}
+
+// hubShard holds the hubs of the documents that hash to it. Its lock is never
+// held while a hub loads, so a slow load only delays the joiners of that
+// document.
+type hubShard struct {
+	mu   sync.Mutex
+	hubs map[uuid.UUID]*DocumentHub
+	// closing holds closed hubs until they stopped, that is until their
+	// pending updates and state are saved.
+	closing map[uuid.UUID]*DocumentHub
+	loading map[uuid.UUID]*hubLoad
+}
+
+// hubLoad is a hub being loaded; joiners arriving meanwhile wait on done
+// instead of loading the document again.
+type hubLoad struct {
+	done chan struct{}
+	hub  *DocumentHub
+	err  error
+}
+
+func newHubShards() []*hubShard {
+	shards := make([]*hubShard, hubShardCount)
+	for i := range shards {
+		shards[i] = &hubShard{
+			hubs:    make(map[uuid.UUID]*DocumentHub),
+			closing: make(map[uuid.UUID]*DocumentHub),
+			loading: make(map[uuid.UUID]*hubLoad),
+		}
+	}
+	return shards
+}
+
+func (m *HubManager) shard(documentID uuid.UUID) *hubShard {
+	return m.shards[binary.BigEndian.Uint32(documentID[12:])%hubShardCount]
+}
+
+// GetOrCreateHub returns an existing hub for a document or initializes a new one, loading persisted state if available.
+// Concurrent callers for the same document share one load, which waits until a previous hub of the document stopped,
+// so that the updates it still had to save are loaded. It fails with ErrShuttingDown once Shutdown was called, and
+// with the context error when ctx is done before the hub is ready.
+func (m *HubManager) GetOrCreateHub(ctx context.Context, documentID uuid.UUID) (*DocumentHub, error) {
+	shard := m.shard(documentID)
+
+	shard.mu.Lock()
+	if m.isClosing() {
+		shard.mu.Unlock()
+		return nil, ErrShuttingDown
+	}
+	if hub, ok := shard.hubs[documentID]; ok {
+		shard.mu.Unlock()
+		return hub, nil
+	}
+	load, loading := shard.loading[documentID]
+	if !loading {
+		load = &hubLoad{done: make(chan struct{})}
+		shard.loading[documentID] = load
+	}
+	previous := shard.closing[documentID]
+	shard.mu.Unlock()
+
+	if !loading {
+		// The load is not tied to ctx: other joiners may be waiting for it.
+		go m.startHub(shard, documentID, load, previous)
+	}
+
+	select {
+	case <-load.done:
+		return load.hub, load.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// startHub loads a hub once previous, the closing hub of the document if any,
+// stopped and, unless loading failed, starts it and adds it to the registry.
+// It reports the outcome to everyone waiting on load.
+func (m *HubManager) startHub(shard *hubShard, documentID uuid.UUID, load *hubLoad, previous *DocumentHub) {
+	defer close(load.done)
+
+	if previous != nil {
+		<-previous.stopped
+	}
+
+	hub, err := m.loadHub(documentID)
+	if err == nil {
+		// Subscribing talks to the broker, so it happens before the shard is
+		// locked; joiners keep waiting on load until the hub is added.
+		m.subscribeHub(hub)
+	}
+
+	shard.mu.Lock()
+	delete(shard.loading, documentID)
+	switch {
+	case err != nil:
+		load.err = err
+	case m.isClosing():
+		load.err = ErrShuttingDown
+	default:
+		shard.hubs[documentID] = hub
+		m.startWriter(hub)
+		go m.run(hub)
+		load.hub = hub
+	}
+	shard.mu.Unlock()
+
+	if errors.Is(load.err, ErrShuttingDown) {
+		m.unsubscribeHub(hub)
+	}
+}
+
+// loadHub builds a hub from the persisted snapshot of a document and the
+// updates stored after it.
+func (m *HubManager) loadHub(documentID uuid.UUID) (*DocumentHub, error) {
+	hub := newHub(documentID)
+	hub.maxSize = m.cfg.MaxDocumentSize
+	if m.states == nil {
+		return hub, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hubLoadTimeout)
+	defer cancel()
+
+	record, err := m.states.LoadSnapshot(ctx, documentID)
+	if err != nil {
+		// An empty hub would overwrite the stored state with its own.
+		return nil, fmt.Errorf("websocket: load snapshot: %w", err)
+	}
+	if record != nil {
+		if err := applySnapshot(hub.YjsDoc, record.YjsSnapshot); err != nil {
+			// Starting without it would overwrite the stored state on the next save.
+			return nil, err
+		}
+		hub.Version = record.Version
+		hub.persistedVersion = record.Version
+		hub.LastUpdated = record.LastModified
+	}
+
+	// Every update not yet folded into the snapshot is merged: the snapshot
+	// version only says which updates its instance stored, not that it merged
+	// those stored by others meanwhile. Merging an update twice changes nothing.
+	var compactedVersion int
+	if record != nil {
+		compactedVersion = record.CompactedVersion
+	}
+	updates, err := m.states.GetUpdates(ctx, documentID, compactedVersion)
+	if err != nil {
+		return nil, fmt.Errorf("websocket: load updates: %w", err)
+	}
+	for _, update := range updates {
+		if err := hub.YjsDoc.ApplyUpdate(update.YjsUpdate); err != nil {
+			m.logger.Warn("failed to decode update for document", zap.String("document_id", documentID.String()), zap.Error(err))
+			continue
+		}
+		if update.Version > hub.persistedVersion {
+			hub.LastUpdated = update.CreatedAt
+		}
+		hub.persistedVersion = max(hub.persistedVersion, update.Version)
+		hub.Version = max(hub.Version, update.Version)
+	}
+	hub.stateSize = len(hub.YjsDoc.EncodeStateAsUpdate(nil))
+
+	maxSize, err := m.states.GetMaxDocumentSize(ctx, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("websocket: load document size limit: %w", err)
+	}
+	if maxSize > 0 {
+		hub.maxSize = maxSize
+	}
+
+	return hub, nil
+}
+
+// applySnapshot merges a stored snapshot into doc. Snapshots saved before hubs
+// kept a merged state hold the last y-websocket update frame instead; the
+// update it carries is loaded and replaces it on the next save.
+func applySnapshot(doc *yjs.Doc, snapshot []byte) error {
+	err := doc.ApplyUpdate(snapshot)
+	if err == nil {
+		return nil
+	}
+	if msg, frameErr := decodeMessage(snapshot); frameErr == nil && msg.isDocumentUpdate() {
+		if doc.ApplyUpdate(msg.Payload) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("websocket: decode snapshot: %w", err)
+}
+
+func newHub(documentID uuid.UUID) *DocumentHub {
+	return &DocumentHub{
+		DocumentID:       documentID,
+		Clients:          make(map[*ClientConnection]bool),
+		Broadcast:        make(chan []byte, broadcastBufferSize),
+		Updates:          make(chan clientUpdate, broadcastBufferSize),
+		SyncStep1:        make(chan syncRequest, syncBufferSize),
+		Remote:           make(chan []byte, remoteBufferSize),
+		Awareness:        make(chan *ClientConnection, awarenessBufferSize),
+		Tasks:            make(chan hubTask),
+		Register:         make(chan *ClientConnection, registerBufferSize),
+		Unregister:       make(chan *ClientConnection, unregisterBufferSize),
+		Done:             make(chan struct{}),
+		stopped:          make(chan struct{}),
+		YjsDoc:           yjs.NewDoc(),
+		awareness:        make(map[uint64]*awarenessState),
+		awarenessChanged: make(map[uint64]bool),
+		Version:          defaultHubVersion,
+		LastUpdated:      time.Now(),
+		openedAt:         time.Now(),
+	}
+}
+
+// lookupHub returns the running hub of a document on this instance.
+func (m *HubManager) lookupHub(documentID uuid.UUID) (*DocumentHub, bool) {
+	shard := m.shard(documentID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	hub, ok := shard.hubs[documentID]
+	return hub, ok
+}
+
+// CloseHub terminates a hub and removes it from manager.
+func (m *HubManager) CloseHub(documentID uuid.UUID) {
+	if hub, ok := m.lookupHub(documentID); ok {
+		m.closeHub(hub)
+	}
+}
+
+// closeHub is CloseHub for a known hub; it leaves alone a newer hub opened for
+// the same document after this one was closed. The hub stays registered as
+// closing until it stopped.
+func (m *HubManager) closeHub(hub *DocumentHub) {
+	shard := m.shard(hub.DocumentID)
+	shard.mu.Lock()
+	current, ok := shard.hubs[hub.DocumentID]
+	ok = ok && current == hub
+	if ok {
+		delete(shard.hubs, hub.DocumentID)
+		shard.closing[hub.DocumentID] = hub
+	}
+	shard.mu.Unlock()
+
+	if ok {
+		close(hub.Done)
+	}
+}
+
+// forgetHub removes a stopped hub from the registry.
+func (m *HubManager) forgetHub(hub *DocumentHub) {
+	shard := m.shard(hub.DocumentID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if shard.closing[hub.DocumentID] == hub {
+		delete(shard.closing, hub.DocumentID)
+	}
+}
+
+// closingHubs returns the hubs of this instance that were closed but have not
+// stopped yet.
+func (m *HubManager) closingHubs() []*DocumentHub {
+	var hubs []*DocumentHub
+	for _, shard := range m.shards {
+		shard.mu.Lock()
+		for _, hub := range shard.closing {
+			hubs = append(hubs, hub)
+		}
+		shard.mu.Unlock()
+	}
+	return hubs
+}
+
+// openHubs returns the hubs running on this instance.
+func (m *HubManager) openHubs() []*DocumentHub {
+	var hubs []*DocumentHub
+	for _, shard := range m.shards {
+		shard.mu.Lock()
+		for _, hub := range shard.hubs {
+			hubs = append(hubs, hub)
+		}
+		shard.mu.Unlock()
+	}
+	return hubs
+}
@@ -0,0 +1,301 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
+	"go.uber.org/zap"
+)
+
+// slowStore serves snapshot, or empty documents, after delay. Loads of the
+// blocked document also wait until release is closed.
+type slowStore struct {
+	delay    time.Duration
+	blocked  uuid.UUID
+	release  chan struct{}
+	err      error
+	maxSize  int
+	snapshot []byte
+
+	mu    sync.Mutex
+	loads map[uuid.UUID]int
+}
+
+func (s *slowStore) LoadSnapshot(ctx context.Context, documentID uuid.UUID) (*repo.SnapshotRecord, error) {
+	s.mu.Lock()
+	if s.loads == nil {
+		s.loads = make(map[uuid.UUID]int)
+	}
+	s.loads[documentID]++
+	s.mu.Unlock()
+
+	if documentID == s.blocked {
+		select {
+		case <-s.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if s.err != nil || s.snapshot == nil {
+		return nil, s.err
+	}
+	return &repo.SnapshotRecord{DocumentID: documentID, YjsSnapshot: s.snapshot, Version: 1}, nil
+}
+
+// blockingBroker holds subscriptions to the blocked document until release is
+// closed.
+type blockingBroker struct {
+	recordingBroker
+	blocked    uuid.UUID
+	subscribed chan struct{}
+	release    chan struct{}
+}
+
+func (b *blockingBroker) Subscribe(documentID uuid.UUID, deliver func(message []byte), resync func()) (uint64, error) {
+	if documentID == b.blocked {
+		close(b.subscribed)
+		<-b.release
+	}
+	return b.recordingBroker.Subscribe(documentID, deliver, resync)
+}
+
+func (s *slowStore) GetUpdates(context.Context, uuid.UUID, int) ([]repo.UpdateRecord, error) {
+	return nil, nil
+}
+
+func (s *slowStore) GetMaxDocumentSize(context.Context, uuid.UUID) (int, error) {
+	return s.maxSize, nil
+}
+
+func (s *slowStore) loadCount(documentID uuid.UUID) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loads[documentID]
+}
+
+func TestGetOrCreateHub(t *testing.T) {
+	setup := func(t *testing.T, store *slowStore) *HubManager {
+		manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+		manager.states = store
+		t.Cleanup(func() {
+			for _, hub := range manager.openHubs() {
+				manager.closeHub(hub)
+			}
+		})
+		return manager
+	}
+
+	t.Run("JoinersShareOneLoad", func(t *testing.T) {
+		// Arrange
+		documentID := uuid.New()
+		store := &slowStore{blocked: documentID, release: make(chan struct{})}
+		manager := setup(t, store)
+
+		// Act: many clients join the document while it loads
+		const joiners = 10
+		hubs := make(chan *DocumentHub, joiners)
+		for range joiners {
+			go func() {
+				hub, err := manager.GetOrCreateHub(context.Background(), documentID)
+				assert.NoError(t, err)
+				hubs <- hub
+			}()
+		}
+		require.Eventually(t, func() bool { return store.loadCount(documentID) == 1 }, time.Second, time.Millisecond)
+
+		// Assert: other documents open meanwhile
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err := manager.GetOrCreateHub(ctx, uuid.New())
+		require.NoError(t, err)
+
+		close(store.release)
+		first := <-hubs
+		for range joiners - 1 {
+			assert.Same(t, first, <-hubs)
+		}
+		assert.Equal(t, 1, store.loadCount(documentID))
+	})
+
+	t.Run("ReportsLoadFailure", func(t *testing.T) {
+		// Arrange
+		store := &slowStore{err: errors.New("database unavailable")}
+		manager := setup(t, store)
+		documentID := uuid.New()
+
+		// Act
+		_, err := manager.GetOrCreateHub(context.Background(), documentID)
+
+		// Assert: no empty hub is left behind, the next joiner loads again
+		require.ErrorIs(t, err, store.err)
+		_, ok := manager.lookupHub(documentID)
+		assert.False(t, ok)
+
+		_, err = manager.GetOrCreateHub(context.Background(), documentID)
+		require.ErrorIs(t, err, store.err)
+		assert.Equal(t, 2, store.loadCount(documentID))
+	})
+
+	t.Run("SubscribesWithoutLockingTheShard", func(t *testing.T) {
+		// Arrange: two documents of the same shard
+		documentID := uuid.New()
+		neighbour := documentID
+		neighbour[0]++
+		broker := &blockingBroker{blocked: documentID, subscribed: make(chan struct{}), release: make(chan struct{})}
+		manager := setup(t, &slowStore{})
+		manager.broker = broker
+		require.Same(t, manager.shard(documentID), manager.shard(neighbour))
+
+		loaded := make(chan error, 1)
+		go func() {
+			_, err := manager.GetOrCreateHub(context.Background(), documentID)
+			loaded <- err
+		}()
+		<-broker.subscribed
+
+		// Act: the neighbour opens while the first hub subscribes
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err := manager.GetOrCreateHub(ctx, neighbour)
+
+		// Assert
+		require.NoError(t, err)
+		close(broker.release)
+		require.NoError(t, <-loaded)
+	})
+
+	t.Run("FailsOnUndecodableSnapshot", func(t *testing.T) {
+		// Arrange
+		store := &slowStore{snapshot: []byte{0xff, 0xff}}
+		manager := setup(t, store)
+		documentID := uuid.New()
+
+		// Act
+		_, err := manager.GetOrCreateHub(context.Background(), documentID)
+
+		// Assert: no empty hub can overwrite the stored state
+		require.Error(t, err)
+		_, ok := manager.lookupHub(documentID)
+		assert.False(t, ok)
+	})
+
+	t.Run("LoadsLegacyFrameSnapshots", func(t *testing.T) {
+		// Arrange: the last update frame a client sent
+		store := &slowStore{snapshot: encodeSyncMessage(YjsUpdate, textInsert(7, "legacy"))}
+		manager := setup(t, store)
+
+		// Act
+		hub, err := manager.GetOrCreateHub(context.Background(), uuid.New())
+
+		// Assert
+		require.NoError(t, err)
+		ran, err := manager.runInHub(context.Background(), hub.DocumentID, func(hub *DocumentHub) {
+			assert.Equal(t, "legacy", hub.YjsDoc.Text(domain.DocumentTextName))
+		})
+		require.NoError(t, err)
+		assert.True(t, ran)
+	})
+
+	t.Run("WaitsForTheClosingHubToStop", func(t *testing.T) {
+		// Arrange: the old hub is still busy when it is closed
+		store := &slowStore{}
+		manager := setup(t, store)
+		documentID := uuid.New()
+		old, err := manager.GetOrCreateHub(context.Background(), documentID)
+		require.NoError(t, err)
+
+		busy, release := make(chan struct{}), make(chan struct{})
+		go func() {
+			_, _ = manager.runInHub(context.Background(), documentID, func(*DocumentHub) {
+				close(busy)
+				<-release
+			})
+		}()
+		<-busy
+		manager.closeHub(old)
+
+		// Act: a client reconnects before the old hub saved its state
+		opened := make(chan *DocumentHub, 1)
+		go func() {
+			hub, err := manager.GetOrCreateHub(context.Background(), documentID)
+			assert.NoError(t, err)
+			opened <- hub
+		}()
+
+		// Assert: the new hub only loads once the old one stopped
+		assert.Never(t, func() bool { return store.loadCount(documentID) > 1 }, 50*time.Millisecond, 5*time.Millisecond)
+		close(release)
+		hub := <-opened
+		assert.NotSame(t, old, hub)
+		assert.Equal(t, 2, store.loadCount(documentID))
+		select {
+		case <-old.stopped:
+		default:
+			t.Fatal("new hub loaded before the old one stopped")
+		}
+	})
+
+	t.Run("AppliesGroupSizeLimit", func(t *testing.T) {
+		manager := setup(t, &slowStore{maxSize: 512})
+
+		hub, err := manager.GetOrCreateHub(context.Background(), uuid.New())
+
+		require.NoError(t, err)
+		assert.Equal(t, 512, hub.maxSize)
+	})
+
+	t.Run("StopsWaitingWhenContextIsDone", func(t *testing.T) {
+		// Arrange
+		documentID := uuid.New()
+		store := &slowStore{blocked: documentID, release: make(chan struct{})}
+		manager := setup(t, store)
+		go func() { _, _ = manager.GetOrCreateHub(context.Background(), documentID) }()
+		require.Eventually(t, func() bool { return store.loadCount(documentID) == 1 }, time.Second, time.Millisecond)
+
+		// Act
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := manager.GetOrCreateHub(ctx, documentID)
+
+		// Assert
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		close(store.release)
+		require.Eventually(t, func() bool {
+			_, ok := manager.lookupHub(documentID)
+			return ok
+		}, time.Second, time.Millisecond)
+	})
+}
+
+// BenchmarkGetOrCreateHub measures how fast connections to many different
+// documents are accepted when loading each document takes a millisecond.
+func BenchmarkGetOrCreateHub(b *testing.B) {
+	manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+	manager.states = &slowStore{delay: time.Millisecond}
+	b.SetParallelism(16)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			hub, err := manager.GetOrCreateHub(context.Background(), uuid.New())
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			manager.closeHub(hub)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrShuttingDown is returned for documents opened after Shutdown was called.
+var ErrShuttingDown = errors.New("websocket: hub manager is shutting down")
+
+// restartCloseMessage tells clients that the server goes away and that they
+// should reconnect, possibly to another instance.
+var restartCloseMessage = websocket.FormatCloseMessage(websocket.CloseServiceRestart, "server restarting, reconnect")
+
+// Shutdown stops opening hubs, persists the state and pending updates of every
+// open hub and disconnects its clients with a restart close code. It returns
+// once all hubs stopped and their presence was released, or with the context
+// error when ctx is done first.
+func (m *HubManager) Shutdown(ctx context.Context) error {
+	m.closing.Store(true)
+	// A hub that was still loading is either listed here or refused, as its
+	// loader checks closing under the same shard lock.
+	for _, hub := range m.openHubs() {
+		// cleanupHub persists the hub before it disconnects the clients.
+		m.closeHub(hub)
+	}
+
+	// Hubs closed earlier may still be saving their state too.
+	for _, hub := range m.closingHubs() {
+		select {
+		case <-hub.stopped:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	m.presenceReleases.close()
+	select {
+	case <-m.presenceReleases.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (m *HubManager) isClosing() bool {
+	return m.closing.Load()
+}
@@ -0,0 +1,40 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHubManagerShutdown(t *testing.T) {
+	// Arrange
+	store := &recordingStore{}
+	manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+	manager.updates = store
+	documentID := uuid.New()
+	hub := openHub(t, manager, documentID)
+
+	client := newTestClient(documentID)
+	register(t, hub, client)
+	hub.Updates <- clientUpdate{client: client, message: encodeSyncMessage(YjsUpdate, textInsert(7, "unsaved"))}
+	receive(t, client)
+
+	// Act
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, manager.Shutdown(ctx))
+
+	// Assert: pending updates are written before clients are sent away
+	assert.Len(t, store.saved(), 1)
+	_, open := <-client.Send
+	assert.False(t, open)
+	assert.Equal(t, restartCloseMessage, client.closeMessage)
+
+	_, err := manager.GetOrCreateHub(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, ErrShuttingDown)
+}
@@ -1,18 +1,33 @@
 package websocket
 
 import (
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
 )
 
-// Message type constants for Yjs protocol (y-protocols spec)
+// Message type constants for the y-websocket protocol (y-protocols spec)
 const (
-	YjsSyncStep1         = 0
-	YjsSyncStep2         = 1
-	YjsUpdate            = 2
-	MessageTypeAwareness = 101
+	MessageTypeSync      = 0
+	MessageTypeAwareness = 1
+	// MessageTypeQueryAwareness asks for the awareness states of the room;
+	// clients are sent them on joining, so it needs no answer.
+	MessageTypeQueryAwareness = 3
+	// MessageTypeControl carries server notices such as permission changes.
+	// It is not part of y-protocols and is never accepted from clients.
+	MessageTypeControl = 100
+)
+
+// Sync step constants carried inside MessageTypeSync frames (y-protocols/sync)
+const (
+	YjsSyncStep1 = 0
+	YjsSyncStep2 = 1
+	YjsUpdate    = 2
 )
 
 // ClientConnection represents active WebSocket connection
@@ -21,27 +36,165 @@ type ClientConnection struct {
 	UserID     uuid.UUID
 	UserName   string
 	DocumentID uuid.UUID
-	Conn       *websocket.Conn
-	Send       chan []byte
-	Done       chan struct{}
-	LastSeen   time.Time
-	// AwarenessID is the client id assigned by y-protocols/awareness
-	AwarenessID uint32
+	// GroupID is the group the document belonged to when the client joined.
+	GroupID  uuid.UUID
+	Conn     *websocket.Conn
+	Send     chan []byte
+	Done     chan struct{}
+	LastSeen time.Time
+	// AwarenessID is the y-protocols/awareness client id the connection
+	// announced first; it is bound once awarenessBound is set. The hub ignores
+	// it while the id belongs to a connection of another user.
+	AwarenessID    uint32
+	awarenessBound bool
+	// pendingAwareness is the latest stamped awareness state the hub has not
+	// picked up yet; newer states replace it.
+	pendingAwareness []byte
+	awarenessMu      sync.Mutex
+	// Role is the member role stamped into awareness states; for guests, the
+	// role granted by their share link.
+	// Role and CanEdit may change while the client is connected, so they are
+	// read through access once the client is registered.
+	Role string
 	// CanEdit controls whether the client is allowed to apply document updates.
-	CanEdit bool
+	CanEdit  bool
+	accessMu sync.RWMutex
+	// ResumeVersion is the stored version a reconnecting client last saw; updates
+	// persisted after it are replayed with its first SyncStep1. Owned by the
+	// read goroutine.
+	ResumeVersion int
+	// Guest marks share-link visitors, who are not users of the application.
+	// Their UserID only identifies the connection; their edits are attributed
+	// to domain.GuestUserUUID.
+	Guest bool
+	// ShareLinkID is the stored share link a guest joined through; uuid.Nil
+	// for members and for links that are not stored.
+	ShareLinkID uuid.UUID
+	// ExpiresAt is when the access of the client ends, such as the expiry of
+	// a guest's share link; zero for members.
+	ExpiresAt time.Time
+	// presenceSavedAt is when presence was last written; owned by the read goroutine.
+	presenceSavedAt time.Time
+	// outbox queues messages while Send is full; owned by the hub goroutine.
+	outbox outbox
+	// closeMessage is the close frame written once Send is closed; it is set
+	// before Send is closed. Empty means a normal close.
+	closeMessage []byte
+	// finalMessages are notices written after the last message of Send, before
+	// the close frame; they are set before Send is closed.
+	finalMessages [][]byte
+	// connectedAt is when the hub registered the client.
+	connectedAt time.Time
+}
+
+// access returns the current role of the client and whether it may edit.
+func (c *ClientConnection) access() (string, bool) {
+	c.accessMu.RLock()
+	defer c.accessMu.RUnlock()
+	return c.Role, c.CanEdit
+}
+
+// authorID is the user that the client's edits are attributed to.
+func (c *ClientConnection) authorID() uuid.UUID {
+	if c.Guest {
+		return domain.GuestUserUUID
+	}
+	return c.UserID
+}
+
+func (c *ClientConnection) setAccess(role string, canEdit bool) {
+	c.accessMu.Lock()
+	defer c.accessMu.Unlock()
+	c.Role = role
+	c.CanEdit = canEdit
+}
+
+// queueAwareness stores the latest awareness state of the client. It reports
+// false when an earlier state was still waiting for the hub and got replaced,
+// in which case the hub has already been notified.
+func (c *ClientConnection) queueAwareness(message []byte) bool {
+	c.awarenessMu.Lock()
+	defer c.awarenessMu.Unlock()
+	queued := c.pendingAwareness == nil
+	c.pendingAwareness = message
+	return queued
+}
+
+// takeAwareness returns and clears the pending awareness state.
+func (c *ClientConnection) takeAwareness() []byte {
+	c.awarenessMu.Lock()
+	defer c.awarenessMu.Unlock()
+	message := c.pendingAwareness
+	c.pendingAwareness = nil
+	return message
 }
 
 // DocumentHub manages all connections for single document
 type DocumentHub struct {
-	DocumentID  uuid.UUID
-	Clients     map[*ClientConnection]bool
-	YjsDoc      []byte
-	Broadcast   chan []byte
-	Register    chan *ClientConnection
-	Unregister  chan *ClientConnection
-	Done        chan struct{}
-	LastUpdated time.Time
-	Version     int
+	DocumentID uuid.UUID
+	Clients    map[*ClientConnection]bool
+	// YjsDoc holds the merged state of every update received by the hub.
+	YjsDoc    *yjs.Doc
+	Broadcast chan []byte
+	// Updates receives document updates from clients, to be versioned and stored.
+	Updates   chan clientUpdate
+	SyncStep1 chan syncRequest
+	// Remote receives messages relayed from other instances by the broker.
+	Remote chan []byte
+	// Awareness receives clients whose pending awareness state is ready.
+	Awareness chan *ClientConnection
+	// Tasks runs server-side operations on the hub goroutine.
+	Tasks      chan hubTask
+	Register   chan *ClientConnection
+	Unregister chan *ClientConnection
+	Done       chan struct{}
+	// stopped is closed once the hub goroutine saved the hub and returned.
+	stopped chan struct{}
+	// subscription identifies the broker subscription of the hub.
+	subscription uint64
+	LastUpdated  time.Time
+	Version      int
+	// persistedVersion is the version of the last stored update merged into
+	// the hub; snapshots are saved at it and clients resume from it.
+	persistedVersion int
+	// pendingUpdates are merged updates waiting to be handed to the writer.
+	pendingUpdates []repo.UpdateRecord
+	writer         *updateWriter
+	// savedContent is the text last written to documents.content.
+	savedContent string
+	// deleted is set once the document is gone; its state is no longer saved.
+	deleted bool
+	// awareness holds the latest awareness state per awareness client id.
+	awareness map[uint64]*awarenessState
+	// awarenessChanged lists the states to relay on the next flush, and whether
+	// they are published to peers.
+	awarenessChanged map[uint64]bool
+	// closeMessage is the close frame sent to the clients when the hub closes.
+	closeMessage []byte
+	openedAt     time.Time
+	// snapshotSize is the size of the snapshot the hub saved last.
+	snapshotSize int
+	traffic      trafficCounters
+	// maxSize caps the encoded document state; client updates that would
+	// outgrow it are refused.
+	maxSize int
+	// stateSize bounds the encoded document state from above: it is exact
+	// after a snapshot and grows by the size of every merged update.
+	stateSize int
+}
+
+// syncRequest carries a client's SyncStep1 state vector to the hub goroutine.
+type syncRequest struct {
+	client      *ClientConnection
+	stateVector []byte
+	// updates are persisted updates the client may have missed while away.
+	updates []repo.UpdateRecord
+}
+
+// hubTask is run on the hub goroutine; done is closed once it returns.
+type hubTask struct {
+	run  func(hub *DocumentHub)
+	done chan struct{}
 }
 
 // PersistenceRecord for database storage
@@ -0,0 +1,215 @@
+package websocket
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
+	"go.uber.org/zap"
+)
+
+const (
+	// updateBatchSize is how many buffered updates trigger a write, and the
+	// most a single insert carries.
+	updateBatchSize = 64
+	// updateFlushInterval bounds how long an update waits in the buffer.
+	updateFlushInterval = 500 * time.Millisecond
+	// updateWriteTimeout bounds a single batch insert.
+	updateWriteTimeout = 10 * time.Second
+	// updateWriteQueueSize is how many batches may wait for the writer.
+	updateWriteQueueSize = 16
+	// updateWriteAttempts is how often a batch is tried before the hub is
+	// closed, so that its clients reconnect and send their edits again.
+	updateWriteAttempts = 3
+	// updateRetryDelay is the pause before the first retry of a batch; it
+	// doubles with every retry.
+	updateRetryDelay = 500 * time.Millisecond
+)
+
+// updateStore persists batches of document updates and returns the version
+// assigned to the last update of a batch.
+type updateStore interface {
+	SaveUpdates(ctx context.Context, updates []repo.UpdateRecord) (int, error)
+}
+
+// clientUpdate is a document update sent by a client connection.
+type clientUpdate struct {
+	client  *ClientConnection
+	message []byte
+}
+
+// updateWriter writes the update batches of one hub in the background, so a
+// slow database does not stall the hub.
+type updateWriter struct {
+	batches chan []repo.UpdateRecord
+	done    chan struct{}
+	// stored is the version of the last update written.
+	stored atomic.Int64
+}
+
+// applyClientUpdate merges a document update sent by a client, queues it for
+// persistence and relays it.
+func (m *HubManager) applyClientUpdate(hub *DocumentHub, update clientUpdate) {
+	// Updates still in flight when the client lost edit access are refused.
+	if _, canEdit := update.client.access(); !canEdit {
+		return
+	}
+
+	msg, err := decodeMessage(update.message)
+	if err != nil {
+		return
+	}
+	if m.exceedsSizeLimit(hub, msg.Payload) {
+		m.countRejected(hub)
+		m.sendControl(hub, update.client, controlMessage{Type: controlDocumentTooLarge, Limit: hub.maxSize})
+		return
+	}
+	if !m.applyMessage(hub, msg) {
+		return
+	}
+	m.queueUpdate(hub, update.client.authorID(), msg.Payload)
+
+	m.publish(hub, update.message)
+	m.fanOut(hub, update.message)
+}
+
+// exceedsSizeLimit reports whether merging update would grow the document
+// state past the limit of the hub. The state is only encoded to measure it
+// when the running estimate crosses the limit.
+func (m *HubManager) exceedsSizeLimit(hub *DocumentHub, update []byte) bool {
+	if hub.stateSize+len(update) <= hub.maxSize {
+		return false
+	}
+	hub.stateSize = len(hub.YjsDoc.EncodeStateAsUpdate(nil))
+	return hub.stateSize+len(update) > hub.maxSize
+}
+
+func (m *HubManager) startWriter(hub *DocumentHub) {
+	if m.updates == nil {
+		return
+	}
+	hub.writer = &updateWriter{
+		batches: make(chan []repo.UpdateRecord, updateWriteQueueSize),
+		done:    make(chan struct{}),
+	}
+	go m.writeUpdates(hub, hub.writer)
+}
+
+// writeUpdates runs on the writer goroutine; it reads only the immutable
+// DocumentID of hub and its atomic counters. A batch that cannot be written
+// after a few attempts closes the hub instead of being lost silently: the
+// clients reconnect to a new hub and send the edits it misses again.
+func (m *HubManager) writeUpdates(hub *DocumentHub, writer *updateWriter) {
+	defer close(writer.done)
+
+	for batch := range writer.batches {
+		version, err := m.saveBatch(hub, batch)
+		if err != nil {
+			m.logger.Error(
+				"failed to persist updates; closing hub",
+				zap.String("document_id", hub.DocumentID.String()),
+				zap.Int("updates", len(batch)),
+				zap.Error(err),
+			)
+			m.closeHub(hub)
+			continue
+		}
+		writer.stored.Store(int64(version))
+	}
+}
+
+// saveBatch writes a batch of updates, retrying failed attempts.
+func (m *HubManager) saveBatch(hub *DocumentHub, batch []repo.UpdateRecord) (int, error) {
+	delay := updateRetryDelay
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), updateWriteTimeout)
+		version, err := m.updates.SaveUpdates(ctx, batch)
+		cancel()
+		if err == nil {
+			return version, nil
+		}
+
+		m.countPersistFailure(hub)
+		if attempt == updateWriteAttempts {
+			return 0, err
+		}
+		m.logger.Warn(
+			"failed to persist updates; retrying",
+			zap.String("document_id", hub.DocumentID.String()),
+			zap.Int("updates", len(batch)),
+			zap.Int("attempt", attempt),
+			zap.Error(err),
+		)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// persistedVersion returns the version of the last stored update merged into
+// the hub, counting the updates the writer stored since it was last asked.
+// The hub version never falls behind it.
+func (m *HubManager) persistedVersion(hub *DocumentHub) int {
+	if hub.writer != nil {
+		hub.persistedVersion = max(hub.persistedVersion, int(hub.writer.stored.Load()))
+	}
+	hub.Version = max(hub.Version, hub.persistedVersion)
+	return hub.persistedVersion
+}
+
+// queueUpdate buffers an update that was merged into the hub. The database
+// assigns its version when it is written. A full batch is handed to the writer
+// right away.
+func (m *HubManager) queueUpdate(hub *DocumentHub, userID uuid.UUID, update []byte) {
+	if hub.writer == nil {
+		return
+	}
+
+	hub.pendingUpdates = append(hub.pendingUpdates, repo.UpdateRecord{
+		DocumentID: hub.DocumentID,
+		YjsUpdate:  update,
+		UserID:     userID,
+		CreatedAt:  time.Now(),
+	})
+	if len(hub.pendingUpdates) >= updateBatchSize {
+		m.flushUpdates(hub)
+	}
+}
+
+// flushUpdates hands the buffered updates to the writer. Whatever the writer
+// has no room for stays buffered until the next flush.
+func (m *HubManager) flushUpdates(hub *DocumentHub) {
+	for hub.writer != nil && len(hub.pendingUpdates) > 0 {
+		batch := hub.pendingUpdates[:min(len(hub.pendingUpdates), updateBatchSize)]
+		select {
+		case hub.writer.batches <- batch:
+			hub.pendingUpdates = hub.pendingUpdates[len(batch):]
+		default:
+			return
+		}
+	}
+	hub.pendingUpdates = nil
+}
+
+// stopWriter writes the remaining updates of a closing hub and waits for the
+// writer to finish. Updates of deleted documents are discarded.
+func (m *HubManager) stopWriter(hub *DocumentHub) {
+	if hub.writer == nil {
+		return
+	}
+
+	if !hub.deleted {
+		for len(hub.pendingUpdates) > 0 {
+			batch := hub.pendingUpdates[:min(len(hub.pendingUpdates), updateBatchSize)]
+			hub.writer.batches <- batch
+			hub.pendingUpdates = hub.pendingUpdates[len(batch):]
+		}
+	}
+	hub.pendingUpdates = nil
+
+	close(hub.writer.batches)
+	<-hub.writer.done
+	m.persistedVersion(hub)
+	hub.writer = nil
+}
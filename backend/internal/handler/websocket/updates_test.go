@@ -0,0 +1,227 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
+	"go.uber.org/zap"
+)
+
+// recordingStore keeps the batches it saves and assigns them versions like
+// the database does. Its first failures calls fail.
+type recordingStore struct {
+	mu       sync.Mutex
+	batches  [][]repo.UpdateRecord
+	version  int
+	failures int
+	attempts int
+}
+
+func (s *recordingStore) SaveUpdates(_ context.Context, updates []repo.UpdateRecord) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.attempts <= s.failures {
+		return 0, errors.New("database unavailable")
+	}
+	s.batches = append(s.batches, append([]repo.UpdateRecord(nil), updates...))
+	s.version += len(updates)
+	return s.version, nil
+}
+
+func (s *recordingStore) saved() []repo.UpdateRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var updates []repo.UpdateRecord
+	for _, batch := range s.batches {
+		updates = append(updates, batch...)
+	}
+	return updates
+}
+
+func TestHubUpdatePersistence(t *testing.T) {
+	setup := func(t *testing.T) (*HubManager, *recordingStore, *DocumentHub, *ClientConnection) {
+		store := &recordingStore{}
+		manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+		manager.updates = store
+		documentID := uuid.New()
+		hub := openHub(t, manager, documentID)
+		t.Cleanup(func() { manager.CloseHub(documentID) })
+
+		client := newTestClient(documentID)
+		register(t, hub, client)
+		return manager, store, hub, client
+	}
+
+	t.Run("StoresUpdatesOfClients", func(t *testing.T) {
+		manager, store, hub, client := setup(t)
+
+		// Act
+		for i, text := range []string{"a", "b", "c"} {
+			hub.Updates <- clientUpdate{client: client, message: encodeSyncMessage(YjsUpdate, textInsert(uint64(i+1), text))}
+			receive(t, client)
+		}
+		assert.Empty(t, store.saved())
+		manager.CloseHub(hub.DocumentID)
+
+		// Assert: the closing hub drains its buffer
+		require.Eventually(t, func() bool { return len(store.saved()) == 3 }, time.Second, 10*time.Millisecond)
+		for i, update := range store.saved() {
+			assert.Equal(t, hub.DocumentID, update.DocumentID)
+			assert.Equal(t, client.UserID, update.UserID)
+			assert.Equal(t, textInsert(uint64(i+1), string(rune('a'+i))), update.YjsUpdate)
+		}
+	})
+
+	t.Run("HandshakeCarriesStoredVersion", func(t *testing.T) {
+		manager, store, hub, client := setup(t)
+		store.version = 41
+
+		// Act
+		hub.Updates <- clientUpdate{client: client, message: encodeSyncMessage(YjsUpdate, textInsert(7, "stored"))}
+		receive(t, client)
+		_, err := manager.PersistHub(context.Background(), hub.DocumentID)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return len(store.saved()) == 1 }, time.Second, 10*time.Millisecond)
+
+		require.Eventually(t, func() bool {
+			var version int
+			_, err := manager.runInHub(context.Background(), hub.DocumentID, func(hub *DocumentHub) {
+				version = manager.persistedVersion(hub)
+			})
+			return err == nil && version == 42
+		}, time.Second, 10*time.Millisecond)
+
+		// Assert: the version comes from the store, not from the hub
+		reader := newTestClient(hub.DocumentID)
+		hub.Register <- reader
+		receive(t, reader)
+		assert.Equal(t, 42, receiveControl(t, reader).Version)
+	})
+
+	t.Run("RetriesFailedBatches", func(t *testing.T) {
+		manager, store, hub, client := setup(t)
+		store.failures = 1
+
+		// Act
+		hub.Updates <- clientUpdate{client: client, message: encodeSyncMessage(YjsUpdate, textInsert(7, "retried"))}
+		receive(t, client)
+		manager.CloseHub(hub.DocumentID)
+
+		// Assert
+		require.Eventually(t, func() bool { return len(store.saved()) == 1 }, 2*time.Second, 10*time.Millisecond)
+		assert.Equal(t, textInsert(7, "retried"), store.saved()[0].YjsUpdate)
+	})
+
+	t.Run("ClosesTheHubWhenABatchCannotBeStored", func(t *testing.T) {
+		manager, store, hub, client := setup(t)
+		store.failures = updateWriteAttempts
+
+		// Act
+		hub.Updates <- clientUpdate{client: client, message: encodeSyncMessage(YjsUpdate, textInsert(7, "lost"))}
+		receive(t, client)
+		_, err := manager.PersistHub(context.Background(), hub.DocumentID)
+		require.NoError(t, err)
+
+		// Assert: the client is disconnected, to reconnect and send it again
+		select {
+		case <-hub.stopped:
+		case <-time.After(5 * time.Second):
+			t.Fatal("hub kept running")
+		}
+		assert.Empty(t, store.saved())
+	})
+
+	t.Run("WritesFullBatchesRightAway", func(t *testing.T) {
+		_, store, hub, client := setup(t)
+
+		// Act
+		for i := range updateBatchSize {
+			hub.Updates <- clientUpdate{client: client, message: encodeSyncMessage(YjsUpdate, textInsert(uint64(i+1), "x"))}
+		}
+
+		// Assert
+		require.Eventually(t, func() bool { return len(store.saved()) == updateBatchSize }, time.Second, 10*time.Millisecond)
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		assert.Len(t, store.batches, 1)
+	})
+
+	t.Run("RefusesUpdatesOfReadOnlyClients", func(t *testing.T) {
+		manager, store, hub, client := setup(t)
+		reader := newTestClient(hub.DocumentID)
+		register(t, hub, reader)
+		reader.setAccess("", false)
+
+		// Act
+		hub.Updates <- clientUpdate{client: reader, message: encodeSyncMessage(YjsUpdate, textInsert(7, "refused"))}
+		hub.Updates <- clientUpdate{client: client, message: encodeSyncMessage(YjsUpdate, textInsert(8, "kept"))}
+		update := receive(t, client)
+		manager.CloseHub(hub.DocumentID)
+
+		// Assert
+		assert.Equal(t, textInsert(8, "kept"), update.Payload)
+		require.Eventually(t, func() bool { return len(store.saved()) == 1 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, client.UserID, store.saved()[0].UserID)
+	})
+}
+
+func TestHubDocumentSizeLimit(t *testing.T) {
+	// Arrange: the limit fits the first update but not the second
+	first := textInsert(1, "hello")
+	manager := NewHubManager(zap.NewNop(), nil, nil, Config{MaxDocumentSize: len(first) + 4})
+	documentID := uuid.New()
+	hub := openHub(t, manager, documentID)
+	t.Cleanup(func() { manager.CloseHub(documentID) })
+
+	writer := newTestClient(documentID)
+	register(t, hub, writer)
+	hub.Updates <- clientUpdate{client: writer, message: encodeSyncMessage(YjsUpdate, first)}
+	receive(t, writer)
+
+	// Act
+	hub.Updates <- clientUpdate{client: writer, message: encodeSyncMessage(YjsUpdate, textInsert(2, "world"))}
+
+	// Assert: the writer is told why, and the state is unchanged
+	assert.Equal(t, controlMessage{Type: controlDocumentTooLarge, Limit: len(first) + 4}, receiveControl(t, writer))
+	state, version, ok, err := manager.EncodeState(context.Background(), documentID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, first, state)
+	assert.Equal(t, defaultHubVersion+1, version)
+
+	stats, err := manager.Stats(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, stats.Traffic.Rejected)
+}
+
+func TestHubGuestUpdateAttribution(t *testing.T) {
+	// Arrange
+	store := &recordingStore{}
+	manager := NewHubManager(zap.NewNop(), nil, nil, Config{})
+	manager.updates = store
+	documentID := uuid.New()
+	hub := openHub(t, manager, documentID)
+
+	guest := newTestClient(documentID)
+	guest.Guest = true
+	guest.Role = domain.RoleEditor
+	register(t, hub, guest)
+
+	// Act
+	hub.Updates <- clientUpdate{client: guest, message: encodeSyncMessage(YjsUpdate, textInsert(7, "guest"))}
+	receive(t, guest)
+	manager.CloseHub(documentID)
+
+	// Assert: the edit is stored under the share-link guest user
+	require.Eventually(t, func() bool { return len(store.saved()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, domain.GuestUserUUID, store.saved()[0].UserID)
+}
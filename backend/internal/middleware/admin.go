@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AdminMiddleware lets only the listed users through. It runs after
+// AuthMiddleware, which identifies the user.
+func AdminMiddleware(adminUUIDs []uuid.UUID) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userUUID, ok := c.Value("user_uid").(uuid.UUID)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user context missing"})
+			return
+		}
+
+		if !slices.Contains(adminUUIDs, userUUID) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			return
+		}
+
+		c.Next()
+	}
+}
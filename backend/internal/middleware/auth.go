@@ -86,6 +86,7 @@ func AuthMiddleware(userRepo userRepository, secretToken string) gin.HandlerFunc
 		}
 
 		c.Set("user_uid", user.UUID)
+		c.Set("user_login", user.Login)
 		c.Set("user_role", domain.RoleAuthor)
 
 		c.Next()
@@ -0,0 +1,339 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	notifyChannelPrefix  = "collab_"
+	notifyChunkSize      = 6000 // base64 characters, below the 8000 byte NOTIFY payload limit
+	notifyPublishTimeout = 5 * time.Second
+	notifyOutboxSize     = 1024
+	notifyPartialTTL     = time.Minute
+	listenerMinReconnect = 100 * time.Millisecond
+	listenerMaxReconnect = 10 * time.Second
+)
+
+// notifyEnvelope is the JSON payload of a single NOTIFY. Messages larger than
+// notifyChunkSize are split into several parts and reassembled by receivers.
+type notifyEnvelope struct {
+	Node  uuid.UUID `json:"node"`
+	ID    uint64    `json:"id"`
+	Part  int       `json:"part"`
+	Parts int       `json:"parts"`
+	Data  string    `json:"data"`
+}
+
+type notifySubscription struct {
+	id      uint64
+	deliver func(message []byte)
+	resync  func()
+}
+
+type notifyPartial struct {
+	parts    []string
+	received int
+	started  time.Time
+}
+
+type notifyOutgoing struct {
+	channel string
+	message []byte
+}
+
+// NotifyBroker relays collaboration messages between backend instances using
+// Postgres LISTEN/NOTIFY, with one channel per document.
+type NotifyBroker struct {
+	db       *sql.DB
+	listener *pq.Listener
+	logger   *zap.Logger
+	nodeID   uuid.UUID
+
+	mu            sync.Mutex
+	subscriptions map[string]notifySubscription
+	partials      map[string]*notifyPartial
+	nextID        uint64
+	// dropped holds the channels that lost a message to a full outbox; their
+	// subscribers resync once the outbox drains.
+	dropped map[string]struct{}
+	// listenMu orders changes of the subscriptions with the LISTEN and
+	// UNLISTEN commands that follow them.
+	listenMu           sync.Mutex
+	nextSubscriptionID uint64
+
+	outbox chan notifyOutgoing
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewNotifyBroker starts listening for collaboration notifications.
+func NewNotifyBroker(db *sql.DB, dsn string, logger *zap.Logger) *NotifyBroker {
+	b := &NotifyBroker{
+		db:            db,
+		logger:        logger,
+		nodeID:        uuid.New(),
+		subscriptions: make(map[string]notifySubscription),
+		partials:      make(map[string]*notifyPartial),
+		dropped:       make(map[string]struct{}),
+		outbox:        make(chan notifyOutgoing, notifyOutboxSize),
+		done:          make(chan struct{}),
+	}
+	b.listener = pq.NewListener(dsn, listenerMinReconnect, listenerMaxReconnect, b.handleListenerEvent)
+
+	b.wg.Add(2)
+	go b.receiveLoop()
+	go b.publishLoop()
+	return b
+}
+
+// Subscribe starts relaying messages of a document to deliver, replacing an
+// earlier subscription of the document, and returns the ID to unsubscribe
+// with. resync is called when the listener reconnects, since notifications may
+// have been missed, and after a message of the document was dropped.
+func (b *NotifyBroker) Subscribe(documentID uuid.UUID, deliver func(message []byte), resync func()) (uint64, error) {
+	channel := notifyChannel(documentID)
+
+	b.listenMu.Lock()
+	defer b.listenMu.Unlock()
+
+	b.mu.Lock()
+	b.nextSubscriptionID++
+	id := b.nextSubscriptionID
+	b.subscriptions[channel] = notifySubscription{id: id, deliver: deliver, resync: resync}
+	b.mu.Unlock()
+
+	if err := b.listener.Listen(channel); err != nil && !errors.Is(err, pq.ErrChannelAlreadyOpen) {
+		return id, errors.Join(domain.ErrInternal, fmt.Errorf("collaboration broker: subscribe: %w", err))
+	}
+	return id, nil
+}
+
+// Unsubscribe stops relaying messages of a document to the subscription with
+// the given ID. It does nothing once a newer subscription replaced it.
+func (b *NotifyBroker) Unsubscribe(documentID uuid.UUID, id uint64) error {
+	channel := notifyChannel(documentID)
+
+	b.listenMu.Lock()
+	defer b.listenMu.Unlock()
+
+	b.mu.Lock()
+	sub, ok := b.subscriptions[channel]
+	ok = ok && sub.id == id
+	if ok {
+		delete(b.subscriptions, channel)
+	}
+	b.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	if err := b.listener.Unlisten(channel); err != nil && !errors.Is(err, pq.ErrChannelNotOpen) {
+		return errors.Join(domain.ErrInternal, fmt.Errorf("collaboration broker: unsubscribe: %w", err))
+	}
+	return nil
+}
+
+// Publish queues a message for the other instances serving the document. It
+// never blocks: when the outbox is full the message is dropped and the
+// subscriber of the document is asked to resync once the outbox drains.
+func (b *NotifyBroker) Publish(documentID uuid.UUID, message []byte) {
+	channel := notifyChannel(documentID)
+	select {
+	case b.outbox <- notifyOutgoing{channel: channel, message: message}:
+	default:
+		b.logger.Warn("dropping collaboration notification; outbox full", zap.String("document_id", documentID.String()))
+		b.mu.Lock()
+		b.dropped[channel] = struct{}{}
+		b.mu.Unlock()
+	}
+}
+
+// Close stops the broker and its listener connection.
+func (b *NotifyBroker) Close() error {
+	close(b.done)
+	b.wg.Wait()
+	if err := b.listener.Close(); err != nil {
+		return fmt.Errorf("collaboration broker: close: %w", err)
+	}
+	return nil
+}
+
+func (b *NotifyBroker) publishLoop() {
+	defer b.wg.Done()
+	for {
+		select {
+		case out := <-b.outbox:
+			if err := b.publish(out.channel, out.message); err != nil {
+				b.logger.Warn("failed to publish collaboration notification", zap.String("channel", out.channel), zap.Error(err))
+			}
+			if len(b.outbox) == 0 {
+				b.resyncDropped()
+			}
+		case <-b.done:
+			return
+		}
+	}
+}
+
+func (b *NotifyBroker) publish(channel string, message []byte) error {
+	b.mu.Lock()
+	b.nextID++
+	id := b.nextID
+	b.mu.Unlock()
+
+	data := base64.StdEncoding.EncodeToString(message)
+	parts := max(1, (len(data)+notifyChunkSize-1)/notifyChunkSize)
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyPublishTimeout)
+	defer cancel()
+
+	for part := 0; part < parts; part++ {
+		chunk := data[part*notifyChunkSize : min(len(data), (part+1)*notifyChunkSize)]
+		payload, err := json.Marshal(notifyEnvelope{Node: b.nodeID, ID: id, Part: part, Parts: parts, Data: chunk})
+		if err != nil {
+			return fmt.Errorf("collaboration broker: marshal: %w", err)
+		}
+		if _, err := b.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, channel, string(payload)); err != nil {
+			return fmt.Errorf("collaboration broker: notify: %w", err)
+		}
+	}
+	return nil
+}
+
+func (b *NotifyBroker) receiveLoop() {
+	defer b.wg.Done()
+	for {
+		select {
+		case n := <-b.listener.Notify:
+			if n == nil {
+				// The listener reconnected and notifications may have been lost.
+				b.resyncAll()
+				continue
+			}
+			b.handleNotification(n)
+		case <-b.done:
+			return
+		}
+	}
+}
+
+func (b *NotifyBroker) handleNotification(n *pq.Notification) {
+	var env notifyEnvelope
+	if err := json.Unmarshal([]byte(n.Extra), &env); err != nil {
+		b.logger.Debug("ignoring malformed collaboration notification", zap.String("channel", n.Channel), zap.Error(err))
+		return
+	}
+	if env.Node == b.nodeID || env.Parts < 1 || env.Part < 0 || env.Part >= env.Parts {
+		return
+	}
+
+	b.mu.Lock()
+	sub, ok := b.subscriptions[n.Channel]
+	data, complete := b.collect(fmt.Sprintf("%s:%s:%d", n.Channel, env.Node, env.ID), env)
+	b.mu.Unlock()
+	if !ok || !complete {
+		return
+	}
+
+	message, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		b.logger.Debug("ignoring undecodable collaboration notification", zap.String("channel", n.Channel), zap.Error(err))
+		return
+	}
+	sub.deliver(message)
+}
+
+// collect stores a message part and returns the joined data once all parts
+// arrived. Callers must hold b.mu.
+func (b *NotifyBroker) collect(key string, env notifyEnvelope) (string, bool) {
+	if env.Parts == 1 {
+		return env.Data, true
+	}
+
+	now := time.Now()
+	for k, p := range b.partials {
+		if now.Sub(p.started) > notifyPartialTTL {
+			delete(b.partials, k)
+		}
+	}
+
+	p, ok := b.partials[key]
+	if !ok {
+		p = &notifyPartial{parts: make([]string, env.Parts), started: now}
+		b.partials[key] = p
+	}
+	if len(p.parts) != env.Parts || p.parts[env.Part] != "" {
+		return "", false
+	}
+	p.parts[env.Part] = env.Data
+	p.received++
+	if p.received < env.Parts {
+		return "", false
+	}
+
+	delete(b.partials, key)
+	return strings.Join(p.parts, ""), true
+}
+
+func (b *NotifyBroker) resyncAll() {
+	b.mu.Lock()
+	subs := make([]notifySubscription, 0, len(b.subscriptions))
+	for _, sub := range b.subscriptions {
+		subs = append(subs, sub)
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.resync()
+	}
+}
+
+// resyncDropped asks the subscribers of channels that dropped a message to
+// resync, now that their resync messages fit in the outbox.
+func (b *NotifyBroker) resyncDropped() {
+	b.mu.Lock()
+	subs := make([]notifySubscription, 0, len(b.dropped))
+	for channel := range b.dropped {
+		if sub, ok := b.subscriptions[channel]; ok {
+			subs = append(subs, sub)
+		}
+		delete(b.dropped, channel)
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.resync()
+	}
+}
+
+func (b *NotifyBroker) handleListenerEvent(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventDisconnected:
+		b.logger.Warn("collaboration listener disconnected", zap.Error(err))
+	case pq.ListenerEventConnectionAttemptFailed:
+		b.logger.Warn("collaboration listener connection attempt failed", zap.Error(err))
+	case pq.ListenerEventReconnected:
+		b.logger.Info("collaboration listener reconnected")
+	}
+}
+
+func notifyChannel(documentID uuid.UUID) string {
+	return notifyChannelPrefix + hexUUID(documentID)
+}
+
+func hexUUID(id uuid.UUID) string {
+	return fmt.Sprintf("%x", id[:])
+}
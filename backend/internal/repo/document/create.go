@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
 )
 
@@ -13,7 +14,7 @@ func (r *DocumentRepository) Create(ctx context.Context, groupUUID uuid.UUID, na
 	query := `
 		INSERT INTO documents (group_uuid, name, content) 
 		VALUES ($1, $2, $3) 
-		RETURNING uuid, group_uuid, name, content, created_at`
+		RETURNING uuid, group_uuid, name, content, created_at, tags`
 
 	var document domain.Document
 	err := r.db.QueryRowContext(ctx, query, groupUUID, name, content).Scan(
@@ -22,6 +23,7 @@ func (r *DocumentRepository) Create(ctx context.Context, groupUUID uuid.UUID, na
 		&document.Name,
 		&document.Content,
 		&document.CreatedAt,
+		pq.Array(&document.Tags),
 	)
 	if err != nil {
 		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: create: %w", err))
@@ -7,12 +7,13 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
 )
 
 func (r *DocumentRepository) GetByUUID(ctx context.Context, uuid uuid.UUID) (*domain.Document, error) {
 	query := `
-		SELECT uuid, group_uuid, name, content, created_at 
+		SELECT uuid, group_uuid, name, content, created_at, tags 
 		FROM documents 
 		WHERE uuid = $1`
 
@@ -23,6 +24,7 @@ func (r *DocumentRepository) GetByUUID(ctx context.Context, uuid uuid.UUID) (*do
 		&document.Name,
 		&document.Content,
 		&document.CreatedAt,
+		pq.Array(&document.Tags),
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -36,7 +38,7 @@ func (r *DocumentRepository) GetByUUID(ctx context.Context, uuid uuid.UUID) (*do
 
 func (r *DocumentRepository) GetAll(ctx context.Context) ([]*domain.Document, error) {
 	query := `
-		SELECT uuid, group_uuid, name, content, created_at 
+		SELECT uuid, group_uuid, name, content, created_at, tags 
 		FROM documents 
 		ORDER BY created_at DESC`
 
@@ -55,6 +57,7 @@ func (r *DocumentRepository) GetAll(ctx context.Context) ([]*domain.Document, er
 			&document.Name,
 			&document.Content,
 			&document.CreatedAt,
+			pq.Array(&document.Tags),
 		)
 		if err != nil {
 			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getAll scan: %w", err))
@@ -71,7 +74,7 @@ func (r *DocumentRepository) GetAll(ctx context.Context) ([]*domain.Document, er
 
 func (r *DocumentRepository) GetAllForUser(ctx context.Context, userUUID uuid.UUID) ([]*domain.Document, error) {
 	query := `
-		SELECT d.uuid, d.group_uuid, d.name, d.content, d.created_at
+		SELECT d.uuid, d.group_uuid, d.name, d.content, d.created_at, d.tags
 		FROM documents d
 		INNER JOIN user_groups ug ON ug.group_uuid = d.group_uuid
 		WHERE ug.user_uuid = $1
@@ -92,6 +95,7 @@ func (r *DocumentRepository) GetAllForUser(ctx context.Context, userUUID uuid.UU
 			&document.Name,
 			&document.Content,
 			&document.CreatedAt,
+			pq.Array(&document.Tags),
 		)
 		if err != nil {
 			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getAllForUser scan: %w", err))
@@ -105,3 +109,79 @@ func (r *DocumentRepository) GetAllForUser(ctx context.Context, userUUID uuid.UU
 
 	return documents, nil
 }
+
+// GetAllByGroup lists the documents of a group, newest first.
+func (r *DocumentRepository) GetAllByGroup(ctx context.Context, groupUUID uuid.UUID) ([]*domain.Document, error) {
+	query := `
+		SELECT uuid, group_uuid, name, content, created_at, tags
+		FROM documents
+		WHERE group_uuid = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, groupUUID)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getAllByGroup query: %w", err))
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var documents []*domain.Document
+	for rows.Next() {
+		var document domain.Document
+		err := rows.Scan(
+			&document.UUID,
+			&document.GroupUUID,
+			&document.Name,
+			&document.Content,
+			&document.CreatedAt,
+			pq.Array(&document.Tags),
+		)
+		if err != nil {
+			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getAllByGroup scan: %w", err))
+		}
+		documents = append(documents, &document)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getAllByGroup rows err: %w", err))
+	}
+
+	return documents, nil
+}
+
+// GetAllByGroupTag lists the documents of a group carrying tag, newest first.
+func (r *DocumentRepository) GetAllByGroupTag(ctx context.Context, groupUUID uuid.UUID, tag string) ([]*domain.Document, error) {
+	query := `
+		SELECT uuid, group_uuid, name, content, created_at, tags
+		FROM documents
+		WHERE group_uuid = $1 AND $2 = ANY(tags)
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, groupUUID, tag)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getAllByGroupTag query: %w", err))
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var documents []*domain.Document
+	for rows.Next() {
+		var document domain.Document
+		err := rows.Scan(
+			&document.UUID,
+			&document.GroupUUID,
+			&document.Name,
+			&document.Content,
+			&document.CreatedAt,
+			pq.Array(&document.Tags),
+		)
+		if err != nil {
+			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getAllByGroupTag scan: %w", err))
+		}
+		documents = append(documents, &document)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getAllByGroupTag rows err: %w", err))
+	}
+
+	return documents, nil
+}
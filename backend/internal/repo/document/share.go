@@ -0,0 +1,265 @@
+package document
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+)
+
+const shareLinkColumns = `uuid, document_uuid, created_by, role, expires_at, max_uses, uses, revoked, created_at, password_hash,
+	group_uuid, tag`
+
+// CreateShareLink stores a share link of a document or, when GroupUUID is
+// set, of a group, and returns it with its UUID.
+func (r *DocumentRepository) CreateShareLink(ctx context.Context, link *domain.ShareLink) (*domain.ShareLink, error) {
+	query := `
+		INSERT INTO document_share_links (document_uuid, group_uuid, tag, created_by, role, expires_at, max_uses, password_hash)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, NULLIF($8, ''))
+		RETURNING uuid, created_at`
+
+	created := *link
+	err := r.db.QueryRowContext(ctx, query,
+		nullUUID(link.DocumentUUID),
+		nullUUID(link.GroupUUID),
+		link.Tag,
+		nullUUID(link.CreatedBy),
+		link.Role,
+		link.ExpiresAt,
+		link.MaxUses,
+		link.PasswordHash,
+	).Scan(&created.UUID, &created.CreatedAt)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: createShareLink: %w", err))
+	}
+
+	return &created, nil
+}
+
+// GetShareLink returns a share link, or nil when there is none.
+func (r *DocumentRepository) GetShareLink(ctx context.Context, linkUUID uuid.UUID) (*domain.ShareLink, error) {
+	query := `SELECT ` + shareLinkColumns + ` FROM document_share_links WHERE uuid = $1`
+
+	link, err := scanShareLink(r.db.QueryRowContext(ctx, query, linkUUID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getShareLink: %w", err))
+	}
+
+	return link, nil
+}
+
+// GetShareLinks lists the share links of a document, newest first.
+func (r *DocumentRepository) GetShareLinks(ctx context.Context, documentUUID uuid.UUID) ([]*domain.ShareLink, error) {
+	query := `SELECT ` + shareLinkColumns + `
+		FROM document_share_links
+		WHERE document_uuid = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, documentUUID)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getShareLinks query: %w", err))
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var links []*domain.ShareLink
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getShareLinks scan: %w", err))
+		}
+		links = append(links, link)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getShareLinks rows: %w", err))
+	}
+
+	return links, nil
+}
+
+// GetGroupShareLinks lists the share links of a group, newest first.
+func (r *DocumentRepository) GetGroupShareLinks(ctx context.Context, groupUUID uuid.UUID) ([]*domain.ShareLink, error) {
+	query := `SELECT ` + shareLinkColumns + `
+		FROM document_share_links
+		WHERE group_uuid = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, groupUUID)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getGroupShareLinks query: %w", err))
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var links []*domain.ShareLink
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getGroupShareLinks scan: %w", err))
+		}
+		links = append(links, link)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getGroupShareLinks rows: %w", err))
+	}
+
+	return links, nil
+}
+
+// RevokeShareLink marks a share link of a document as revoked. It reports
+// false when the document has no such link.
+func (r *DocumentRepository) RevokeShareLink(ctx context.Context, documentUUID, linkUUID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE document_share_links
+		SET revoked = TRUE
+		WHERE document_uuid = $1 AND uuid = $2`
+
+	result, err := r.db.ExecContext(ctx, query, documentUUID, linkUUID)
+	if err != nil {
+		return false, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: revokeShareLink: %w", err))
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: revokeShareLink rows: %w", err))
+	}
+
+	return rows > 0, nil
+}
+
+// RevokeGroupShareLink marks a share link of a group as revoked. It reports
+// false when the group has no such link.
+func (r *DocumentRepository) RevokeGroupShareLink(ctx context.Context, groupUUID, linkUUID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE document_share_links
+		SET revoked = TRUE
+		WHERE group_uuid = $1 AND uuid = $2`
+
+	result, err := r.db.ExecContext(ctx, query, groupUUID, linkUUID)
+	if err != nil {
+		return false, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: revokeGroupShareLink: %w", err))
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: revokeGroupShareLink rows: %w", err))
+	}
+
+	return rows > 0, nil
+}
+
+// UseShareLink counts a use of a share link. It reports false, without
+// counting it, when the link is revoked or has no uses left.
+func (r *DocumentRepository) UseShareLink(ctx context.Context, linkUUID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE document_share_links
+		SET uses = uses + 1
+		WHERE uuid = $1 AND NOT revoked AND (max_uses IS NULL OR uses < max_uses)`
+
+	result, err := r.db.ExecContext(ctx, query, linkUUID)
+	if err != nil {
+		return false, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: useShareLink: %w", err))
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: useShareLink rows: %w", err))
+	}
+
+	return rows > 0, nil
+}
+
+// AddUnlockAttempt counts an attempt to unlock a share link with its password
+// and returns how many attempts were counted since windowStart. Attempts
+// counted before windowStart are forgotten.
+func (r *DocumentRepository) AddUnlockAttempt(ctx context.Context, linkUUID uuid.UUID, windowStart time.Time) (int, error) {
+	query := `
+		UPDATE document_share_links
+		SET failed_unlocks = CASE
+				WHEN unlock_window_start IS NULL OR unlock_window_start < $2 THEN 1
+				ELSE failed_unlocks + 1
+			END,
+			unlock_window_start = CASE
+				WHEN unlock_window_start IS NULL OR unlock_window_start < $2 THEN NOW()
+				ELSE unlock_window_start
+			END
+		WHERE uuid = $1
+		RETURNING failed_unlocks`
+
+	var attempts int
+	err := r.db.QueryRowContext(ctx, query, linkUUID, windowStart).Scan(&attempts)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, domain.ErrShareLinkNotFound
+		}
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: addUnlockAttempt: %w", err))
+	}
+
+	return attempts, nil
+}
+
+// ForgetUnlockAttempt takes back an attempt counted by AddUnlockAttempt, so
+// that only wrong passwords count against a share link.
+func (r *DocumentRepository) ForgetUnlockAttempt(ctx context.Context, linkUUID uuid.UUID) error {
+	query := `
+		UPDATE document_share_links
+		SET failed_unlocks = GREATEST(failed_unlocks - 1, 0)
+		WHERE uuid = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, linkUUID); err != nil {
+		return errors.Join(domain.ErrInternal, fmt.Errorf("document repository: forgetUnlockAttempt: %w", err))
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanShareLink(row rowScanner) (*domain.ShareLink, error) {
+	var link domain.ShareLink
+	var documentUUID, groupUUID, createdBy uuid.NullUUID
+	var maxUses sql.NullInt32
+	var passwordHash, tag sql.NullString
+	err := row.Scan(
+		&link.UUID,
+		&documentUUID,
+		&createdBy,
+		&link.Role,
+		&link.ExpiresAt,
+		&maxUses,
+		&link.Uses,
+		&link.Revoked,
+		&link.CreatedAt,
+		&passwordHash,
+		&groupUUID,
+		&tag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	link.DocumentUUID = documentUUID.UUID
+	link.GroupUUID = groupUUID.UUID
+	link.Tag = tag.String
+	link.CreatedBy = createdBy.UUID
+	link.PasswordHash = passwordHash.String
+	if maxUses.Valid {
+		uses := int(maxUses.Int32)
+		link.MaxUses = &uses
+	}
+
+	return &link, nil
+}
+
+// nullUUID stores uuid.Nil as NULL.
+func nullUUID(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: id != uuid.Nil}
+}
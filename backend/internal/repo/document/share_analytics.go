@@ -0,0 +1,107 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+)
+
+// CreateShareLinkAccess records a use of a share link.
+func (r *DocumentRepository) CreateShareLinkAccess(ctx context.Context, access *domain.ShareLinkAccess) error {
+	query := `
+		INSERT INTO share_link_accesses (
+			document_uuid, link_uuid, signature_hash, ip_hash, user_agent_family, channel, accessed_at
+		)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)`
+
+	linkUUID := uuid.NullUUID{}
+	if access.LinkUUID != uuid.Nil {
+		linkUUID = uuid.NullUUID{UUID: access.LinkUUID, Valid: true}
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		access.DocumentUUID,
+		linkUUID,
+		access.SignatureHash,
+		access.IPHash,
+		access.UserAgentFamily,
+		access.Channel,
+		access.AccessedAt,
+	)
+	if err != nil {
+		return errors.Join(domain.ErrInternal, fmt.Errorf("document repository: createShareLinkAccess: %w", err))
+	}
+
+	return nil
+}
+
+// GetShareLinkAnalytics counts the uses of a document's share links since a
+// time, in total and per UTC day. Days without uses are left out.
+func (r *DocumentRepository) GetShareLinkAnalytics(ctx context.Context, documentUUID uuid.UUID, since time.Time) (*domain.ShareLinkAnalytics, error) {
+	analytics := &domain.ShareLinkAnalytics{From: since}
+
+	totalQuery := `
+		SELECT COUNT(*), COUNT(DISTINCT ip_hash)
+		FROM share_link_accesses
+		WHERE document_uuid = $1 AND accessed_at >= $2`
+
+	err := r.db.QueryRowContext(ctx, totalQuery, documentUUID, since).Scan(&analytics.Accesses, &analytics.UniqueVisitors)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getShareLinkAnalytics totals: %w", err))
+	}
+
+	dailyQuery := `
+		SELECT
+			date_trunc('day', accessed_at AT TIME ZONE 'UTC') AS day,
+			COUNT(*),
+			COUNT(DISTINCT ip_hash),
+			COUNT(*) FILTER (WHERE channel = $3)
+		FROM share_link_accesses
+		WHERE document_uuid = $1 AND accessed_at >= $2
+		GROUP BY day
+		ORDER BY day`
+
+	rows, err := r.db.QueryContext(ctx, dailyQuery, documentUUID, since, domain.ShareChannelWebSocket)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getShareLinkAnalytics query: %w", err))
+	}
+	defer rows.Close() //nolint:errcheck
+
+	for rows.Next() {
+		var day time.Time
+		var daily domain.ShareLinkDailyAccesses
+		if err := rows.Scan(&day, &daily.Accesses, &daily.UniqueVisitors, &daily.WebSocket); err != nil {
+			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getShareLinkAnalytics scan: %w", err))
+		}
+		daily.Day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+		analytics.Days = append(analytics.Days, daily)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getShareLinkAnalytics rows: %w", err))
+	}
+
+	return analytics, nil
+}
+
+// DeleteShareLinkAccesses deletes the share link accesses recorded before a
+// time and returns how many were deleted.
+func (r *DocumentRepository) DeleteShareLinkAccesses(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM share_link_accesses WHERE accessed_at < $1`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: deleteShareLinkAccesses: %w", err))
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: deleteShareLinkAccesses rows: %w", err))
+	}
+
+	return deleted, nil
+}
@@ -7,23 +7,33 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
 )
 
-func (r *DocumentRepository) Update(ctx context.Context, uuid uuid.UUID, name, content string) (*domain.Document, error) {
+// Update renames a document and replaces its content and tags; a nil content
+// or nil tags keep the current ones.
+func (r *DocumentRepository) Update(
+	ctx context.Context,
+	uuid uuid.UUID,
+	name string,
+	content *string,
+	tags []string,
+) (*domain.Document, error) {
 	query := `
 		UPDATE documents 
-		SET name = $1, content = $2 
-		WHERE uuid = $3 
-		RETURNING uuid, group_uuid, name, content, created_at`
+		SET name = $1, content = COALESCE($2, content), tags = COALESCE($3, tags) 
+		WHERE uuid = $4 
+		RETURNING uuid, group_uuid, name, content, created_at, tags`
 
 	var document domain.Document
-	err := r.db.QueryRowContext(ctx, query, name, content, uuid).Scan(
+	err := r.db.QueryRowContext(ctx, query, name, content, pq.Array(tags), uuid).Scan(
 		&document.UUID,
 		&document.GroupUUID,
 		&document.Name,
 		&document.Content,
 		&document.CreatedAt,
+		pq.Array(&document.Tags),
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -0,0 +1,116 @@
+package document
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+)
+
+// CreateVersion stores a named checkpoint. It returns nil when the document
+// already has a checkpoint with that name.
+func (r *DocumentRepository) CreateVersion(ctx context.Context, version *domain.DocumentVersion) (*domain.DocumentVersion, error) {
+	query := `
+		INSERT INTO document_versions (document_uuid, name, yjs_snapshot, content, version, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (document_uuid, name) DO NOTHING
+		RETURNING uuid, created_at`
+
+	createdBy := uuid.NullUUID{}
+	if version.CreatedBy != uuid.Nil {
+		createdBy = uuid.NullUUID{UUID: version.CreatedBy, Valid: true}
+	}
+
+	created := *version
+	err := r.db.QueryRowContext(ctx, query,
+		version.DocumentUUID,
+		version.Name,
+		version.YjsSnapshot,
+		version.Content,
+		version.Version,
+		createdBy,
+	).Scan(&created.UUID, &created.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: createVersion: %w", err))
+	}
+
+	return &created, nil
+}
+
+// GetVersions lists the checkpoints of a document without their snapshots,
+// newest first.
+func (r *DocumentRepository) GetVersions(ctx context.Context, documentUUID uuid.UUID) ([]*domain.DocumentVersion, error) {
+	query := `
+		SELECT uuid, document_uuid, name, content, version, created_by, created_at
+		FROM document_versions
+		WHERE document_uuid = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, documentUUID)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getVersions query: %w", err))
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var versions []*domain.DocumentVersion
+	for rows.Next() {
+		var version domain.DocumentVersion
+		var createdBy uuid.NullUUID
+		err := rows.Scan(
+			&version.UUID,
+			&version.DocumentUUID,
+			&version.Name,
+			&version.Content,
+			&version.Version,
+			&createdBy,
+			&version.CreatedAt,
+		)
+		if err != nil {
+			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getVersions scan: %w", err))
+		}
+		version.CreatedBy = createdBy.UUID
+		versions = append(versions, &version)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getVersions rows: %w", err))
+	}
+
+	return versions, nil
+}
+
+// GetVersion returns a checkpoint of a document, or nil when there is none.
+func (r *DocumentRepository) GetVersion(ctx context.Context, documentUUID, versionUUID uuid.UUID) (*domain.DocumentVersion, error) {
+	query := `
+		SELECT uuid, document_uuid, name, content, yjs_snapshot, version, created_by, created_at
+		FROM document_versions
+		WHERE document_uuid = $1 AND uuid = $2`
+
+	var version domain.DocumentVersion
+	var createdBy uuid.NullUUID
+	err := r.db.QueryRowContext(ctx, query, documentUUID, versionUUID).Scan(
+		&version.UUID,
+		&version.DocumentUUID,
+		&version.Name,
+		&version.Content,
+		&version.YjsSnapshot,
+		&version.Version,
+		&createdBy,
+		&version.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document repository: getVersion: %w", err))
+	}
+	version.CreatedBy = createdBy.UUID
+
+	return &version, nil
+}
@@ -0,0 +1,215 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+)
+
+// ListCompactionCandidates returns documents whose snapshot is newer than some
+// of their stored updates that have not been folded into it yet.
+func (p *DocumentPersistence) ListCompactionCandidates(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT s.document_id
+		FROM document_snapshots s
+		WHERE EXISTS (
+			SELECT 1 FROM document_updates u
+			WHERE u.document_id = s.document_id
+			  AND u.version > s.compacted_version
+			  AND u.version <= s.version
+		)
+		ORDER BY s.updated_at ASC
+		LIMIT $1
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: listCompactionCandidates: %w", err))
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var documentIDs []uuid.UUID
+	for rows.Next() {
+		var documentID uuid.UUID
+		if err = rows.Scan(&documentID); err != nil {
+			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: listCompactionCandidates scan: %w", err))
+		}
+		documentIDs = append(documentIDs, documentID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: listCompactionCandidates rows: %w", err))
+	}
+
+	return documentIDs, nil
+}
+
+// SaveCompactedSnapshot stores a snapshot with updates folded into it and records
+// the compacted version. It reports false without writing when the snapshot
+// version changed since it was loaded.
+func (p *DocumentPersistence) SaveCompactedSnapshot(
+	ctx context.Context,
+	documentID uuid.UUID,
+	yjsSnapshot []byte,
+	snapshotVersion int,
+	compactedVersion int,
+) (bool, error) {
+	query := `
+		UPDATE document_snapshots
+		SET yjs_snapshot = $2,
+		    compacted_version = $4,
+		    compacted_at = NOW()
+		WHERE document_id = $1 AND version = $3
+	`
+
+	result, err := p.db.ExecContext(ctx, query, documentID, yjsSnapshot, snapshotVersion, compactedVersion)
+	if err != nil {
+		return false, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: saveCompactedSnapshot: %w", err))
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: saveCompactedSnapshot rows: %w", err))
+	}
+
+	return affected > 0, nil
+}
+
+// ListPrunableDocuments returns documents with updates that were folded into
+// their snapshot and are older than the retention period.
+func (p *DocumentPersistence) ListPrunableDocuments(ctx context.Context, retention time.Duration, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT s.document_id
+		FROM document_snapshots s
+		WHERE EXISTS (
+			SELECT 1 FROM document_updates u
+			WHERE u.document_id = s.document_id
+			  AND u.version <= s.compacted_version
+			  AND u.created_at < NOW() - make_interval(secs => $1)
+		)
+		LIMIT $2
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, retention.Seconds(), limit)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: listPrunableDocuments: %w", err))
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var documentIDs []uuid.UUID
+	for rows.Next() {
+		var documentID uuid.UUID
+		if err = rows.Scan(&documentID); err != nil {
+			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: listPrunableDocuments scan: %w", err))
+		}
+		documentIDs = append(documentIDs, documentID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: listPrunableDocuments rows: %w", err))
+	}
+
+	return documentIDs, nil
+}
+
+// GetPrunableUpdates fetches the folded updates of a document that are older
+// than the retention period.
+func (p *DocumentPersistence) GetPrunableUpdates(ctx context.Context, documentID uuid.UUID, retention time.Duration) ([]UpdateRecord, error) {
+	query := `
+		SELECT u.id, u.document_id, u.yjs_update, u.user_id, u.version, u.created_at
+		FROM document_updates u
+		JOIN document_snapshots s ON s.document_id = u.document_id
+		WHERE u.document_id = $1
+		  AND u.version <= s.compacted_version
+		  AND u.created_at < NOW() - make_interval(secs => $2)
+		ORDER BY u.id ASC
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, documentID, retention.Seconds())
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getPrunableUpdates: %w", err))
+	}
+
+	updates, err := scanUpdates(rows)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getPrunableUpdates: %w", err))
+	}
+
+	return updates, nil
+}
+
+// PruneUpdates deletes updates of a document and stores their merged state as
+// the new history base, so versions after baseVersion can still be rebuilt.
+func (p *DocumentPersistence) PruneUpdates(
+	ctx context.Context,
+	documentID uuid.UUID,
+	historyBase []byte,
+	baseVersion int,
+	updateIDs []int64,
+) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: pruneUpdates: begin: %w", err))
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE document_snapshots
+		SET history_base = $2,
+		    history_base_version = GREATEST(history_base_version, $3)
+		WHERE document_id = $1
+	`, documentID, historyBase, baseVersion)
+	if err != nil {
+		return errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: pruneUpdates: base: %w", err))
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM document_updates
+		WHERE document_id = $1 AND id = ANY($2)
+	`, documentID, pq.Array(updateIDs))
+	if err != nil {
+		return errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: pruneUpdates: delete: %w", err))
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: pruneUpdates: commit: %w", err))
+	}
+
+	return nil
+}
+
+// ArchiveCompactedUpdates moves updates that were folded into their snapshot and
+// are older than the retention period into document_updates_archive.
+func (p *DocumentPersistence) ArchiveCompactedUpdates(ctx context.Context, retention time.Duration) (int64, error) {
+	query := `
+		WITH moved AS (
+			DELETE FROM document_updates u
+			USING document_snapshots s
+			WHERE u.document_id = s.document_id
+			  AND u.version <= s.compacted_version
+			  AND u.created_at < NOW() - make_interval(secs => $1)
+			RETURNING u.id, u.document_id, u.yjs_update, u.user_id, u.version, u.created_at
+		)
+		INSERT INTO document_updates_archive (id, document_id, yjs_update, user_id, version, created_at, archived_at)
+		SELECT id, document_id, yjs_update, user_id, version, created_at, NOW()
+		FROM moved
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	result, err := p.db.ExecContext(ctx, query, retention.Seconds())
+	if err != nil {
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: archiveCompactedUpdates: %w", err))
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: archiveCompactedUpdates rows: %w", err))
+	}
+
+	return affected, nil
+}
@@ -0,0 +1,131 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+)
+
+// UpdateSummary describes a stored update without its payload.
+type UpdateSummary struct {
+	ID        int64
+	UserID    uuid.UUID
+	UserLogin string
+	Version   int
+	Size      int
+	CreatedAt time.Time
+}
+
+// storedUpdates covers live and archived updates of a document.
+const storedUpdates = `(
+	SELECT id, document_id, yjs_update, user_id, version, created_at FROM document_updates
+	UNION ALL
+	SELECT id, document_id, yjs_update, user_id, version, created_at FROM document_updates_archive
+)`
+
+// LoadHistoryBase returns the merged state of pruned updates and the highest
+// version folded into it. It returns nil and zero when nothing was pruned.
+func (p *DocumentPersistence) LoadHistoryBase(ctx context.Context, documentID uuid.UUID) ([]byte, int, error) {
+	query := `
+		SELECT history_base, history_base_version
+		FROM document_snapshots
+		WHERE document_id = $1
+	`
+
+	var base []byte
+	var version int
+	err := p.db.QueryRowContext(ctx, query, documentID).Scan(&base, &version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, 0, nil
+		}
+		return nil, 0, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: loadHistoryBase: %w", err))
+	}
+
+	return base, version, nil
+}
+
+// GetUpdateHistory lists live and archived updates of a document in the order
+// they were stored.
+func (p *DocumentPersistence) GetUpdateHistory(ctx context.Context, documentID uuid.UUID) ([]UpdateSummary, error) {
+	query := `
+		SELECT u.id, u.user_id, COALESCE(us.login, ''), u.version, octet_length(u.yjs_update), u.created_at
+		FROM ` + storedUpdates + ` u
+		LEFT JOIN users us ON us.uuid = u.user_id
+		WHERE u.document_id = $1
+		ORDER BY u.created_at ASC, u.id ASC
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, documentID)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getUpdateHistory: %w", err))
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var summaries []UpdateSummary
+	for rows.Next() {
+		var summary UpdateSummary
+		err = rows.Scan(
+			&summary.ID,
+			&summary.UserID,
+			&summary.UserLogin,
+			&summary.Version,
+			&summary.Size,
+			&summary.CreatedAt,
+		)
+		if err != nil {
+			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getUpdateHistory scan: %w", err))
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getUpdateHistory rows: %w", err))
+	}
+
+	return summaries, nil
+}
+
+// GetUpdatesUpTo fetches live and archived updates up to a version (inclusive).
+func (p *DocumentPersistence) GetUpdatesUpTo(ctx context.Context, documentID uuid.UUID, version int) ([]UpdateRecord, error) {
+	query := `
+		SELECT u.id, u.document_id, u.yjs_update, u.user_id, u.version, u.created_at
+		FROM ` + storedUpdates + ` u
+		WHERE u.document_id = $1 AND u.version <= $2
+		ORDER BY u.id ASC
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, documentID, version)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getUpdatesUpTo: %w", err))
+	}
+
+	updates, err := scanUpdates(rows)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getUpdatesUpTo: %w", err))
+	}
+
+	return updates, nil
+}
+
+// GetVersionAt returns the highest update version stored at or before the given
+// time, or zero when there is none.
+func (p *DocumentPersistence) GetVersionAt(ctx context.Context, documentID uuid.UUID, at time.Time) (int, error) {
+	query := `
+		SELECT COALESCE(MAX(u.version), 0)
+		FROM ` + storedUpdates + ` u
+		WHERE u.document_id = $1 AND u.created_at <= $2
+	`
+
+	var version int
+	if err := p.db.QueryRowContext(ctx, query, documentID, at).Scan(&version); err != nil {
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getVersionAt: %w", err))
+	}
+
+	return version, nil
+}
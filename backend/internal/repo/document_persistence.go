@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +19,8 @@ type SnapshotRecord struct {
 	Version        int
 	LastModifiedBy uuid.UUID
 	LastModified   time.Time
+	// CompactedVersion is the highest update version folded into the snapshot.
+	CompactedVersion int
 }
 
 // UpdateRecord represents an incremental CRDT update row.
@@ -35,10 +38,15 @@ type PresenceRecord struct {
 	ID             int64
 	DocumentID     uuid.UUID
 	UserID         uuid.UUID
+	UserLogin      string
 	CursorPosition *int
 	LastSeen       time.Time
 }
 
+// presenceTTL is how long a presence row counts as active without being
+// refreshed; connected clients refresh it well within this period.
+const presenceTTL = 2 * time.Minute
+
 // DocumentPersistence stores and retrieves collaborative state.
 type DocumentPersistence struct {
 	db *sql.DB
@@ -49,7 +57,9 @@ func NewDocumentPersistence(db *sql.DB) *DocumentPersistence {
 	return &DocumentPersistence{db: db}
 }
 
-// SaveSnapshot upserts the latest snapshot for a document.
+// SaveSnapshot upserts the latest snapshot for a document. A stored snapshot
+// is only replaced by one at a later version, so that an instance that fell
+// behind does not overwrite the state saved by another.
 func (p *DocumentPersistence) SaveSnapshot(
 	ctx context.Context,
 	documentID uuid.UUID,
@@ -65,6 +75,7 @@ func (p *DocumentPersistence) SaveSnapshot(
 		    version = EXCLUDED.version,
 		    modified_by = EXCLUDED.modified_by,
 		    updated_at = NOW()
+		WHERE document_snapshots.version < EXCLUDED.version
 	`
 
 	modifiedByUUID := uuid.NullUUID{}
@@ -80,10 +91,22 @@ func (p *DocumentPersistence) SaveSnapshot(
 	return nil
 }
 
+// SaveContent writes the text of the collaborative state to documents.content
+// so that REST consumers read what the editors see.
+func (p *DocumentPersistence) SaveContent(ctx context.Context, documentID uuid.UUID, content string) error {
+	query := `UPDATE documents SET content = $2 WHERE uuid = $1`
+
+	if _, err := p.db.ExecContext(ctx, query, documentID, content); err != nil {
+		return errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: saveContent: %w", err))
+	}
+
+	return nil
+}
+
 // LoadSnapshot retrieves the last persisted snapshot for a document.
 func (p *DocumentPersistence) LoadSnapshot(ctx context.Context, documentID uuid.UUID) (*SnapshotRecord, error) {
 	query := `
-		SELECT document_id, yjs_snapshot, version, modified_by, updated_at
+		SELECT document_id, yjs_snapshot, version, modified_by, updated_at, compacted_version
 		FROM document_snapshots
 		WHERE document_id = $1
 	`
@@ -96,6 +119,7 @@ func (p *DocumentPersistence) LoadSnapshot(ctx context.Context, documentID uuid.
 		&record.Version,
 		&modifiedBy,
 		&record.LastModified,
+		&record.CompactedVersion,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -111,19 +135,137 @@ func (p *DocumentPersistence) LoadSnapshot(ctx context.Context, documentID uuid.
 	return &record, nil
 }
 
-// SaveUpdate stores a CRDT incremental update for auditing or recovery.
-func (p *DocumentPersistence) SaveUpdate(ctx context.Context, documentID, userID uuid.UUID, yjsUpdate []byte, version int) error {
+// GetMaxDocumentSize returns the state size limit set for the group of a
+// document, or zero when the group uses the default.
+func (p *DocumentPersistence) GetMaxDocumentSize(ctx context.Context, documentID uuid.UUID) (int, error) {
 	query := `
-		INSERT INTO document_updates (document_id, yjs_update, user_id, version, created_at)
-		VALUES ($1, $2, $3, $4, NOW())
+		SELECT g.max_document_size
+		FROM documents d
+		INNER JOIN groups g ON g.uuid = d.group_uuid
+		WHERE d.uuid = $1
 	`
 
-	_, err := p.db.ExecContext(ctx, query, documentID, yjsUpdate, userID, version)
+	var size sql.NullInt32
+	err := p.db.QueryRowContext(ctx, query, documentID).Scan(&size)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getMaxDocumentSize: %w", err))
+	}
+
+	return int(size.Int32), nil
+}
+
+// SaveUpdate stores a CRDT incremental update made outside of a live room as
+// the next version of the document. It reports false, storing nothing, when an
+// update at version or later was stored meanwhile.
+func (p *DocumentPersistence) SaveUpdate(ctx context.Context, documentID, userID uuid.UUID, yjsUpdate []byte, version int) (bool, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
 	if err != nil {
-		return errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: saveUpdate: %w", err))
+		return false, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: saveUpdate: begin: %w", err))
 	}
+	defer tx.Rollback() //nolint:errcheck
 
-	return nil
+	// Concurrent writers of the same document take turns, so only one of
+	// them can claim the version.
+	if err = lockDocumentVersions(ctx, tx, documentID); err != nil {
+		return false, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: saveUpdate: lock: %w", err))
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO document_updates (document_id, yjs_update, user_id, version, created_at)
+		SELECT $1, $2, $3, $4, NOW()
+		WHERE NOT EXISTS (
+			SELECT 1 FROM document_updates WHERE document_id = $1 AND version >= $4
+		)
+	`, documentID, yjsUpdate, userID, version)
+	if err != nil {
+		return false, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: saveUpdate: %w", err))
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: saveUpdate: %w", err))
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: saveUpdate: commit: %w", err))
+	}
+
+	return inserted == 1, nil
+}
+
+// latestVersionQuery selects the version of the last snapshot or stored
+// update of a document, whichever is newer.
+const latestVersionQuery = `
+	SELECT GREATEST(
+		COALESCE((SELECT version FROM document_snapshots WHERE document_id = $1), 0),
+		COALESCE((SELECT MAX(version) FROM document_updates WHERE document_id = $1), 0)
+	)
+`
+
+// lockDocumentVersions makes the writers of a document's versions take turns
+// until the transaction ends.
+func lockDocumentVersions(ctx context.Context, tx *sql.Tx, documentID uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1::text, 0))`, documentID)
+	return err
+}
+
+// GetLatestVersion returns the version of the last snapshot or stored update
+// of a document, whichever is newer.
+func (p *DocumentPersistence) GetLatestVersion(ctx context.Context, documentID uuid.UUID) (int, error) {
+	var version int
+	if err := p.db.QueryRowContext(ctx, latestVersionQuery, documentID).Scan(&version); err != nil {
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getLatestVersion: %w", err))
+	}
+
+	return version, nil
+}
+
+// SaveUpdates stores a batch of CRDT updates of one document in a single
+// statement and returns the version of the last one. The versions are assigned
+// here, after the latest version of the document, so that instances writing
+// the same document never claim the same one; the versions of the records are
+// ignored. Their creation times are kept as given.
+func (p *DocumentPersistence) SaveUpdates(ctx context.Context, updates []UpdateRecord) (int, error) {
+	if len(updates) == 0 {
+		return 0, nil
+	}
+	documentID := updates[0].DocumentID
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: saveUpdates: begin: %w", err))
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err = lockDocumentVersions(ctx, tx, documentID); err != nil {
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: saveUpdates: lock: %w", err))
+	}
+
+	var latest int
+	if err = tx.QueryRowContext(ctx, latestVersionQuery, documentID).Scan(&latest); err != nil {
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: saveUpdates: latest version: %w", err))
+	}
+
+	var query strings.Builder
+	query.WriteString(`INSERT INTO document_updates (document_id, yjs_update, user_id, version, created_at) VALUES `)
+	args := make([]any, 0, len(updates)*5)
+	for i, update := range updates {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		n := len(args)
+		fmt.Fprintf(&query, "($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5)
+		args = append(args, documentID, update.YjsUpdate, update.UserID, latest+i+1, update.CreatedAt)
+	}
+
+	if _, err = tx.ExecContext(ctx, query.String(), args...); err != nil {
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: saveUpdates: %w", err))
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: saveUpdates: commit: %w", err))
+	}
+
+	return latest + len(updates), nil
 }
 
 // GetUpdates fetches updates since a provided version (exclusive).
@@ -139,12 +281,23 @@ func (p *DocumentPersistence) GetUpdates(ctx context.Context, documentID uuid.UU
 	if err != nil {
 		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getUpdates: %w", err))
 	}
+
+	updates, err := scanUpdates(rows)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getUpdates: %w", err))
+	}
+
+	return updates, nil
+}
+
+// scanUpdates reads update rows and closes them.
+func scanUpdates(rows *sql.Rows) ([]UpdateRecord, error) {
 	defer rows.Close() //nolint:errcheck
 
 	var updates []UpdateRecord
 	for rows.Next() {
 		var update UpdateRecord
-		err = rows.Scan(
+		err := rows.Scan(
 			&update.ID,
 			&update.DocumentID,
 			&update.YjsUpdate,
@@ -153,13 +306,13 @@ func (p *DocumentPersistence) GetUpdates(ctx context.Context, documentID uuid.UU
 			&update.CreatedAt,
 		)
 		if err != nil {
-			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getUpdates scan: %w", err))
+			return nil, fmt.Errorf("scan: %w", err)
 		}
 		updates = append(updates, update)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getUpdates rows: %w", err))
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
 	}
 
 	return updates, nil
@@ -229,16 +382,149 @@ func (p *DocumentPersistence) GetPresence(ctx context.Context, documentID uuid.U
 	return presences, nil
 }
 
-// RemovePresence removes a user's presence from a document (e.g., when they disconnect).
-func (p *DocumentPersistence) RemovePresence(ctx context.Context, documentID, userID uuid.UUID) error {
+// GetActivePresence retrieves presence refreshed within the presence TTL for a
+// document, together with user logins.
+func (p *DocumentPersistence) GetActivePresence(ctx context.Context, documentID uuid.UUID) ([]PresenceRecord, error) {
 	query := `
-		DELETE FROM document_presence
-		WHERE document_id = $1 AND user_id = $2
+		SELECT p.id, p.document_id, p.user_id, COALESCE(u.login, ''), p.cursor_position, p.last_seen
+		FROM document_presence p
+		LEFT JOIN users u ON u.uuid = p.user_id
+		WHERE p.document_id = $1 AND p.last_seen > NOW() - make_interval(secs => $2)
+		ORDER BY p.last_seen DESC
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, documentID, presenceTTL.Seconds())
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getActivePresence: %w", err))
+	}
+
+	presences, err := scanPresence(rows)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getActivePresence: %w", err))
+	}
+
+	return presences, nil
+}
+
+// GetGroupPresence retrieves active presence across all documents of a group.
+func (p *DocumentPersistence) GetGroupPresence(ctx context.Context, groupUUID uuid.UUID) ([]PresenceRecord, error) {
+	query := `
+		SELECT p.id, p.document_id, p.user_id, COALESCE(u.login, ''), p.cursor_position, p.last_seen
+		FROM document_presence p
+		JOIN documents d ON d.uuid = p.document_id
+		LEFT JOIN users u ON u.uuid = p.user_id
+		WHERE d.group_uuid = $1 AND p.last_seen > NOW() - make_interval(secs => $2)
+		ORDER BY p.document_id, p.last_seen DESC
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, groupUUID, presenceTTL.Seconds())
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getGroupPresence: %w", err))
+	}
+
+	presences, err := scanPresence(rows)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: getGroupPresence: %w", err))
+	}
+
+	return presences, nil
+}
+
+// scanPresence reads presence rows with user logins and closes them.
+func scanPresence(rows *sql.Rows) ([]PresenceRecord, error) {
+	defer rows.Close() //nolint:errcheck
+
+	var presences []PresenceRecord
+	for rows.Next() {
+		var presence PresenceRecord
+		var cursorPos sql.NullInt32
+		err := rows.Scan(
+			&presence.ID,
+			&presence.DocumentID,
+			&presence.UserID,
+			&presence.UserLogin,
+			&cursorPos,
+			&presence.LastSeen,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		if cursorPos.Valid {
+			pos := int(cursorPos.Int32)
+			presence.CursorPosition = &pos
+		}
+
+		presences = append(presences, presence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows: %w", err)
+	}
+
+	return presences, nil
+}
+
+// AddPresenceConnection counts a new connection of a user to a document and
+// refreshes their presence. Rows that outlived the presence TTL belong to
+// connections of instances that went away, so their count starts over.
+func (p *DocumentPersistence) AddPresenceConnection(ctx context.Context, documentID, userID uuid.UUID) error {
+	query := `
+		INSERT INTO document_presence (document_id, user_id, connections, last_seen)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (document_id, user_id) DO UPDATE
+		SET connections = CASE
+		        WHEN document_presence.last_seen < NOW() - make_interval(secs => $3) THEN 1
+		        ELSE document_presence.connections + 1
+		    END,
+		    last_seen = NOW()
 	`
 
-	_, err := p.db.ExecContext(ctx, query, documentID, userID)
+	_, err := p.db.ExecContext(ctx, query, documentID, userID, presenceTTL.Seconds())
 	if err != nil {
-		return errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: removePresence: %w", err))
+		return errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: addPresenceConnection: %w", err))
+	}
+
+	return nil
+}
+
+// RemovePresenceConnection counts a closed connection of a user to a document
+// and removes their presence once no connection remains on any instance.
+func (p *DocumentPersistence) RemovePresenceConnection(ctx context.Context, documentID, userID uuid.UUID) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: removePresenceConnection: begin: %w", err))
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	// The row stays locked until commit, so concurrent releases of the same
+	// presence count one after another.
+	var connections int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE document_presence
+		SET connections = connections - 1
+		WHERE document_id = $1 AND user_id = $2
+		RETURNING connections
+	`, documentID, userID).Scan(&connections)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: removePresenceConnection: release: %w", err))
+	}
+
+	if connections <= 0 {
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM document_presence
+			WHERE document_id = $1 AND user_id = $2
+		`, documentID, userID)
+		if err != nil {
+			return errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: removePresenceConnection: delete: %w", err))
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Join(domain.ErrInternal, fmt.Errorf("document persistence: removePresenceConnection: commit: %w", err))
 	}
 
 	return nil
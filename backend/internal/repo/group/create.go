@@ -12,12 +12,13 @@ func (r *GroupRepository) Create(ctx context.Context, name string) (*domain.Grou
 	query := `
 		INSERT INTO groups (name) 
 		VALUES ($1) 
-		RETURNING uuid, name, created_at`
+		RETURNING uuid, name, max_document_size, created_at`
 
 	var group domain.Group
 	err := r.db.QueryRowContext(ctx, query, name).Scan(
 		&group.UUID,
 		&group.Name,
+		&group.MaxDocumentSize,
 		&group.CreatedAt,
 	)
 	if err != nil {
@@ -12,7 +12,7 @@ import (
 
 func (r *GroupRepository) GetByUUID(ctx context.Context, uuid uuid.UUID) (*domain.Group, error) {
 	query := `
-		SELECT uuid, name, created_at 
+		SELECT uuid, name, max_document_size, created_at 
 		FROM groups 
 		WHERE uuid = $1`
 
@@ -20,6 +20,7 @@ func (r *GroupRepository) GetByUUID(ctx context.Context, uuid uuid.UUID) (*domai
 	err := r.db.QueryRowContext(ctx, query, uuid).Scan(
 		&group.UUID,
 		&group.Name,
+		&group.MaxDocumentSize,
 		&group.CreatedAt,
 	)
 	if err != nil {
@@ -34,7 +35,7 @@ func (r *GroupRepository) GetByUUID(ctx context.Context, uuid uuid.UUID) (*domai
 
 func (r *GroupRepository) GetAll(ctx context.Context) ([]*domain.Group, error) {
 	query := `
-		SELECT uuid, name, created_at 
+		SELECT uuid, name, max_document_size, created_at 
 		FROM groups 
 		ORDER BY created_at DESC`
 
@@ -50,6 +51,7 @@ func (r *GroupRepository) GetAll(ctx context.Context) ([]*domain.Group, error) {
 		err := rows.Scan(
 			&group.UUID,
 			&group.Name,
+			&group.MaxDocumentSize,
 			&group.CreatedAt,
 		)
 		if err != nil {
@@ -67,7 +69,7 @@ func (r *GroupRepository) GetAll(ctx context.Context) ([]*domain.Group, error) {
 
 func (r *GroupRepository) GetAllForUser(ctx context.Context, userUUID uuid.UUID) ([]*domain.Group, error) {
 	query := `
-		SELECT g.uuid, g.name, g.created_at
+		SELECT g.uuid, g.name, g.max_document_size, g.created_at
 		FROM groups g
 		INNER JOIN user_groups ug ON ug.group_uuid = g.uuid
 		WHERE ug.user_uuid = $1
@@ -85,6 +87,7 @@ func (r *GroupRepository) GetAllForUser(ctx context.Context, userUUID uuid.UUID)
 		err := rows.Scan(
 			&group.UUID,
 			&group.Name,
+			&group.MaxDocumentSize,
 			&group.CreatedAt,
 		)
 		if err != nil {
@@ -99,3 +102,29 @@ func (r *GroupRepository) GetAllForUser(ctx context.Context, userUUID uuid.UUID)
 
 	return groups, nil
 }
+
+// GetDocumentUUIDs lists the documents that belong to a group.
+func (r *GroupRepository) GetDocumentUUIDs(ctx context.Context, groupUUID uuid.UUID) ([]uuid.UUID, error) {
+	query := `SELECT uuid FROM documents WHERE group_uuid = $1`
+
+	rows, err := r.db.QueryContext(ctx, query, groupUUID)
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("group repository: getDocumentUUIDs query: %w", err))
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var documentUUIDs []uuid.UUID
+	for rows.Next() {
+		var documentUUID uuid.UUID
+		if err := rows.Scan(&documentUUID); err != nil {
+			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("group repository: getDocumentUUIDs scan: %w", err))
+		}
+		documentUUIDs = append(documentUUIDs, documentUUID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("group repository: getDocumentUUIDs rows err: %w", err))
+	}
+
+	return documentUUIDs, nil
+}
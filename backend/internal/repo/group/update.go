@@ -10,17 +10,19 @@ import (
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
 )
 
-func (r *GroupRepository) Update(ctx context.Context, uuid uuid.UUID, name string) (*domain.Group, error) {
+// Update renames a group. A nil maxDocumentSize keeps the current limit.
+func (r *GroupRepository) Update(ctx context.Context, uuid uuid.UUID, name string, maxDocumentSize *int) (*domain.Group, error) {
 	query := `
 		UPDATE groups 
-		SET name = $1 
+		SET name = $1, max_document_size = COALESCE($3, max_document_size) 
 		WHERE uuid = $2 
-		RETURNING uuid, name, created_at`
+		RETURNING uuid, name, max_document_size, created_at`
 
 	var group domain.Group
-	err := r.db.QueryRowContext(ctx, query, name, uuid).Scan(
+	err := r.db.QueryRowContext(ctx, query, name, uuid, maxDocumentSize).Scan(
 		&group.UUID,
 		&group.Name,
+		&group.MaxDocumentSize,
 		&group.CreatedAt,
 	)
 	if err != nil {
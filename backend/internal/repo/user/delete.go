@@ -11,9 +11,9 @@ import (
 )
 
 func (r *UserRepository) Delete(ctx context.Context, uuid uuid.UUID) error {
-	query := `DELETE FROM users WHERE uuid = $1`
+	query := `DELETE FROM users WHERE uuid = $1 AND uuid <> $2`
 
-	result, err := r.db.ExecContext(ctx, query, uuid)
+	result, err := r.db.ExecContext(ctx, query, uuid, domain.GuestUserUUID)
 	if err != nil {
 		return errors.Join(domain.ErrInternal, fmt.Errorf("user repository: delete exec: %w", err))
 	}
@@ -14,10 +14,10 @@ func (r *UserRepository) GetByUUID(ctx context.Context, uuid uuid.UUID) (*domain
 	query := `
 		SELECT uuid, login, email, hashed_password, created_at 
 		FROM users 
-		WHERE uuid = $1`
+		WHERE uuid = $1 AND uuid <> $2`
 
 	var user domain.User
-	err := r.db.QueryRowContext(ctx, query, uuid).Scan(
+	err := r.db.QueryRowContext(ctx, query, uuid, domain.GuestUserUUID).Scan(
 		&user.UUID,
 		&user.Login,
 		&user.Email,
@@ -38,10 +38,10 @@ func (r *UserRepository) GetByLogin(ctx context.Context, login string) (*domain.
 	query := `
     SELECT uuid, login, email, hashed_password, created_at 
     FROM users 
-    WHERE login = $1`
+    WHERE login = $1 AND uuid <> $2`
 
 	var user domain.User
-	err := r.db.QueryRowContext(ctx, query, login).Scan(
+	err := r.db.QueryRowContext(ctx, query, login, domain.GuestUserUUID).Scan(
 		&user.UUID,
 		&user.Login,
 		&user.Email,
@@ -62,9 +62,10 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
 	query := `
 		SELECT uuid, login, email, hashed_password, created_at 
 		FROM users 
+		WHERE uuid <> $1
 		ORDER BY created_at DESC`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, domain.GuestUserUUID)
 	if err != nil {
 		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("user repository: getAll query: %w", err))
 	}
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 )
 
+// UserRepository stores user accounts. The share-link guest user
+// (domain.GuestUserUUID) is not an account, so its queries leave it out.
 type UserRepository struct {
 	db *sql.DB
 }
@@ -14,11 +14,11 @@ func (r *UserRepository) Update(ctx context.Context, uuid uuid.UUID, login strin
 	query := `
 		UPDATE users 
 		SET login = $1, email = $2, hashed_password = $3
-		WHERE uuid = $4
+		WHERE uuid = $4 AND uuid <> $5
 		RETURNING uuid, login, email, hashed_password, created_at`
 
 	var user domain.User
-	err := r.db.QueryRowContext(ctx, query, login, email, password, uuid).Scan(
+	err := r.db.QueryRowContext(ctx, query, login, email, password, uuid, domain.GuestUserUUID).Scan(
 		&user.UUID,
 		&user.Login,
 		&user.Email,
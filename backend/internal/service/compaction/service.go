@@ -0,0 +1,245 @@
+package compaction
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+	"go.uber.org/zap"
+)
+
+const defaultBatchSize = 100
+
+// Config controls how often updates are compacted and how long folded rows are kept.
+type Config struct {
+	// Interval between compaction runs; zero disables the worker.
+	Interval time.Duration
+	// Retention keeps folded updates for this long before they are pruned.
+	Retention time.Duration
+	// Archive moves pruned updates to document_updates_archive instead of deleting them.
+	Archive bool
+	// BatchSize limits how many documents are compacted per run.
+	BatchSize int
+}
+
+// updateStore is the part of repo.DocumentPersistence the worker uses.
+type updateStore interface {
+	ListCompactionCandidates(ctx context.Context, limit int) ([]uuid.UUID, error)
+	LoadSnapshot(ctx context.Context, documentID uuid.UUID) (*repo.SnapshotRecord, error)
+	GetUpdates(ctx context.Context, documentID uuid.UUID, fromVersion int) ([]repo.UpdateRecord, error)
+	SaveCompactedSnapshot(ctx context.Context, documentID uuid.UUID, yjsSnapshot []byte, snapshotVersion, compactedVersion int) (bool, error)
+	ListPrunableDocuments(ctx context.Context, retention time.Duration, limit int) ([]uuid.UUID, error)
+	GetPrunableUpdates(ctx context.Context, documentID uuid.UUID, retention time.Duration) ([]repo.UpdateRecord, error)
+	LoadHistoryBase(ctx context.Context, documentID uuid.UUID) ([]byte, int, error)
+	PruneUpdates(ctx context.Context, documentID uuid.UUID, historyBase []byte, baseVersion int, updateIDs []int64) error
+	ArchiveCompactedUpdates(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// CompactionService folds stored document updates into document snapshots.
+type CompactionService struct {
+	persistence updateStore
+	logger      *zap.Logger
+	cfg         Config
+}
+
+func NewCompactionService(persistence updateStore, logger *zap.Logger, cfg Config) *CompactionService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	return &CompactionService{
+		persistence: persistence,
+		logger:      logger,
+		cfg:         cfg,
+	}
+}
+
+// Run compacts documents every configured interval until ctx is canceled.
+func (s *CompactionService) Run(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.runOnce(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *CompactionService) runOnce(ctx context.Context) {
+	compacted, err := s.CompactAll(ctx)
+	if err != nil {
+		s.logger.Warn("failed to compact document updates", zap.Error(err))
+	}
+
+	pruned, err := s.PruneUpdates(ctx)
+	if err != nil {
+		s.logger.Warn("failed to prune compacted document updates", zap.Error(err))
+	}
+
+	if compacted > 0 || pruned > 0 {
+		s.logger.Info("compacted document updates", zap.Int("documents", compacted), zap.Int64("pruned_updates", pruned))
+	}
+}
+
+// CompactAll compacts one batch of documents with pending updates and returns
+// how many snapshots were rewritten.
+func (s *CompactionService) CompactAll(ctx context.Context) (int, error) {
+	documentIDs, err := s.persistence.ListCompactionCandidates(ctx, s.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("compaction service: compactAll: %w", err)
+	}
+
+	compacted := 0
+	for _, documentID := range documentIDs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		ok, err := s.CompactDocument(ctx, documentID)
+		if err != nil {
+			s.logger.Warn("failed to compact document", zap.String("document_id", documentID.String()), zap.Error(err))
+			continue
+		}
+		if ok {
+			compacted++
+		}
+	}
+
+	return compacted, nil
+}
+
+// CompactDocument merges the updates covered by the latest snapshot into it and
+// records the highest folded version. It reports false when nothing was written,
+// either because there was nothing to fold or the snapshot changed meanwhile.
+func (s *CompactionService) CompactDocument(ctx context.Context, documentID uuid.UUID) (bool, error) {
+	snapshot, err := s.persistence.LoadSnapshot(ctx, documentID)
+	if err != nil {
+		return false, fmt.Errorf("compaction service: compactDocument: %w", err)
+	}
+	if snapshot == nil {
+		return false, nil
+	}
+
+	updates, err := s.persistence.GetUpdates(ctx, documentID, snapshot.CompactedVersion)
+	if err != nil {
+		return false, fmt.Errorf("compaction service: compactDocument: %w", err)
+	}
+
+	parts := [][]byte{snapshot.YjsSnapshot}
+	compactedVersion := snapshot.CompactedVersion
+	for _, update := range updates {
+		if update.Version > snapshot.Version {
+			continue
+		}
+		compactedVersion = max(compactedVersion, update.Version)
+
+		if err := yjs.ValidateUpdate(update.YjsUpdate); err != nil {
+			s.logger.Warn(
+				"skipping undecodable document update",
+				zap.String("document_id", documentID.String()),
+				zap.Int64("update_id", update.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		parts = append(parts, update.YjsUpdate)
+	}
+
+	if compactedVersion == snapshot.CompactedVersion {
+		return false, nil
+	}
+
+	merged, err := yjs.MergeUpdates(parts...)
+	if err != nil {
+		return false, fmt.Errorf("compaction service: compactDocument: merge: %w", err)
+	}
+
+	saved, err := s.persistence.SaveCompactedSnapshot(ctx, documentID, merged, snapshot.Version, compactedVersion)
+	if err != nil {
+		return false, fmt.Errorf("compaction service: compactDocument: %w", err)
+	}
+
+	return saved, nil
+}
+
+// PruneUpdates archives or deletes folded updates older than the retention
+// period. Deleted updates are merged into the document's history base first.
+func (s *CompactionService) PruneUpdates(ctx context.Context) (int64, error) {
+	if s.cfg.Archive {
+		archived, err := s.persistence.ArchiveCompactedUpdates(ctx, s.cfg.Retention)
+		if err != nil {
+			return 0, fmt.Errorf("compaction service: pruneUpdates: %w", err)
+		}
+		return archived, nil
+	}
+
+	documentIDs, err := s.persistence.ListPrunableDocuments(ctx, s.cfg.Retention, s.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("compaction service: pruneUpdates: %w", err)
+	}
+
+	var pruned int64
+	for _, documentID := range documentIDs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		n, err := s.pruneDocument(ctx, documentID)
+		if err != nil {
+			s.logger.Warn("failed to prune document updates", zap.String("document_id", documentID.String()), zap.Error(err))
+			continue
+		}
+		pruned += n
+	}
+
+	return pruned, nil
+}
+
+func (s *CompactionService) pruneDocument(ctx context.Context, documentID uuid.UUID) (int64, error) {
+	updates, err := s.persistence.GetPrunableUpdates(ctx, documentID, s.cfg.Retention)
+	if err != nil {
+		return 0, fmt.Errorf("compaction service: pruneDocument: %w", err)
+	}
+	if len(updates) == 0 {
+		return 0, nil
+	}
+
+	base, baseVersion, err := s.persistence.LoadHistoryBase(ctx, documentID)
+	if err != nil {
+		return 0, fmt.Errorf("compaction service: pruneDocument: %w", err)
+	}
+
+	parts := make([][]byte, 0, len(updates)+1)
+	if len(base) > 0 {
+		parts = append(parts, base)
+	}
+	ids := make([]int64, 0, len(updates))
+	for _, update := range updates {
+		ids = append(ids, update.ID)
+		baseVersion = max(baseVersion, update.Version)
+		if yjs.ValidateUpdate(update.YjsUpdate) == nil {
+			parts = append(parts, update.YjsUpdate)
+		}
+	}
+
+	merged, err := yjs.MergeUpdates(parts...)
+	if err != nil {
+		return 0, fmt.Errorf("compaction service: pruneDocument: merge: %w", err)
+	}
+
+	if err := s.persistence.PruneUpdates(ctx, documentID, merged, baseVersion, ids); err != nil {
+		return 0, fmt.Errorf("compaction service: pruneDocument: %w", err)
+	}
+
+	return int64(len(ids)), nil
+}
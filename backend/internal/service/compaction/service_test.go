@@ -0,0 +1,439 @@
+package compaction_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/service/compaction"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+	"go.uber.org/zap"
+)
+
+type mockUpdateStore struct {
+	mock.Mock
+}
+
+func (m *mockUpdateStore) ListCompactionCandidates(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1) //nolint:errcheck
+}
+
+func (m *mockUpdateStore) LoadSnapshot(ctx context.Context, documentID uuid.UUID) (*repo.SnapshotRecord, error) {
+	args := m.Called(ctx, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repo.SnapshotRecord), args.Error(1) //nolint:errcheck
+}
+
+func (m *mockUpdateStore) GetUpdates(ctx context.Context, documentID uuid.UUID, fromVersion int) ([]repo.UpdateRecord, error) {
+	args := m.Called(ctx, documentID, fromVersion)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repo.UpdateRecord), args.Error(1) //nolint:errcheck
+}
+
+func (m *mockUpdateStore) SaveCompactedSnapshot(
+	ctx context.Context,
+	documentID uuid.UUID,
+	yjsSnapshot []byte,
+	snapshotVersion, compactedVersion int,
+) (bool, error) {
+	args := m.Called(ctx, documentID, yjsSnapshot, snapshotVersion, compactedVersion)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockUpdateStore) ListPrunableDocuments(ctx context.Context, retention time.Duration, limit int) ([]uuid.UUID, error) {
+	args := m.Called(ctx, retention, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1) //nolint:errcheck
+}
+
+func (m *mockUpdateStore) GetPrunableUpdates(ctx context.Context, documentID uuid.UUID, retention time.Duration) ([]repo.UpdateRecord, error) {
+	args := m.Called(ctx, documentID, retention)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repo.UpdateRecord), args.Error(1) //nolint:errcheck
+}
+
+func (m *mockUpdateStore) LoadHistoryBase(ctx context.Context, documentID uuid.UUID) ([]byte, int, error) {
+	args := m.Called(ctx, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]byte), args.Int(1), args.Error(2) //nolint:errcheck
+}
+
+func (m *mockUpdateStore) PruneUpdates(
+	ctx context.Context,
+	documentID uuid.UUID,
+	historyBase []byte,
+	baseVersion int,
+	updateIDs []int64,
+) error {
+	args := m.Called(ctx, documentID, historyBase, baseVersion, updateIDs)
+	return args.Error(0)
+}
+
+func (m *mockUpdateStore) ArchiveCompactedUpdates(ctx context.Context, retention time.Duration) (int64, error) {
+	args := m.Called(ctx, retention)
+	return args.Get(0).(int64), args.Error(1) //nolint:errcheck
+}
+
+// textInsert encodes a Yjs update appending text for client, starting at clock,
+// to the root type "content".
+func textInsert(client, clock uint64, text string) []byte {
+	enc := yjs.NewEncoder()
+	enc.WriteVarUint(1)
+	enc.WriteVarUint(1)
+	enc.WriteVarUint(client)
+	enc.WriteVarUint(clock)
+	if clock == 0 {
+		enc.WriteUint8(4)
+		enc.WriteVarUint(1)
+		enc.WriteVarString("content")
+	} else {
+		enc.WriteUint8(4 | 0x80)
+		enc.WriteVarUint(client)
+		enc.WriteVarUint(clock - 1)
+	}
+	enc.WriteVarString(text)
+	enc.WriteVarUint(0)
+	return enc.Bytes()
+}
+
+// textOf decodes a merged state and returns its "content" text.
+func textOf(t *testing.T, state []byte) string {
+	t.Helper()
+	doc, err := yjs.NewDocFromUpdate(state)
+	require.NoError(t, err)
+	return doc.Text("content")
+}
+
+func TestCompactDocument(t *testing.T) {
+	setup := func(t *testing.T) (*mockUpdateStore, *compaction.CompactionService) {
+		store := &mockUpdateStore{}
+		service := compaction.NewCompactionService(store, zap.NewNop(), compaction.Config{})
+		t.Cleanup(func() {
+			store.AssertExpectations(t)
+		})
+		return store, service
+	}
+
+	t.Run("FoldsUpdatesCoveredBySnapshot", func(t *testing.T) {
+		// Arrange
+		store, service := setup(t)
+
+		documentID := uuid.New()
+		store.On("LoadSnapshot", mock.Anything, documentID).Return(&repo.SnapshotRecord{
+			DocumentID:       documentID,
+			YjsSnapshot:      textInsert(1, 0, "ab"),
+			Version:          3,
+			CompactedVersion: 1,
+		}, nil)
+		store.On("GetUpdates", mock.Anything, documentID, 1).Return([]repo.UpdateRecord{
+			{ID: 10, YjsUpdate: textInsert(1, 2, "c"), Version: 2},
+			{ID: 11, YjsUpdate: []byte{1, 1, 7}, Version: 3},
+			{ID: 12, YjsUpdate: textInsert(1, 3, "d"), Version: 4},
+		}, nil)
+
+		var saved []byte
+		store.On("SaveCompactedSnapshot", mock.Anything, documentID, mock.Anything, 3, 3).
+			Run(func(args mock.Arguments) { saved = args.Get(2).([]byte) }). //nolint:errcheck
+			Return(true, nil)
+
+		// Act
+		ok, err := service.CompactDocument(context.Background(), documentID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "abc", textOf(t, saved))
+	})
+
+	t.Run("SkipsDocumentsWithNothingToFold", func(t *testing.T) {
+		// Arrange
+		store, service := setup(t)
+
+		documentID := uuid.New()
+		store.On("LoadSnapshot", mock.Anything, documentID).Return(&repo.SnapshotRecord{
+			DocumentID:       documentID,
+			YjsSnapshot:      textInsert(1, 0, "ab"),
+			Version:          2,
+			CompactedVersion: 2,
+		}, nil)
+		store.On("GetUpdates", mock.Anything, documentID, 2).Return([]repo.UpdateRecord{
+			{ID: 10, YjsUpdate: textInsert(1, 2, "c"), Version: 3},
+		}, nil)
+
+		// Act
+		ok, err := service.CompactDocument(context.Background(), documentID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, ok)
+		store.AssertNotCalled(t, "SaveCompactedSnapshot")
+	})
+
+	t.Run("SkipsDocumentsWithoutSnapshot", func(t *testing.T) {
+		// Arrange
+		store, service := setup(t)
+
+		documentID := uuid.New()
+		store.On("LoadSnapshot", mock.Anything, documentID).Return(nil, nil)
+
+		// Act
+		ok, err := service.CompactDocument(context.Background(), documentID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("ReportsChangedSnapshot", func(t *testing.T) {
+		// Arrange
+		store, service := setup(t)
+
+		documentID := uuid.New()
+		store.On("LoadSnapshot", mock.Anything, documentID).Return(&repo.SnapshotRecord{
+			DocumentID:  documentID,
+			YjsSnapshot: textInsert(1, 0, "a"),
+			Version:     2,
+		}, nil)
+		store.On("GetUpdates", mock.Anything, documentID, 0).Return([]repo.UpdateRecord{
+			{ID: 10, YjsUpdate: textInsert(1, 1, "b"), Version: 2},
+		}, nil)
+		store.On("SaveCompactedSnapshot", mock.Anything, documentID, mock.Anything, 2, 2).Return(false, nil)
+
+		// Act
+		ok, err := service.CompactDocument(context.Background(), documentID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("ErrorMapping", func(t *testing.T) {
+		cases := map[string]func(store *mockUpdateStore, documentID uuid.UUID){
+			"LoadSnapshot": func(store *mockUpdateStore, documentID uuid.UUID) {
+				store.On("LoadSnapshot", mock.Anything, documentID).Return(nil, domain.ErrInternal)
+			},
+			"GetUpdates": func(store *mockUpdateStore, documentID uuid.UUID) {
+				store.On("LoadSnapshot", mock.Anything, documentID).Return(&repo.SnapshotRecord{Version: 1}, nil)
+				store.On("GetUpdates", mock.Anything, documentID, 0).Return(nil, domain.ErrInternal)
+			},
+			"SaveCompactedSnapshot": func(store *mockUpdateStore, documentID uuid.UUID) {
+				store.On("LoadSnapshot", mock.Anything, documentID).Return(&repo.SnapshotRecord{
+					YjsSnapshot: textInsert(1, 0, "a"),
+					Version:     1,
+				}, nil)
+				store.On("GetUpdates", mock.Anything, documentID, 0).Return([]repo.UpdateRecord{
+					{ID: 10, YjsUpdate: textInsert(1, 0, "a"), Version: 1},
+				}, nil)
+				store.On("SaveCompactedSnapshot", mock.Anything, documentID, mock.Anything, 1, 1).Return(false, domain.ErrInternal)
+			},
+		}
+		for name, arrange := range cases {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				store, service := setup(t)
+
+				documentID := uuid.New()
+				arrange(store, documentID)
+
+				// Act
+				ok, err := service.CompactDocument(context.Background(), documentID)
+
+				// Assert
+				assert.ErrorIs(t, err, domain.ErrInternal)
+				assert.False(t, ok)
+			})
+		}
+	})
+}
+
+func TestCompactAll(t *testing.T) {
+	t.Run("ContinuesPastFailingDocuments", func(t *testing.T) {
+		// Arrange
+		store := &mockUpdateStore{}
+		service := compaction.NewCompactionService(store, zap.NewNop(), compaction.Config{BatchSize: 5})
+
+		failing := uuid.New()
+		compacted := uuid.New()
+		store.On("ListCompactionCandidates", mock.Anything, 5).Return([]uuid.UUID{failing, compacted}, nil)
+		store.On("LoadSnapshot", mock.Anything, failing).Return(nil, domain.ErrInternal)
+		store.On("LoadSnapshot", mock.Anything, compacted).Return(&repo.SnapshotRecord{
+			YjsSnapshot: textInsert(1, 0, "a"),
+			Version:     1,
+		}, nil)
+		store.On("GetUpdates", mock.Anything, compacted, 0).Return([]repo.UpdateRecord{
+			{ID: 10, YjsUpdate: textInsert(1, 0, "a"), Version: 1},
+		}, nil)
+		store.On("SaveCompactedSnapshot", mock.Anything, compacted, mock.Anything, 1, 1).Return(true, nil)
+
+		// Act
+		count, err := service.CompactAll(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("FailsWhenCandidatesCannotBeListed", func(t *testing.T) {
+		// Arrange
+		store := &mockUpdateStore{}
+		service := compaction.NewCompactionService(store, zap.NewNop(), compaction.Config{})
+		store.On("ListCompactionCandidates", mock.Anything, 100).Return(nil, domain.ErrInternal)
+
+		// Act
+		count, err := service.CompactAll(context.Background())
+
+		// Assert
+		assert.ErrorIs(t, err, domain.ErrInternal)
+		assert.Zero(t, count)
+	})
+}
+
+func TestPruneUpdates(t *testing.T) {
+	const retention = 48 * time.Hour
+
+	t.Run("MergesPrunedUpdatesIntoHistoryBase", func(t *testing.T) {
+		// Arrange
+		store := &mockUpdateStore{}
+		service := compaction.NewCompactionService(store, zap.NewNop(), compaction.Config{Retention: retention, BatchSize: 10})
+
+		documentID := uuid.New()
+		store.On("ListPrunableDocuments", mock.Anything, retention, 10).Return([]uuid.UUID{documentID}, nil)
+		store.On("GetPrunableUpdates", mock.Anything, documentID, retention).Return([]repo.UpdateRecord{
+			{ID: 20, YjsUpdate: textInsert(1, 2, "c"), Version: 4},
+			{ID: 21, YjsUpdate: []byte{1, 1, 7}, Version: 5},
+		}, nil)
+		store.On("LoadHistoryBase", mock.Anything, documentID).Return(textInsert(1, 0, "ab"), 3, nil)
+
+		var base []byte
+		store.On("PruneUpdates", mock.Anything, documentID, mock.Anything, 5, []int64{20, 21}).
+			Run(func(args mock.Arguments) { base = args.Get(2).([]byte) }). //nolint:errcheck
+			Return(nil)
+
+		// Act
+		pruned, err := service.PruneUpdates(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), pruned)
+		assert.Equal(t, "abc", textOf(t, base))
+		store.AssertExpectations(t)
+	})
+
+	t.Run("ArchivesWhenConfigured", func(t *testing.T) {
+		// Arrange
+		store := &mockUpdateStore{}
+		service := compaction.NewCompactionService(store, zap.NewNop(), compaction.Config{Retention: retention, Archive: true})
+		store.On("ArchiveCompactedUpdates", mock.Anything, retention).Return(int64(7), nil)
+
+		// Act
+		archived, err := service.PruneUpdates(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), archived)
+		store.AssertNotCalled(t, "ListPrunableDocuments")
+		store.AssertExpectations(t)
+	})
+
+	t.Run("ContinuesPastFailingDocuments", func(t *testing.T) {
+		// Arrange
+		store := &mockUpdateStore{}
+		service := compaction.NewCompactionService(store, zap.NewNop(), compaction.Config{Retention: retention, BatchSize: 10})
+
+		failing := uuid.New()
+		pruned := uuid.New()
+		store.On("ListPrunableDocuments", mock.Anything, retention, 10).Return([]uuid.UUID{failing, pruned}, nil)
+		store.On("GetPrunableUpdates", mock.Anything, failing, retention).Return([]repo.UpdateRecord{
+			{ID: 30, YjsUpdate: textInsert(1, 0, "a"), Version: 1},
+		}, nil)
+		store.On("LoadHistoryBase", mock.Anything, failing).Return(nil, 0, nil)
+		store.On("PruneUpdates", mock.Anything, failing, mock.Anything, 1, []int64{30}).Return(domain.ErrInternal)
+		store.On("GetPrunableUpdates", mock.Anything, pruned, retention).Return([]repo.UpdateRecord{
+			{ID: 31, YjsUpdate: textInsert(2, 0, "b"), Version: 1},
+		}, nil)
+		store.On("LoadHistoryBase", mock.Anything, pruned).Return(nil, 0, nil)
+		store.On("PruneUpdates", mock.Anything, pruned, mock.Anything, 1, []int64{31}).Return(nil)
+
+		// Act
+		count, err := service.PruneUpdates(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("ErrorMapping", func(t *testing.T) {
+		cases := map[string]struct {
+			cfg     compaction.Config
+			arrange func(store *mockUpdateStore)
+		}{
+			"Archive": {
+				cfg: compaction.Config{Retention: retention, Archive: true},
+				arrange: func(store *mockUpdateStore) {
+					store.On("ArchiveCompactedUpdates", mock.Anything, retention).Return(int64(0), domain.ErrInternal)
+				},
+			},
+			"ListPrunableDocuments": {
+				cfg: compaction.Config{Retention: retention},
+				arrange: func(store *mockUpdateStore) {
+					store.On("ListPrunableDocuments", mock.Anything, retention, 100).Return(nil, domain.ErrInternal)
+				},
+			},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				store := &mockUpdateStore{}
+				service := compaction.NewCompactionService(store, zap.NewNop(), tc.cfg)
+				tc.arrange(store)
+
+				// Act
+				count, err := service.PruneUpdates(context.Background())
+
+				// Assert
+				assert.ErrorIs(t, err, domain.ErrInternal)
+				assert.Zero(t, count)
+			})
+		}
+	})
+}
+
+func TestRunWithoutInterval(t *testing.T) {
+	store := &mockUpdateStore{}
+	service := compaction.NewCompactionService(store, zap.NewNop(), compaction.Config{})
+
+	done := make(chan struct{})
+	go func() {
+		service.Run(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker without interval did not return")
+	}
+	store.AssertExpectations(t)
+}
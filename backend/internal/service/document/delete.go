@@ -26,6 +26,13 @@ func (s *DocumentService) Delete(ctx context.Context, docUUID, userUUID uuid.UUI
 		return domain.ErrForbidden
 	}
 
+	// The room is closed first, so that it writes no state for the rows
+	// deleted below. Its clients are told the document is gone and do not
+	// reconnect.
+	if s.rooms != nil {
+		s.rooms.CloseRoom(ctx, docUUID)
+	}
+
 	err = s.repo.Delete(ctx, docUUID)
 	if err != nil {
 		if err == sql.ErrNoRows {
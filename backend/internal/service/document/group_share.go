@@ -0,0 +1,249 @@
+package document
+
+import (
+	"context"
+	"crypto/hmac"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+)
+
+// GenerateGroupShareLink stores and signs a read-only link to the documents of
+// a group until it expires. With a tag, the link only shares the documents
+// carrying it. Like document links, group links can be listed, capped and
+// revoked.
+func (s *DocumentService) GenerateGroupShareLink(
+	ctx context.Context,
+	groupUUID, userUUID uuid.UUID,
+	opts domain.GroupShareLinkOptions,
+) (*domain.ShareLink, error) {
+	if s.shareCfg.Secret == "" || s.shareCfg.BaseURL == "" {
+		return nil, domain.ErrInternal
+	}
+
+	if _, err := s.groupShareManager(ctx, groupUUID, userUUID); err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := s.shareExpiry(opts.ExpirationDays)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.repo.CreateShareLink(ctx, &domain.ShareLink{
+		GroupUUID: groupUUID,
+		Tag:       strings.TrimSpace(opts.Tag),
+		CreatedBy: userUUID,
+		Role:      domain.RoleViewer,
+		ExpiresAt: expiresAt,
+		MaxUses:   opts.MaxUses,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("document service: shareGroup: %w", err)
+	}
+	link.URL = s.groupShareURL(link)
+
+	return link, nil
+}
+
+// GetGroupShareLinks lists the share links of a group, newest first,
+// including revoked and expired ones.
+func (s *DocumentService) GetGroupShareLinks(ctx context.Context, groupUUID, userUUID uuid.UUID) ([]*domain.ShareLink, error) {
+	if _, err := s.groupShareManager(ctx, groupUUID, userUUID); err != nil {
+		return nil, err
+	}
+
+	links, err := s.repo.GetGroupShareLinks(ctx, groupUUID)
+	if err != nil {
+		return nil, fmt.Errorf("document service: getGroupShareLinks: %w", err)
+	}
+	for _, link := range links {
+		link.URL = s.groupShareURL(link)
+	}
+
+	return links, nil
+}
+
+// RevokeGroupShareLink stops a share link of a group from working, for good.
+func (s *DocumentService) RevokeGroupShareLink(ctx context.Context, groupUUID, linkUUID, userUUID uuid.UUID) error {
+	if _, err := s.groupShareManager(ctx, groupUUID, userUUID); err != nil {
+		return err
+	}
+
+	ok, err := s.repo.RevokeGroupShareLink(ctx, groupUUID, linkUUID)
+	if err != nil {
+		return fmt.Errorf("document service: revokeGroupShareLink: %w", err)
+	}
+	if !ok {
+		return domain.ErrShareLinkNotFound
+	}
+
+	return nil
+}
+
+// GetSharedGroup returns the group a share link grants access to, with the
+// documents it shares, newest first. Like OpenShareLink, it counts a use of
+// the link unless the query carries a visit token, and returns the visit
+// proving the use.
+func (s *DocumentService) GetSharedGroup(
+	ctx context.Context,
+	query domain.GroupShareLinkQuery,
+) (*domain.Group, []*domain.Document, *domain.ShareVisit, error) {
+	group, link, err := s.validateGroupShareLink(ctx, query)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	visit, err := s.useShareLink(ctx, link, query.Visit)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var documents []*domain.Document
+	if link.Tag == "" {
+		documents, err = s.repo.GetAllByGroup(ctx, group.UUID)
+	} else {
+		documents, err = s.repo.GetAllByGroupTag(ctx, group.UUID, link.Tag)
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("document service: getSharedGroup: %w", err)
+	}
+
+	return group, documents, visit, nil
+}
+
+// GetSharedGroupDocument returns a document shared by a group share link,
+// counting a use like GetSharedGroup. Documents of other groups, or without
+// the tag of the link, are reported as not found.
+func (s *DocumentService) GetSharedGroupDocument(
+	ctx context.Context,
+	query domain.GroupShareLinkQuery,
+	docUUID uuid.UUID,
+) (*domain.Document, *domain.ShareVisit, error) {
+	group, link, err := s.validateGroupShareLink(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, err := s.GetByUUID(ctx, docUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if doc.GroupUUID != group.UUID || (link.Tag != "" && !slices.Contains(doc.Tags, link.Tag)) {
+		return nil, nil, domain.ErrDocumentNotFound
+	}
+
+	visit, err := s.useShareLink(ctx, link, query.Visit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return doc, visit, nil
+}
+
+// groupShareManager returns the group if the user may share it: editors and
+// authors of the group can.
+func (s *DocumentService) groupShareManager(ctx context.Context, groupUUID, userUUID uuid.UUID) (*domain.Group, error) {
+	member, err := s.memberRepo.GetMember(ctx, groupUUID, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("document service: shareGroup: %w", err)
+	}
+	if member == nil || member.Role == domain.RoleViewer {
+		return nil, domain.ErrForbidden
+	}
+
+	group, err := s.groupRepo.GetByUUID(ctx, groupUUID)
+	if err != nil {
+		return nil, fmt.Errorf("document service: shareGroup: %w", err)
+	}
+	if group == nil {
+		return nil, domain.ErrGroupNotFound
+	}
+
+	return group, nil
+}
+
+// validateGroupShareLink checks the signature of a group share link and its
+// row, and returns the shared group with the link.
+func (s *DocumentService) validateGroupShareLink(
+	ctx context.Context,
+	query domain.GroupShareLinkQuery,
+) (*domain.Group, *domain.ShareLink, error) {
+	if s.shareCfg.Secret == "" {
+		return nil, nil, domain.ErrInternal
+	}
+
+	if query.Group == "" || query.Sig == "" || query.Exp == "" || query.Link == "" {
+		return nil, nil, domain.ErrShareLinkInvalid
+	}
+
+	groupUUID, err := uuid.Parse(query.Group)
+	if err != nil {
+		return nil, nil, domain.ErrShareLinkInvalid
+	}
+
+	linkUUID, err := uuid.Parse(query.Link)
+	if err != nil {
+		return nil, nil, domain.ErrShareLinkInvalid
+	}
+
+	expTS, err := strconv.ParseInt(query.Exp, 10, 64)
+	if err != nil {
+		return nil, nil, domain.ErrShareLinkInvalid
+	}
+
+	expiresAt := time.Unix(expTS, 0).UTC()
+	if time.Now().UTC().After(expiresAt) {
+		return nil, nil, domain.ErrShareLinkExpired
+	}
+
+	expectedSig := s.computeGroupSignature(groupUUID, expiresAt, linkUUID)
+	if !hmac.Equal([]byte(expectedSig), []byte(query.Sig)) {
+		return nil, nil, domain.ErrShareLinkInvalid
+	}
+
+	link, err := s.repo.GetShareLink(ctx, linkUUID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("document service: validateGroupShareLink: %w", err)
+	}
+	if link == nil || link.GroupUUID != groupUUID {
+		return nil, nil, domain.ErrShareLinkInvalid
+	}
+	if link.Revoked {
+		return nil, nil, domain.ErrShareLinkRevoked
+	}
+
+	group, err := s.groupRepo.GetByUUID(ctx, groupUUID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("document service: validateGroupShareLink: %w", err)
+	}
+	if group == nil {
+		return nil, nil, domain.ErrGroupNotFound
+	}
+
+	return group, link, nil
+}
+
+// groupShareURL builds the public URL of a group share link.
+func (s *DocumentService) groupShareURL(link *domain.ShareLink) string {
+	params := url.Values{}
+	params.Set("group", link.GroupUUID.String())
+	params.Set("sig", s.computeGroupSignature(link.GroupUUID, link.ExpiresAt, link.UUID))
+	params.Set("exp", strconv.FormatInt(link.ExpiresAt.Unix(), 10))
+	params.Set("link", link.UUID.String())
+
+	return fmt.Sprintf("%s/groups/public?%s", strings.TrimRight(s.shareCfg.BaseURL, "/"), params.Encode())
+}
+
+// computeGroupSignature signs a group share link. The payload is prefixed so
+// that it never matches the payload of a document link.
+func (s *DocumentService) computeGroupSignature(groupUUID uuid.UUID, expiresAt time.Time, linkUUID uuid.UUID) string {
+	return s.sign(fmt.Sprintf("group:%s:%d:%s", groupUUID.String(), expiresAt.Unix(), linkUUID.String()))
+}
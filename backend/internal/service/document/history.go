@@ -0,0 +1,280 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+)
+
+const defaultHistoryWindow = 5 * time.Minute
+
+var ErrInvalidRestorePoint = errors.New("invalid restore point")
+
+// GetHistory returns the update timeline of a document. Consecutive updates of
+// the same user are grouped while they are at most window apart.
+func (s *DocumentService) GetHistory(
+	ctx context.Context,
+	docUUID, userUUID uuid.UUID,
+	window time.Duration,
+) ([]domain.DocumentHistoryEntry, error) {
+	if _, err := s.GetByUUIDForUser(ctx, docUUID, userUUID); err != nil {
+		return nil, err
+	}
+
+	if window <= 0 {
+		window = defaultHistoryWindow
+	}
+
+	summaries, err := s.persistence.GetUpdateHistory(ctx, docUUID)
+	if err != nil {
+		return nil, fmt.Errorf("document service: getHistory: %w", err)
+	}
+
+	entries := make([]domain.DocumentHistoryEntry, 0)
+	for _, summary := range summaries {
+		if n := len(entries); n > 0 {
+			last := &entries[n-1]
+			if last.UserUUID == summary.UserID && summary.CreatedAt.Sub(last.EndedAt) <= window {
+				last.FromVersion = min(last.FromVersion, summary.Version)
+				last.ToVersion = max(last.ToVersion, summary.Version)
+				last.Updates++
+				last.Size += summary.Size
+				last.EndedAt = summary.CreatedAt
+				continue
+			}
+		}
+
+		entries = append(entries, domain.DocumentHistoryEntry{
+			UserUUID:    summary.UserID,
+			UserLogin:   summary.UserLogin,
+			FromVersion: summary.Version,
+			ToVersion:   summary.Version,
+			Updates:     1,
+			Size:        summary.Size,
+			StartedAt:   summary.CreatedAt,
+			EndedAt:     summary.CreatedAt,
+		})
+	}
+
+	return entries, nil
+}
+
+// Restore brings the document text back to how it was at version, or at the
+// given time when version is zero. The change is applied on top of the current
+// state as a new update, so the history before it stays intact.
+func (s *DocumentService) Restore(
+	ctx context.Context,
+	docUUID, userUUID uuid.UUID,
+	version int,
+	at time.Time,
+) (*domain.RestoredDocument, error) {
+	doc, err := s.GetByUUID(ctx, docUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.memberRepo.GetMember(ctx, doc.GroupUUID, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("document service: restore: %w", err)
+	}
+	if member == nil || member.Role == domain.RoleViewer {
+		return nil, domain.ErrForbidden
+	}
+
+	if version == 0 && !at.IsZero() {
+		version, err = s.persistence.GetVersionAt(ctx, docUUID, at)
+		if err != nil {
+			return nil, fmt.Errorf("document service: restore: %w", err)
+		}
+	}
+	if version < 1 {
+		return nil, ErrInvalidRestorePoint
+	}
+
+	past, err := s.stateAt(ctx, docUUID, version)
+	if err != nil {
+		return nil, err
+	}
+	text := past.Text(domain.DocumentTextName)
+
+	newVersion, err := s.replaceText(ctx, docUUID, userUUID, text, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.repo.Update(ctx, docUUID, doc.Name, &text, nil); err != nil {
+		return nil, fmt.Errorf("document service: restore: %w", err)
+	}
+
+	return &domain.RestoredDocument{
+		UUID:            docUUID,
+		RestoredVersion: version,
+		Version:         newVersion,
+		Content:         text,
+	}, nil
+}
+
+// stateAt rebuilds the document from its history base and the stored updates
+// up to version.
+func (s *DocumentService) stateAt(ctx context.Context, docUUID uuid.UUID, version int) (*yjs.Doc, error) {
+	base, baseVersion, err := s.persistence.LoadHistoryBase(ctx, docUUID)
+	if err != nil {
+		return nil, fmt.Errorf("document service: stateAt: %w", err)
+	}
+	if version < baseVersion {
+		return nil, domain.ErrHistoryPruned
+	}
+
+	doc := yjs.NewDoc()
+	if len(base) > 0 {
+		if err = doc.ApplyUpdate(base); err != nil {
+			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document service: stateAt: history base: %w", err))
+		}
+	}
+
+	updates, err := s.persistence.GetUpdatesUpTo(ctx, docUUID, version)
+	if err != nil {
+		return nil, fmt.Errorf("document service: stateAt: %w", err)
+	}
+	for _, update := range updates {
+		if update.Version <= baseVersion {
+			continue
+		}
+		// Updates the hub rejected as undecodable were never part of the document.
+		_ = doc.ApplyUpdate(update.YjsUpdate)
+	}
+
+	return doc, nil
+}
+
+// replaceText rewrites the document text through its live room when one is
+// open on this instance, or on top of the persisted state otherwise, and
+// returns the resulting version. An update stored here reaches the room of
+// the document on another instance through the broker. Documents never opened in the editor have no
+// collaborative state and are left alone. With a base version, the text is
+// only written when the document is still at that version; otherwise
+// domain.ErrVersionConflict is returned, so a stale copy cannot undo the edits
+// made since.
+func (s *DocumentService) replaceText(ctx context.Context, docUUID, userUUID uuid.UUID, text string, baseVersion *int) (int, error) {
+	if s.rooms != nil {
+		version, ok, err := s.rooms.ReplaceText(ctx, docUUID, userUUID, text, baseVersion)
+		if err != nil {
+			return 0, fmt.Errorf("document service: replaceText: %w", err)
+		}
+		if ok {
+			return version, nil
+		}
+	}
+
+	doc, version, err := s.storedState(ctx, docUUID)
+	if err != nil {
+		return 0, err
+	}
+	if baseVersion != nil && *baseVersion != version {
+		return 0, domain.ErrVersionConflict
+	}
+	if version == 0 && doc.IsEmpty() {
+		return 0, nil
+	}
+
+	update := doc.ReplaceText(domain.DocumentTextName, text, yjs.NewClientID())
+	if update == nil {
+		return version, nil
+	}
+	if err = doc.ApplyUpdate(update); err != nil {
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document service: replaceText: %w", err))
+	}
+	version++
+
+	saved, err := s.persistence.SaveUpdate(ctx, docUUID, userUUID, update, version)
+	if err != nil {
+		return 0, fmt.Errorf("document service: replaceText: %w", err)
+	}
+	if !saved {
+		return 0, domain.ErrVersionConflict
+	}
+	if err = s.persistence.SaveSnapshot(ctx, docUUID, doc.EncodeStateAsUpdate(nil), version, userUUID); err != nil {
+		return 0, fmt.Errorf("document service: replaceText: %w", err)
+	}
+	if s.rooms != nil {
+		// A room open on another instance merges the update into its state
+		// and relays it to its editors.
+		s.rooms.RelayUpdate(docUUID, update)
+	}
+
+	return version, nil
+}
+
+// currentVersion returns the version of a document, taken from its live room
+// when one is open on this instance.
+func (s *DocumentService) currentVersion(ctx context.Context, docUUID uuid.UUID) (int, error) {
+	if s.rooms != nil {
+		version, ok, err := s.rooms.Version(ctx, docUUID)
+		if err != nil {
+			return 0, fmt.Errorf("document service: currentVersion: %w", err)
+		}
+		if ok {
+			return version, nil
+		}
+	}
+
+	version, err := s.persistence.GetLatestVersion(ctx, docUUID)
+	if err != nil {
+		return 0, fmt.Errorf("document service: currentVersion: %w", err)
+	}
+	return version, nil
+}
+
+// currentState returns the latest state of a document, taken from its live room
+// when one is open on this instance.
+func (s *DocumentService) currentState(ctx context.Context, docUUID uuid.UUID) (*yjs.Doc, int, error) {
+	if s.rooms != nil {
+		state, version, ok, err := s.rooms.EncodeState(ctx, docUUID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("document service: currentState: %w", err)
+		}
+		if ok {
+			doc, err := yjs.NewDocFromUpdate(state)
+			if err != nil {
+				return nil, 0, errors.Join(domain.ErrInternal, fmt.Errorf("document service: currentState: %w", err))
+			}
+			return doc, version, nil
+		}
+	}
+
+	return s.storedState(ctx, docUUID)
+}
+
+// storedState rebuilds a document from its snapshot and the updates stored
+// after it.
+func (s *DocumentService) storedState(ctx context.Context, docUUID uuid.UUID) (*yjs.Doc, int, error) {
+	doc := yjs.NewDoc()
+	version := 0
+	snapshot, err := s.persistence.LoadSnapshot(ctx, docUUID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("document service: storedState: %w", err)
+	}
+	if snapshot != nil {
+		if err = doc.ApplyUpdate(snapshot.YjsSnapshot); err != nil {
+			return nil, 0, errors.Join(domain.ErrInternal, fmt.Errorf("document service: storedState: snapshot: %w", err))
+		}
+		version = snapshot.Version
+	}
+
+	updates, err := s.persistence.GetUpdates(ctx, docUUID, version)
+	if err != nil {
+		return nil, 0, fmt.Errorf("document service: storedState: %w", err)
+	}
+	for _, update := range updates {
+		if doc.ApplyUpdate(update.YjsUpdate) == nil {
+			version = max(version, update.Version)
+		}
+	}
+
+	return doc, version, nil
+}
@@ -0,0 +1,38 @@
+package document
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
+)
+
+// GetPresence lists the users that currently have the document open.
+func (s *DocumentService) GetPresence(ctx context.Context, docUUID, userUUID uuid.UUID) ([]*domain.Presence, error) {
+	if _, err := s.GetByUUIDForUser(ctx, docUUID, userUUID); err != nil {
+		return nil, err
+	}
+
+	records, err := s.persistence.GetActivePresence(ctx, docUUID)
+	if err != nil {
+		return nil, fmt.Errorf("document service: getPresence: %w", err)
+	}
+
+	return mapPresenceRecords(records), nil
+}
+
+func mapPresenceRecords(records []repo.PresenceRecord) []*domain.Presence {
+	presences := make([]*domain.Presence, len(records))
+	for i, record := range records {
+		presences[i] = &domain.Presence{
+			DocumentUUID:   record.DocumentID,
+			UserUUID:       record.UserID,
+			UserLogin:      record.UserLogin,
+			CursorPosition: record.CursorPosition,
+			LastSeen:       record.LastSeen,
+		}
+	}
+	return presences
+}
@@ -35,6 +35,10 @@ func (s *DocumentService) GetByUUIDForUser(ctx context.Context, documentUUID, us
 		return nil, domain.ErrForbidden
 	}
 
+	if document.Version, err = s.currentVersion(ctx, documentUUID); err != nil {
+		return nil, err
+	}
+
 	return document, nil
 }
 
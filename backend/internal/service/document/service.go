@@ -3,17 +3,48 @@ package document
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo/document"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo/group"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo/member"
 )
 
 type DocumentService struct {
-	repo       *document.DocumentRepository
-	memberRepo *member.MemberRepository
-	shareCfg   ShareConfig
+	repo        *document.DocumentRepository
+	memberRepo  *member.MemberRepository
+	groupRepo   *group.GroupRepository
+	persistence *repo.DocumentPersistence
+	rooms       CollabRooms
+	shareCfg    ShareConfig
+}
+
+// CollabRooms gives the service access to live collaboration rooms.
+type CollabRooms interface {
+	// ReplaceText rewrites the text of a document's room on this instance and
+	// returns the new room version. It reports false when no room is open, and
+	// fails with domain.ErrVersionConflict when baseVersion is set and the room
+	// moved past it.
+	ReplaceText(ctx context.Context, documentID, userID uuid.UUID, text string, baseVersion *int) (int, bool, error)
+	// Version returns the version of a document's room on this instance. It
+	// reports false when no room is open.
+	Version(ctx context.Context, documentID uuid.UUID) (int, bool, error)
+	// EncodeState returns the merged state and version of a document's room on
+	// this instance. It reports false when no room is open.
+	EncodeState(ctx context.Context, documentID uuid.UUID) ([]byte, int, bool, error)
+	// RelayUpdate hands a stored update to the rooms of the document on other
+	// instances.
+	RelayUpdate(documentID uuid.UUID, update []byte)
+	// CloseRoom disconnects the clients of a deleted document and discards
+	// its room without saving it. It returns once the room on this instance
+	// stopped.
+	CloseRoom(ctx context.Context, documentID uuid.UUID)
+	// NotifyPermissionChange applies an access change to the open sessions
+	// on every instance.
+	NotifyPermissionChange(ctx context.Context, change domain.PermissionChange)
 }
 
 type ShareConfig struct {
@@ -21,17 +52,38 @@ type ShareConfig struct {
 	BaseURL               string
 	DefaultExpirationDays int
 	MaxExpirationDays     int
+	// HashingCost is the bcrypt cost of share link passwords.
+	HashingCost int
+	// UnlockDuration is how long the guest token of a password-protected
+	// link lasts.
+	UnlockDuration time.Duration
+	// UnlockAttempts wrong passwords lock a link until UnlockWindow, counted
+	// from the first of them, has passed.
+	UnlockAttempts int
+	UnlockWindow   time.Duration
+	// VisitDuration is how long the visit token of an opened link lasts;
+	// requests carrying it do not count as further uses of the link.
+	VisitDuration time.Duration
+	// AnalyticsRetention is how long share link accesses are kept; zero
+	// keeps them forever.
+	AnalyticsRetention time.Duration
 }
 
 func NewDocumentService(
-	repo *document.DocumentRepository,
+	documentRepo *document.DocumentRepository,
 	memberRepo *member.MemberRepository,
+	groupRepo *group.GroupRepository,
+	persistence *repo.DocumentPersistence,
+	rooms CollabRooms,
 	shareCfg ShareConfig,
 ) *DocumentService {
 	return &DocumentService{
-		repo:       repo,
-		memberRepo: memberRepo,
-		shareCfg:   shareCfg,
+		repo:        documentRepo,
+		memberRepo:  memberRepo,
+		groupRepo:   groupRepo,
+		persistence: persistence,
+		rooms:       rooms,
+		shareCfg:    shareCfg,
 	}
 }
 
@@ -7,11 +7,14 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
 )
@@ -19,38 +22,126 @@ import (
 const (
 	minShareExpirationDays = 1
 	defaultMaxExpiration   = 90
+	defaultUnlockDuration  = time.Hour
+	defaultUnlockAttempts  = 5
+	defaultUnlockWindow    = 15 * time.Minute
+	defaultVisitDuration   = 12 * time.Hour
+	// guestTokenAudience and visitTokenAudience tell guest and visit tokens
+	// apart from each other and from other tokens signed with the share secret.
+	guestTokenAudience = "share-link"
+	visitTokenAudience = "share-visit"
 )
 
-var ErrInvalidExpiration = errors.New("invalid expiration days")
+var (
+	ErrInvalidExpiration     = errors.New("invalid expiration days")
+	ErrInvalidShareRole      = errors.New("invalid share role")
+	ErrShareLinkNotProtected = errors.New("share link has no password")
+	ErrInvalidSharePassword  = errors.New("invalid share link password")
+)
 
+// GenerateShareLink stores and signs a link granting a role on a document until
+// it expires. An empty role shares the document read-only.
 func (s *DocumentService) GenerateShareLink(
 	ctx context.Context,
 	docUUID, userUUID uuid.UUID,
-	expirationDays int,
-) (string, time.Time, error) {
+	opts domain.ShareLinkOptions,
+) (*domain.ShareLink, error) {
 	if s.shareCfg.Secret == "" || s.shareCfg.BaseURL == "" {
-		return "", time.Time{}, domain.ErrInternal
+		return nil, domain.ErrInternal
+	}
+	role := opts.Role
+	if role == "" {
+		role = domain.RoleViewer
+	}
+	if !isShareRole(role) {
+		return nil, ErrInvalidShareRole
 	}
 
-	doc, err := s.GetByUUID(ctx, docUUID)
+	if _, err := s.shareManager(ctx, docUUID, userUUID); err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := s.shareExpiry(opts.ExpirationDays)
 	if err != nil {
-		return "", time.Time{}, err
+		return nil, err
 	}
 
-	member, err := s.memberRepo.GetMember(ctx, doc.GroupUUID, userUUID)
+	var passwordHash string
+	if opts.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(opts.Password), s.shareCfg.HashingCost)
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return nil, ErrInvalidSharePassword
+		}
+		if err != nil {
+			return nil, fmt.Errorf("document service: share: %w", err)
+		}
+		passwordHash = string(hash)
+	}
+
+	link, err := s.repo.CreateShareLink(ctx, &domain.ShareLink{
+		DocumentUUID: docUUID,
+		CreatedBy:    userUUID,
+		Role:         role,
+		ExpiresAt:    expiresAt,
+		MaxUses:      opts.MaxUses,
+		PasswordHash: passwordHash,
+	})
 	if err != nil {
-		return "", time.Time{}, fmt.Errorf("document service: share: %w", err)
+		return nil, fmt.Errorf("document service: share: %w", err)
 	}
-	if member == nil || member.Role == domain.RoleViewer {
-		return "", time.Time{}, domain.ErrForbidden
+	link.URL = s.shareURL(link)
+
+	return link, nil
+}
+
+// GetShareLinks lists the share links of a document, newest first, including
+// revoked and expired ones.
+func (s *DocumentService) GetShareLinks(ctx context.Context, docUUID, userUUID uuid.UUID) ([]*domain.ShareLink, error) {
+	if _, err := s.shareManager(ctx, docUUID, userUUID); err != nil {
+		return nil, err
 	}
 
-	effectiveDays := expirationDays
-	if effectiveDays == 0 {
-		effectiveDays = s.shareCfg.DefaultExpirationDays
+	links, err := s.repo.GetShareLinks(ctx, docUUID)
+	if err != nil {
+		return nil, fmt.Errorf("document service: getShareLinks: %w", err)
+	}
+	for _, link := range links {
+		link.URL = s.shareURL(link)
+	}
+
+	return links, nil
+}
+
+// RevokeShareLink stops a share link of a document from working, for good.
+// Guests connected through it are disconnected.
+func (s *DocumentService) RevokeShareLink(ctx context.Context, docUUID, linkUUID, userUUID uuid.UUID) error {
+	if _, err := s.shareManager(ctx, docUUID, userUUID); err != nil {
+		return err
+	}
+
+	ok, err := s.repo.RevokeShareLink(ctx, docUUID, linkUUID)
+	if err != nil {
+		return fmt.Errorf("document service: revokeShareLink: %w", err)
 	}
-	if effectiveDays == 0 {
-		effectiveDays = minShareExpirationDays
+	if !ok {
+		return domain.ErrShareLinkNotFound
+	}
+
+	if s.rooms != nil {
+		s.rooms.NotifyPermissionChange(ctx, domain.PermissionChange{ShareLinkUUID: linkUUID})
+	}
+
+	return nil
+}
+
+// shareExpiry returns when a link shared for the given days expires, applying
+// the default and the limits of the share config. Zero days uses the default.
+func (s *DocumentService) shareExpiry(days int) (time.Time, error) {
+	if days == 0 {
+		days = s.shareCfg.DefaultExpirationDays
+	}
+	if days == 0 {
+		days = minShareExpirationDays
 	}
 
 	maxDays := s.shareCfg.MaxExpirationDays
@@ -58,42 +149,209 @@ func (s *DocumentService) GenerateShareLink(
 		maxDays = defaultMaxExpiration
 	}
 
-	if effectiveDays < minShareExpirationDays || effectiveDays > maxDays {
-		return "", time.Time{}, ErrInvalidExpiration
+	if days < minShareExpirationDays || days > maxDays {
+		return time.Time{}, ErrInvalidExpiration
 	}
 
-	expiresAt := time.Now().UTC().Add(time.Duration(effectiveDays) * 24 * time.Hour).Truncate(time.Second)
+	return time.Now().UTC().Add(time.Duration(days) * 24 * time.Hour).Truncate(time.Second), nil
+}
 
-	signature := s.computeSignature(docUUID, expiresAt)
-	shareURL := fmt.Sprintf(
-		"%s/documents/public?doc=%s&sig=%s&exp=%d",
-		strings.TrimRight(s.shareCfg.BaseURL, "/"),
-		docUUID.String(),
-		signature,
-		expiresAt.Unix(),
-	)
+// shareManager returns the document if the user may share it: editors and
+// authors of its group can.
+func (s *DocumentService) shareManager(ctx context.Context, docUUID, userUUID uuid.UUID) (*domain.Document, error) {
+	doc, err := s.GetByUUID(ctx, docUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.memberRepo.GetMember(ctx, doc.GroupUUID, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("document service: share: %w", err)
+	}
+	if member == nil || member.Role == domain.RoleViewer {
+		return nil, domain.ErrForbidden
+	}
 
-	return shareURL, expiresAt, nil
+	return doc, nil
 }
 
-func (s *DocumentService) ValidateShareLink(
+// OpenShareLink checks the parameters of a share link and returns the shared
+// document with the role the link grants. Links without a role are read-only.
+// Stored links must not be revoked, and password-protected ones need the
+// guest token issued by UnlockShareLink; links issued before links were
+// stored are only checked against their signature.
+//
+// Opening a stored link counts a use of it, and links with no uses left are
+// refused. The returned visit proves the use: requests carrying its token
+// until it expires, such as the collaboration socket of the page that opened
+// the link, do not count again. The visit is nil when no use was counted.
+func (s *DocumentService) OpenShareLink(
 	ctx context.Context,
-	docParam, sigParam, expParam string,
-) (*domain.Document, error) {
+	query domain.ShareLinkQuery,
+) (*domain.Document, string, *domain.ShareVisit, error) {
+	doc, role, link, err := s.accessShareLink(ctx, query)
+	if err != nil || link.UUID == uuid.Nil {
+		return doc, role, nil, err
+	}
+
+	visit, err := s.useShareLink(ctx, link, query.Visit)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return doc, role, visit, nil
+}
+
+// useShareLink counts a use of a stored share link, unless visitToken proves
+// an earlier one, and returns the visit proving the new use. The visit is nil
+// when no use was counted.
+func (s *DocumentService) useShareLink(ctx context.Context, link *domain.ShareLink, visitToken string) (*domain.ShareVisit, error) {
+	if s.validLinkToken(visitToken, visitTokenAudience, link.UUID) {
+		return nil, nil
+	}
+
+	ok, err := s.repo.UseShareLink(ctx, link.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("document service: useShareLink: %w", err)
+	}
+	if !ok {
+		return nil, domain.ErrShareLinkUsedUp
+	}
+
+	duration := s.shareCfg.VisitDuration
+	if duration <= 0 {
+		duration = defaultVisitDuration
+	}
+	visit := &domain.ShareVisit{ExpiresAt: time.Now().UTC().Add(duration).Truncate(time.Second)}
+	if visit.ExpiresAt.After(link.ExpiresAt) {
+		visit.ExpiresAt = link.ExpiresAt
+	}
+	if visit.Token, err = s.signLinkToken(visitTokenAudience, link.UUID, visit.ExpiresAt); err != nil {
+		return nil, fmt.Errorf("document service: useShareLink: %w", err)
+	}
+
+	return visit, nil
+}
+
+// UnlockShareLink checks the password of a password-protected share link and
+// returns a guest token for it, valid until the returned time. Wrong
+// passwords are throttled per link: once UnlockAttempts of them were given
+// within UnlockWindow, the link refuses any password until the window ends.
+func (s *DocumentService) UnlockShareLink(
+	ctx context.Context,
+	query domain.ShareLinkQuery,
+	password string,
+) (string, time.Time, error) {
+	link, err := s.validateShareLink(ctx, query)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if link.PasswordHash == "" {
+		return "", time.Time{}, ErrShareLinkNotProtected
+	}
+
+	window := s.shareCfg.UnlockWindow
+	if window <= 0 {
+		window = defaultUnlockWindow
+	}
+	maxAttempts := s.shareCfg.UnlockAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultUnlockAttempts
+	}
+
+	// The attempt is counted before the password is checked, so that
+	// concurrent guesses cannot all slip under the limit.
+	attempts, err := s.repo.AddUnlockAttempt(ctx, link.UUID, time.Now().Add(-window))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("document service: unlockShareLink: %w", err)
+	}
+	if attempts > maxAttempts {
+		return "", time.Time{}, domain.ErrTooManyAttempts
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)); err != nil {
+		return "", time.Time{}, domain.ErrWrongPassword
+	}
+
+	if err := s.repo.ForgetUnlockAttempt(ctx, link.UUID); err != nil {
+		return "", time.Time{}, fmt.Errorf("document service: unlockShareLink: %w", err)
+	}
+
+	duration := s.shareCfg.UnlockDuration
+	if duration <= 0 {
+		duration = defaultUnlockDuration
+	}
+	expiresAt := time.Now().UTC().Add(duration).Truncate(time.Second)
+	if expiresAt.After(link.ExpiresAt) {
+		expiresAt = link.ExpiresAt
+	}
+
+	token, err := s.signLinkToken(guestTokenAudience, link.UUID, expiresAt)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("document service: unlockShareLink: %w", err)
+	}
+
+	return token, expiresAt, nil
+}
+
+// accessShareLink is OpenShareLink without counting a use; it also returns
+// the link.
+func (s *DocumentService) accessShareLink(
+	ctx context.Context,
+	query domain.ShareLinkQuery,
+) (*domain.Document, string, *domain.ShareLink, error) {
+	link, err := s.validateShareLink(ctx, query)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if link.PasswordHash != "" && !s.validLinkToken(query.Token, guestTokenAudience, link.UUID) {
+		return nil, "", nil, domain.ErrShareLinkLocked
+	}
+
+	doc, err := s.GetByUUID(ctx, link.DocumentUUID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if doc.Version, err = s.currentVersion(ctx, doc.UUID); err != nil {
+		return nil, "", nil, err
+	}
+
+	return doc, link.Role, link, nil
+}
+
+// validateShareLink checks the signature of a share link and, for a stored
+// link, its row, but not its password. Stateless links are described by a
+// ShareLink without a UUID.
+func (s *DocumentService) validateShareLink(ctx context.Context, query domain.ShareLinkQuery) (*domain.ShareLink, error) {
 	if s.shareCfg.Secret == "" {
 		return nil, domain.ErrInternal
 	}
 
-	if docParam == "" || sigParam == "" || expParam == "" {
+	if query.Doc == "" || query.Sig == "" || query.Exp == "" {
 		return nil, domain.ErrShareLinkInvalid
 	}
 
-	docUUID, err := uuid.Parse(docParam)
+	role := query.Role
+	if role == "" {
+		role = domain.RoleViewer
+	}
+	if !isShareRole(role) {
+		return nil, domain.ErrShareLinkInvalid
+	}
+
+	docUUID, err := uuid.Parse(query.Doc)
 	if err != nil {
 		return nil, domain.ErrShareLinkInvalid
 	}
 
-	expTS, err := strconv.ParseInt(expParam, 10, 64)
+	linkUUID := uuid.Nil
+	if query.Link != "" {
+		if linkUUID, err = uuid.Parse(query.Link); err != nil {
+			return nil, domain.ErrShareLinkInvalid
+		}
+	}
+
+	expTS, err := strconv.ParseInt(query.Exp, 10, 64)
 	if err != nil {
 		return nil, domain.ErrShareLinkInvalid
 	}
@@ -103,22 +361,119 @@ func (s *DocumentService) ValidateShareLink(
 		return nil, domain.ErrShareLinkExpired
 	}
 
-	expectedSig := s.computeSignature(docUUID, expiresAt)
-	if !hmac.Equal([]byte(expectedSig), []byte(sigParam)) {
+	expectedSig := s.computeSignature(docUUID, expiresAt, role, linkUUID)
+	if !hmac.Equal([]byte(expectedSig), []byte(query.Sig)) {
 		return nil, domain.ErrShareLinkInvalid
 	}
 
-	doc, err := s.GetByUUID(ctx, docUUID)
+	if linkUUID == uuid.Nil {
+		return &domain.ShareLink{DocumentUUID: docUUID, Role: role, ExpiresAt: expiresAt}, nil
+	}
+
+	link, err := s.repo.GetShareLink(ctx, linkUUID)
+	if err != nil {
+		return nil, fmt.Errorf("document service: validateShareLink: %w", err)
+	}
+	if link == nil || link.DocumentUUID != docUUID {
+		return nil, domain.ErrShareLinkInvalid
+	}
+	if link.Revoked {
+		return nil, domain.ErrShareLinkRevoked
+	}
+
+	return link, nil
+}
+
+// signLinkToken issues a token for the share link, meant for audience and
+// valid until expiresAt.
+func (s *DocumentService) signLinkToken(audience string, linkUUID uuid.UUID, expiresAt time.Time) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   linkUUID.String(),
+		Audience:  jwt.ClaimStrings{audience},
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}).SignedString([]byte(s.shareCfg.Secret))
+}
+
+// validLinkToken reports whether token was issued for the share link and
+// audience and has not expired yet.
+func (s *DocumentService) validLinkToken(token, audience string, linkUUID uuid.UUID) bool {
+	if token == "" {
+		return false
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(s.shareCfg.Secret), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return false
+	}
+
+	return claims.Subject == linkUUID.String()
+}
+
+// UpdateShared updates a document through an editor share link. Like opening
+// the link, it counts a use unless the query carries a visit token. The new
+// text is attributed to the share-link guest user.
+func (s *DocumentService) UpdateShared(
+	ctx context.Context,
+	query domain.ShareLinkQuery,
+	update domain.DocumentUpdate,
+) (*domain.Document, error) {
+	doc, role, _, err := s.OpenShareLink(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	if role != domain.RoleEditor {
+		return nil, domain.ErrForbidden
+	}
 
-	return doc, nil
+	return s.update(ctx, doc.UUID, domain.GuestUserUUID, update)
 }
 
-func (s *DocumentService) computeSignature(docUUID uuid.UUID, expiresAt time.Time) string {
-	mac := hmac.New(sha256.New, []byte(s.shareCfg.Secret))
+// shareURL builds the public URL of a stored share link.
+func (s *DocumentService) shareURL(link *domain.ShareLink) string {
+	params := url.Values{}
+	params.Set("doc", link.DocumentUUID.String())
+	params.Set("sig", s.computeSignature(link.DocumentUUID, link.ExpiresAt, link.Role, link.UUID))
+	params.Set("exp", strconv.FormatInt(link.ExpiresAt.Unix(), 10))
+	params.Set("link", link.UUID.String())
+	if link.Role != domain.RoleViewer {
+		params.Set("role", link.Role)
+	}
+
+	return fmt.Sprintf("%s/documents/public?%s", strings.TrimRight(s.shareCfg.BaseURL, "/"), params.Encode())
+}
+
+// computeSignature signs a share link. Stored links sign their role and UUID
+// too; stateless read-only links sign the document and expiry alone, so that
+// links issued before roles existed stay valid.
+func (s *DocumentService) computeSignature(docUUID uuid.UUID, expiresAt time.Time, role string, linkUUID uuid.UUID) string {
 	payload := fmt.Sprintf("%s:%d", docUUID.String(), expiresAt.Unix())
+	switch {
+	case linkUUID != uuid.Nil:
+		payload += ":" + role + ":" + linkUUID.String()
+	case role != domain.RoleViewer:
+		payload += ":" + role
+	}
+	return s.sign(payload)
+}
+
+// sign returns the hex HMAC of a share link payload.
+func (s *DocumentService) sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(s.shareCfg.Secret))
 	_, _ = mac.Write([]byte(payload))
 	return hex.EncodeToString(mac.Sum(nil))
 }
+
+func isShareRole(role string) bool {
+	switch role {
+	case domain.RoleViewer, domain.RoleCommenter, domain.RoleEditor:
+		return true
+	}
+	return false
+}
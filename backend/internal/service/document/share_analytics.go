@@ -0,0 +1,179 @@
+package document
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+)
+
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 365
+)
+
+var ErrInvalidAnalyticsDays = errors.New("invalid analytics days")
+
+// crawlerWords are the words of user agents that mark automated visitors:
+// generic ones and the names of common crawlers and link previewers. Only
+// whole words count, so that devices such as Cubot phones are not bots.
+var crawlerWords = map[string]bool{
+	"bot": true, "crawler": true, "spider": true, "preview": true,
+	"googlebot": true, "bingbot": true, "duckduckbot": true, "yandexbot": true,
+	"baiduspider": true, "applebot": true, "slurp": true, "petalbot": true,
+	"ahrefsbot": true, "semrushbot": true, "facebookexternalhit": true,
+	"twitterbot": true, "linkedinbot": true, "slackbot": true,
+	"discordbot": true, "telegrambot": true, "whatsapp": true,
+}
+
+// userAgentFamilies maps markers found in user agents to browser families.
+// The first match wins, so browsers built on others come before them.
+var userAgentFamilies = []struct {
+	family  string
+	markers []string
+}{
+	{family: "edge", markers: []string{"edg/", "edga/", "edgios/"}},
+	{family: "opera", markers: []string{"opr/", "opera"}},
+	{family: "firefox", markers: []string{"firefox/", "fxios/"}},
+	{family: "chrome", markers: []string{"chrome/", "crios/", "chromium/"}},
+	{family: "safari", markers: []string{"safari/"}},
+}
+
+// RecordShareLinkAccess records a use of a share link that was validated for
+// a document. Visitors are recorded by a keyed hash of their IP address and
+// the family of their user agent only.
+func (s *DocumentService) RecordShareLinkAccess(
+	ctx context.Context,
+	docUUID uuid.UUID,
+	query domain.ShareLinkQuery,
+	visit domain.ShareLinkVisit,
+) error {
+	access := &domain.ShareLinkAccess{
+		DocumentUUID:    docUUID,
+		IPHash:          s.visitorHash(visit.IP),
+		UserAgentFamily: userAgentFamily(visit.UserAgent),
+		Channel:         visit.Channel,
+		AccessedAt:      time.Now().UTC(),
+	}
+	if linkUUID, err := uuid.Parse(query.Link); err == nil {
+		access.LinkUUID = linkUUID
+	} else {
+		sum := sha256.Sum256([]byte(query.Sig))
+		access.SignatureHash = hex.EncodeToString(sum[:])
+	}
+
+	if err := s.repo.CreateShareLinkAccess(ctx, access); err != nil {
+		return fmt.Errorf("document service: recordShareLinkAccess: %w", err)
+	}
+
+	return nil
+}
+
+// GetShareLinkAnalytics counts the uses of a document's share links over the
+// last days, today included, with a row for every UTC day. Zero days uses
+// the default.
+func (s *DocumentService) GetShareLinkAnalytics(
+	ctx context.Context,
+	docUUID, userUUID uuid.UUID,
+	days int,
+) (*domain.ShareLinkAnalytics, error) {
+	if days == 0 {
+		days = defaultAnalyticsDays
+	}
+	if days < 1 || days > maxAnalyticsDays {
+		return nil, ErrInvalidAnalyticsDays
+	}
+
+	if _, err := s.shareManager(ctx, docUUID, userUUID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	since := today.AddDate(0, 0, 1-days)
+
+	analytics, err := s.repo.GetShareLinkAnalytics(ctx, docUUID, since)
+	if err != nil {
+		return nil, fmt.Errorf("document service: getShareLinkAnalytics: %w", err)
+	}
+
+	recorded := make(map[time.Time]domain.ShareLinkDailyAccesses, len(analytics.Days))
+	for _, daily := range analytics.Days {
+		recorded[daily.Day] = daily
+	}
+	analytics.Days = make([]domain.ShareLinkDailyAccesses, days)
+	for i := range analytics.Days {
+		day := since.AddDate(0, 0, i)
+		daily, ok := recorded[day]
+		if !ok {
+			daily = domain.ShareLinkDailyAccesses{Day: day}
+		}
+		analytics.Days[i] = daily
+	}
+
+	return analytics, nil
+}
+
+// PruneShareLinkAccesses deletes the share link accesses older than the
+// analytics retention and returns how many were deleted.
+func (s *DocumentService) PruneShareLinkAccesses(ctx context.Context) (int64, error) {
+	if s.shareCfg.AnalyticsRetention <= 0 {
+		return 0, nil
+	}
+
+	deleted, err := s.repo.DeleteShareLinkAccesses(ctx, time.Now().Add(-s.shareCfg.AnalyticsRetention))
+	if err != nil {
+		return 0, fmt.Errorf("document service: pruneShareLinkAccesses: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// visitorHash hashes an IP address with the share secret, so that stored
+// hashes cannot be reversed by hashing every address.
+func (s *DocumentService) visitorHash(ip string) string {
+	mac := hmac.New(sha256.New, []byte(s.shareCfg.Secret))
+	_, _ = mac.Write([]byte("visitor:" + ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func userAgentFamily(userAgent string) string {
+	if userAgent == "" {
+		return "unknown"
+	}
+
+	lower := strings.ToLower(userAgent)
+	if isCrawler(lower) {
+		return "bot"
+	}
+	for _, candidate := range userAgentFamilies {
+		for _, marker := range candidate.markers {
+			if strings.Contains(lower, marker) {
+				return candidate.family
+			}
+		}
+	}
+	return "other"
+}
+
+// isCrawler reports whether a lower-case user agent has a crawler word.
+func isCrawler(userAgent string) bool {
+	words := strings.FieldsFunc(userAgent, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if crawlerWords[word] {
+			return true
+		}
+	}
+	return false
+}
@@ -3,12 +3,14 @@ package document
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
 )
 
-func (s *DocumentService) Update(ctx context.Context, docUUID, userUUID uuid.UUID, name, content string) (*domain.Document, error) {
+func (s *DocumentService) Update(ctx context.Context, docUUID, userUUID uuid.UUID, update domain.DocumentUpdate) (*domain.Document, error) {
 	doc, err := s.GetByUUID(ctx, docUUID)
 	if err != nil {
 		return nil, err
@@ -25,13 +27,52 @@ func (s *DocumentService) Update(ctx context.Context, docUUID, userUUID uuid.UUI
 		return nil, domain.ErrForbidden
 	}
 
-	updatedDoc, err := s.repo.Update(ctx, docUUID, name, content)
+	return s.update(ctx, docUUID, userUUID, update)
+}
+
+// update writes the name, text and tags of a document on behalf of userUUID, whose
+// access was already checked.
+func (s *DocumentService) update(ctx context.Context, docUUID, userUUID uuid.UUID, update domain.DocumentUpdate) (*domain.Document, error) {
+	var (
+		version int
+		err     error
+	)
+	if update.Content != nil {
+		// Live editors and the stored collaborative state get the new text as
+		// a regular Yjs update, so they converge with the REST write.
+		version, err = s.replaceText(ctx, docUUID, userUUID, *update.Content, update.BaseVersion)
+	} else {
+		version, err = s.currentVersion(ctx, docUUID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	updatedDoc, err := s.repo.Update(ctx, docUUID, update.Name, update.Content, normalizeTags(update.Tags))
 	if err != nil {
 		return nil, fmt.Errorf("document service: update: %w", err)
 	}
 	if updatedDoc == nil {
 		return nil, domain.ErrDocumentNotFound
 	}
+	updatedDoc.Version = version
 
 	return updatedDoc, nil
 }
+
+// normalizeTags trims tags and drops empty and repeated ones; nil stays nil so
+// that the tags are left alone.
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
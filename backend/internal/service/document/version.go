@@ -0,0 +1,143 @@
+package document
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/linediff"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+)
+
+// CreateVersion captures the current state of a document as a named checkpoint.
+// Only editors and authors of the document's group may create checkpoints.
+func (s *DocumentService) CreateVersion(
+	ctx context.Context,
+	docUUID, userUUID uuid.UUID,
+	name string,
+) (*domain.DocumentVersion, error) {
+	doc, err := s.GetByUUID(ctx, docUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.memberRepo.GetMember(ctx, doc.GroupUUID, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("document service: createVersion: %w", err)
+	}
+	if member == nil || (member.Role != domain.RoleEditor && member.Role != domain.RoleAuthor) {
+		return nil, domain.ErrForbidden
+	}
+
+	state, version, err := s.currentState(ctx, docUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateVersion(ctx, &domain.DocumentVersion{
+		DocumentUUID: docUUID,
+		Name:         name,
+		Content:      documentText(doc, state),
+		YjsSnapshot:  state.EncodeStateAsUpdate(nil),
+		Version:      version,
+		CreatedBy:    userUUID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("document service: createVersion: %w", err)
+	}
+	if created == nil {
+		return nil, domain.ErrAlreadyExists
+	}
+
+	return created, nil
+}
+
+// GetVersions lists the checkpoints of a document, newest first.
+func (s *DocumentService) GetVersions(ctx context.Context, docUUID, userUUID uuid.UUID) ([]*domain.DocumentVersion, error) {
+	if _, err := s.GetByUUIDForUser(ctx, docUUID, userUUID); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.repo.GetVersions(ctx, docUUID)
+	if err != nil {
+		return nil, fmt.Errorf("document service: getVersions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// GetVersion returns a checkpoint with its Markdown content.
+func (s *DocumentService) GetVersion(ctx context.Context, docUUID, versionUUID, userUUID uuid.UUID) (*domain.DocumentVersion, error) {
+	if _, err := s.GetByUUIDForUser(ctx, docUUID, userUUID); err != nil {
+		return nil, err
+	}
+
+	return s.getVersion(ctx, docUUID, versionUUID)
+}
+
+// DiffVersions compares the Markdown of a checkpoint line by line with another
+// checkpoint, or with the current document when toUUID is uuid.Nil.
+func (s *DocumentService) DiffVersions(
+	ctx context.Context,
+	docUUID, fromUUID, toUUID, userUUID uuid.UUID,
+) (*domain.DocumentVersionDiff, error) {
+	doc, err := s.GetByUUIDForUser(ctx, docUUID, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.getVersion(ctx, docUUID, fromUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var toContent string
+	if toUUID == uuid.Nil {
+		state, _, err := s.currentState(ctx, docUUID)
+		if err != nil {
+			return nil, err
+		}
+		toContent = documentText(doc, state)
+	} else {
+		to, err := s.getVersion(ctx, docUUID, toUUID)
+		if err != nil {
+			return nil, err
+		}
+		toContent = to.Content
+	}
+
+	diff := &domain.DocumentVersionDiff{FromUUID: fromUUID, ToUUID: toUUID}
+	for _, line := range linediff.Diff(from.Content, toContent) {
+		switch line.Op {
+		case linediff.Insert:
+			diff.Added++
+		case linediff.Delete:
+			diff.Removed++
+		}
+		diff.Lines = append(diff.Lines, domain.DiffLine{Op: line.Op.String(), Text: line.Text})
+	}
+
+	return diff, nil
+}
+
+// documentText returns the Markdown of a document. Documents never opened in
+// the editor only have their REST content.
+func documentText(doc *domain.Document, state *yjs.Doc) string {
+	if state.IsEmpty() {
+		return doc.Content
+	}
+	return state.Text(domain.DocumentTextName)
+}
+
+func (s *DocumentService) getVersion(ctx context.Context, docUUID, versionUUID uuid.UUID) (*domain.DocumentVersion, error) {
+	version, err := s.repo.GetVersion(ctx, docUUID, versionUUID)
+	if err != nil {
+		return nil, fmt.Errorf("document service: getVersion: %w", err)
+	}
+	if version == nil {
+		return nil, domain.ErrVersionNotFound
+	}
+
+	return version, nil
+}
@@ -21,6 +21,12 @@ func (s *GroupService) Delete(ctx context.Context, userUUID, groupUUID uuid.UUID
 		return domain.ErrForbidden
 	}
 
+	// The documents are deleted along with the group, so list them first.
+	documentUUIDs, err := s.repo.GetDocumentUUIDs(ctx, groupUUID)
+	if err != nil {
+		return fmt.Errorf("group service: delete: %w", err)
+	}
+
 	err = s.repo.Delete(ctx, groupUUID)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -29,5 +35,11 @@ func (s *GroupService) Delete(ctx context.Context, userUUID, groupUUID uuid.UUID
 		return fmt.Errorf("group service: delete: %w", err)
 	}
 
+	if s.rooms != nil {
+		for _, documentUUID := range documentUUIDs {
+			s.rooms.CloseRoom(ctx, documentUUID)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,34 @@
+package group
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+)
+
+// GetPresence lists the users that currently have a document of the group open.
+func (s *GroupService) GetPresence(ctx context.Context, groupUUID, userUUID uuid.UUID) ([]*domain.Presence, error) {
+	if _, err := s.GetByUUIDForUser(ctx, groupUUID, userUUID); err != nil {
+		return nil, err
+	}
+
+	records, err := s.persistence.GetGroupPresence(ctx, groupUUID)
+	if err != nil {
+		return nil, fmt.Errorf("group service: getPresence: %w", err)
+	}
+
+	presences := make([]*domain.Presence, len(records))
+	for i, record := range records {
+		presences[i] = &domain.Presence{
+			DocumentUUID:   record.DocumentID,
+			UserUUID:       record.UserID,
+			UserLogin:      record.UserLogin,
+			CursorPosition: record.CursorPosition,
+			LastSeen:       record.LastSeen,
+		}
+	}
+
+	return presences, nil
+}
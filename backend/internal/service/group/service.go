@@ -1,18 +1,36 @@
 package group
 
 import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo/group"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo/member"
 )
 
+// CollabRooms closes the live collaboration rooms of deleted documents.
+type CollabRooms interface {
+	CloseRoom(ctx context.Context, documentID uuid.UUID)
+}
+
 type GroupService struct {
-	repo       *group.GroupRepository
-	memberRepo *member.MemberRepository
+	repo        *group.GroupRepository
+	memberRepo  *member.MemberRepository
+	persistence *repo.DocumentPersistence
+	rooms       CollabRooms
 }
 
-func NewGroupService(repo *group.GroupRepository, memberRepo *member.MemberRepository) *GroupService {
+func NewGroupService(
+	groupRepo *group.GroupRepository,
+	memberRepo *member.MemberRepository,
+	persistence *repo.DocumentPersistence,
+	rooms CollabRooms,
+) *GroupService {
 	return &GroupService{
-		repo:       repo,
-		memberRepo: memberRepo,
+		repo:        groupRepo,
+		memberRepo:  memberRepo,
+		persistence: persistence,
+		rooms:       rooms,
 	}
 }
@@ -8,7 +8,15 @@ import (
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
 )
 
-func (s *GroupService) Update(ctx context.Context, userUUID, groupUUID uuid.UUID, name string) (*domain.Group, error) {
+// Update renames a group and, when maxDocumentSize is set, changes the size
+// limit of its documents. Open documents keep their limit until they are
+// reopened.
+func (s *GroupService) Update(
+	ctx context.Context,
+	userUUID, groupUUID uuid.UUID,
+	name string,
+	maxDocumentSize *int,
+) (*domain.Group, error) {
 	member, err := s.memberRepo.GetMember(ctx, groupUUID, userUUID)
 	if err != nil {
 		return nil, fmt.Errorf("group service: update member: %w", err)
@@ -20,7 +28,7 @@ func (s *GroupService) Update(ctx context.Context, userUUID, groupUUID uuid.UUID
 		return nil, domain.ErrForbidden
 	}
 
-	group, err := s.repo.Update(ctx, groupUUID, name)
+	group, err := s.repo.Update(ctx, groupUUID, name, maxDocumentSize)
 	if err != nil {
 		return nil, fmt.Errorf("group service: update: %w", err)
 	}
@@ -47,6 +47,7 @@ func (s *MemberService) DeleteMemberByUser(ctx context.Context, userUUID, groupU
 		}
 		return fmt.Errorf("member service: delete member: %w", err)
 	}
+	s.notifyPermissionChange(ctx, groupUUID, memberUUID, "")
 
 	return nil
 }
@@ -1,22 +1,45 @@
 package member
 
 import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo/group"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo/member"
 	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo/user"
 )
 
+// PermissionNotifier is told about role changes so that open collaboration
+// sessions can follow them.
+type PermissionNotifier interface {
+	NotifyPermissionChange(ctx context.Context, change domain.PermissionChange)
+}
+
 type MemberService struct {
-	repo      *member.MemberRepository
-	groupRepo *group.GroupRepository
-	userRepo  *user.UserRepository
+	repo        *member.MemberRepository
+	groupRepo   *group.GroupRepository
+	userRepo    *user.UserRepository
+	permissions PermissionNotifier
 }
 
 func NewMemberService(repo *member.MemberRepository, groupRepo *group.GroupRepository,
-	userRepo *user.UserRepository) *MemberService {
+	userRepo *user.UserRepository, permissions PermissionNotifier) *MemberService {
 	return &MemberService{
-		repo:      repo,
-		groupRepo: groupRepo,
-		userRepo:  userRepo,
+		repo:        repo,
+		groupRepo:   groupRepo,
+		userRepo:    userRepo,
+		permissions: permissions,
+	}
+}
+
+func (s *MemberService) notifyPermissionChange(ctx context.Context, groupUUID, userUUID uuid.UUID, role string) {
+	if s.permissions == nil {
+		return
 	}
+	s.permissions.NotifyPermissionChange(ctx, domain.PermissionChange{
+		GroupUUID: groupUUID,
+		UserUUID:  userUUID,
+		Role:      role,
+	})
 }
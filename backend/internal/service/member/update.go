@@ -53,8 +53,13 @@ func (s *MemberService) UpdateMemberByUser(ctx context.Context, userUUID, groupU
 			return nil, domain.ErrUserNotFound
 		}
 
+		s.notifyPermissionChange(ctx, groupUUID, memberUUID, role)
+
 		// unlucky if fails
-		_, _ = s.repo.UpdateMember(ctx, groupUUID, actor.UserUUID, domain.RoleEditor) //nolint:errcheck
+		demoted, err := s.repo.UpdateMember(ctx, groupUUID, actor.UserUUID, domain.RoleEditor)
+		if err == nil && demoted != nil {
+			s.notifyPermissionChange(ctx, groupUUID, actor.UserUUID, domain.RoleEditor)
+		}
 
 		return member, nil
 	}
@@ -66,6 +71,7 @@ func (s *MemberService) UpdateMemberByUser(ctx context.Context, userUUID, groupU
 	if member == nil {
 		return nil, domain.ErrUserNotFound
 	}
+	s.notifyPermissionChange(ctx, groupUUID, memberUUID, role)
 
 	return member, nil
 }
@@ -0,0 +1,170 @@
+// Package linediff computes line-based differences between two texts using
+// the Myers algorithm.
+package linediff
+
+import "strings"
+
+// Op is the kind of change a line represents.
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+func (op Op) String() string {
+	switch op {
+	case Insert:
+		return "insert"
+	case Delete:
+		return "delete"
+	default:
+		return "equal"
+	}
+}
+
+// Line is a single line of a diff.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// maxLines bounds the lines that differ between both texts, after their
+// common prefix and suffix. Larger changes are reported as a replacement, so
+// that the cost of a diff stays bounded.
+const maxLines = 10000
+
+// Diff returns the lines of a and b as a shortest edit script turning a into b.
+// A trailing newline does not produce an extra empty line. When more than
+// maxLines lines differ, the differing lines are deleted and inserted as a
+// whole instead.
+func Diff(a, b string) []Line {
+	x, y := splitLines(a), splitLines(b)
+
+	prefix, suffix := commonEnds(x, y)
+	lines := make([]Line, 0, len(x)+len(y)-prefix-suffix)
+	lines = appendLines(lines, Equal, x[:prefix])
+	if middleX, middleY := x[prefix:len(x)-suffix], y[prefix:len(y)-suffix]; len(middleX)+len(middleY) > maxLines {
+		lines = appendLines(lines, Delete, middleX)
+		lines = appendLines(lines, Insert, middleY)
+	} else {
+		lines = myers(lines, middleX, middleY, newSnakeBuffers(len(middleX)+len(middleY)))
+	}
+	return appendLines(lines, Equal, x[len(x)-suffix:])
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// commonEnds returns the lengths of the common prefix and suffix of a and b.
+func commonEnds(a, b []string) (int, int) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	return prefix, suffix
+}
+
+func appendLines(lines []Line, op Op, texts []string) []Line {
+	for _, text := range texts {
+		lines = append(lines, Line{Op: op, Text: text})
+	}
+	return lines
+}
+
+// snakeBuffers holds the furthest reaching points of the forward and backward
+// searches; they are reused across the recursion of one diff.
+type snakeBuffers struct {
+	forward, backward []int
+}
+
+func newSnakeBuffers(size int) *snakeBuffers {
+	return &snakeBuffers{
+		forward:  make([]int, size+4),
+		backward: make([]int, size+4),
+	}
+}
+
+// myers appends the shortest edit script turning a into b using the linear
+// space variant of the Myers algorithm: it finds the middle snake of an
+// optimal path and recurses on both sides of it, so memory stays O(n+m).
+func myers(lines []Line, a, b []string, buf *snakeBuffers) []Line {
+	prefix, suffix := commonEnds(a, b)
+	lines = appendLines(lines, Equal, a[:prefix])
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	switch {
+	case len(middleA) == 0:
+		lines = appendLines(lines, Insert, middleB)
+	case len(middleB) == 0:
+		lines = appendLines(lines, Delete, middleA)
+	default:
+		x, y, u, v := middleSnake(middleA, middleB, buf)
+		lines = myers(lines, middleA[:x], middleB[:y], buf)
+		lines = appendLines(lines, Equal, middleA[x:u])
+		lines = myers(lines, middleA[u:], middleB[v:], buf)
+	}
+
+	return appendLines(lines, Equal, a[len(a)-suffix:])
+}
+
+// middleSnake searches an optimal path from both ends at once and returns the
+// snake from (x, y) to (u, v) where both searches meet.
+func middleSnake(a, b []string, buf *snakeBuffers) (x, y, u, v int) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	half := (n + m + 1) / 2
+	offset := half + 1
+	vf, vb := buf.forward[:2*offset+1], buf.backward[:2*offset+1]
+	vf[offset+1], vb[offset+1] = 0, 0
+
+	for d := 0; d <= half; d++ {
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && vf[offset+k-1] < vf[offset+k+1]) {
+				x = vf[offset+k+1]
+			} else {
+				x = vf[offset+k-1] + 1
+			}
+			y = x - k
+			u, v = x, y
+			for u < n && v < m && a[u] == b[v] {
+				u++
+				v++
+			}
+			vf[offset+k] = u
+			if odd && k-delta >= -(d-1) && k-delta <= d-1 && u+vb[offset+delta-k] >= n {
+				return x, y, u, v
+			}
+		}
+		// The backward search runs on reversed texts; its diagonal k is the
+		// forward diagonal delta-k.
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && vb[offset+k-1] < vb[offset+k+1]) {
+				x = vb[offset+k+1]
+			} else {
+				x = vb[offset+k-1] + 1
+			}
+			y = x - k
+			u, v = x, y
+			for u < n && v < m && a[n-1-u] == b[m-1-v] {
+				u++
+				v++
+			}
+			vb[offset+k] = u
+			if !odd && delta-k >= -d && delta-k <= d && u+vf[offset+delta-k] >= n {
+				return n - u, m - v, n - x, m - y
+			}
+		}
+	}
+	return 0, 0, 0, 0
+}
@@ -0,0 +1,159 @@
+package linediff_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/linediff"
+)
+
+// apply rebuilds both texts from a diff.
+func apply(lines []linediff.Line) (string, string) {
+	var from, to []string
+	for _, line := range lines {
+		if line.Op != linediff.Insert {
+			from = append(from, line.Text)
+		}
+		if line.Op != linediff.Delete {
+			to = append(to, line.Text)
+		}
+	}
+	return strings.Join(from, "\n"), strings.Join(to, "\n")
+}
+
+func count(lines []linediff.Line, op linediff.Op) int {
+	n := 0
+	for _, line := range lines {
+		if line.Op == op {
+			n++
+		}
+	}
+	return n
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("EqualTexts", func(t *testing.T) {
+		lines := linediff.Diff("a\nb\n", "a\nb")
+
+		assert.Equal(t, []linediff.Line{
+			{Op: linediff.Equal, Text: "a"},
+			{Op: linediff.Equal, Text: "b"},
+		}, lines)
+	})
+
+	t.Run("EmptyTexts", func(t *testing.T) {
+		assert.Empty(t, linediff.Diff("", ""))
+		assert.Equal(t, []linediff.Line{{Op: linediff.Insert, Text: "a"}}, linediff.Diff("", "a"))
+		assert.Equal(t, []linediff.Line{{Op: linediff.Delete, Text: "a"}}, linediff.Diff("a", ""))
+	})
+
+	t.Run("ReplacesMiddleLine", func(t *testing.T) {
+		lines := linediff.Diff("# Title\nold\nend", "# Title\nnew\nend")
+
+		assert.Equal(t, []linediff.Line{
+			{Op: linediff.Equal, Text: "# Title"},
+			{Op: linediff.Delete, Text: "old"},
+			{Op: linediff.Insert, Text: "new"},
+			{Op: linediff.Equal, Text: "end"},
+		}, lines)
+	})
+
+	t.Run("FindsShortestScript", func(t *testing.T) {
+		from := "a\nb\nc\na\nb\nb\na"
+		to := "c\nb\na\nb\na\nc"
+
+		lines := linediff.Diff(from, to)
+
+		gotFrom, gotTo := apply(lines)
+		assert.Equal(t, from, gotFrom)
+		assert.Equal(t, to, gotTo)
+		assert.Equal(t, 5, count(lines, linediff.Insert)+count(lines, linediff.Delete))
+	})
+}
+
+// lcs returns the length of the longest common subsequence of a and b.
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// numbered returns count lines named after prefix and their number.
+func numbered(prefix string, count int) string {
+	lines := make([]string, count)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%s %d", prefix, i)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestDiffShortestScript(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(3)))
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		from, to := randomLines(), randomLines()
+
+		lines := linediff.Diff(strings.Join(from, "\n"), strings.Join(to, "\n"))
+
+		gotFrom, gotTo := apply(lines)
+		assert.Equal(t, strings.Join(from, "\n"), gotFrom)
+		assert.Equal(t, strings.Join(to, "\n"), gotTo)
+		assert.Equal(t, len(from)+len(to)-2*lcs(from, to), count(lines, linediff.Insert)+count(lines, linediff.Delete),
+			"from %q to %q", from, to)
+	}
+}
+
+func TestDiffLargeTexts(t *testing.T) {
+	t.Run("DiffsFullyDifferentTextsInLinearSpace", func(t *testing.T) {
+		from, to := numbered("old", 4000), numbered("new", 4000)
+
+		lines := linediff.Diff(from, to)
+
+		gotFrom, gotTo := apply(lines)
+		assert.Equal(t, from, gotFrom)
+		assert.Equal(t, to, gotTo)
+		assert.Equal(t, 4000, count(lines, linediff.Delete))
+		assert.Equal(t, 4000, count(lines, linediff.Insert))
+	})
+
+	t.Run("ReplacesChangesOverLineLimit", func(t *testing.T) {
+		from := "# Title\n" + numbered("old", 10000) + "\nend"
+		to := "# Title\n" + numbered("new", 10000) + "\nend"
+
+		lines := linediff.Diff(from, to)
+
+		gotFrom, gotTo := apply(lines)
+		assert.Equal(t, from, gotFrom)
+		assert.Equal(t, to, gotTo)
+		assert.Equal(t, linediff.Line{Op: linediff.Equal, Text: "# Title"}, lines[0])
+		assert.Equal(t, linediff.Line{Op: linediff.Delete, Text: "old 0"}, lines[1])
+		assert.Equal(t, linediff.Line{Op: linediff.Insert, Text: "new 0"}, lines[10001])
+		assert.Equal(t, linediff.Line{Op: linediff.Equal, Text: "end"}, lines[len(lines)-1])
+	})
+}
+
+func TestOpString(t *testing.T) {
+	assert.Equal(t, "equal", linediff.Equal.String())
+	assert.Equal(t, "insert", linediff.Insert.String())
+	assert.Equal(t, "delete", linediff.Delete.String())
+}
@@ -0,0 +1,322 @@
+package yjs
+
+// Content reference numbers as stored in the lower five bits of an item's info byte.
+const (
+	refGC            = 0
+	refContentDelete = 1
+	refContentJSON   = 2
+	refContentBinary = 3
+	refContentString = 4
+	refContentEmbed  = 5
+	refContentFormat = 6
+	refContentType   = 7
+	refContentAny    = 8
+	refContentDoc    = 9
+	refSkip          = 10
+)
+
+// Type references used by ContentType.
+const (
+	typeRefArray       = 0
+	typeRefMap         = 1
+	typeRefText        = 2
+	typeRefXMLElement  = 3
+	typeRefXMLFragment = 4
+	typeRefXMLHook     = 5
+	typeRefXMLText     = 6
+)
+
+type content interface {
+	ref() uint8
+	length() uint64
+	countable() bool
+	// splice splits the content at offset and returns the left and right parts.
+	splice(offset uint64) (content, content)
+	write(enc *Encoder, offset uint64)
+}
+
+func readContent(dec *Decoder, info uint8) (content, error) {
+	switch info & bits5 {
+	case refContentDelete:
+		n, err := dec.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		if n > maxStructLength {
+			return nil, ErrInvalidUpdate
+		}
+		return &contentDeleted{len: n}, nil
+	case refContentJSON:
+		return readContentJSON(dec)
+	case refContentBinary:
+		data, err := dec.ReadVarUint8Array()
+		if err != nil {
+			return nil, err
+		}
+		return &contentBinary{data: data}, nil
+	case refContentString:
+		s, err := dec.ReadVarString()
+		if err != nil {
+			return nil, err
+		}
+		return &contentString{str: toUTF16(s)}, nil
+	case refContentEmbed:
+		s, err := dec.ReadVarString()
+		if err != nil {
+			return nil, err
+		}
+		return &contentEmbed{json: s}, nil
+	case refContentFormat:
+		key, err := dec.ReadVarString()
+		if err != nil {
+			return nil, err
+		}
+		value, err := dec.ReadVarString()
+		if err != nil {
+			return nil, err
+		}
+		return &contentFormat{key: key, json: value}, nil
+	case refContentType:
+		return readContentType(dec)
+	case refContentAny:
+		return readContentAny(dec)
+	case refContentDoc:
+		guid, err := dec.ReadVarString()
+		if err != nil {
+			return nil, err
+		}
+		opts, err := dec.ReadAny()
+		if err != nil {
+			return nil, err
+		}
+		return &contentDoc{guid: guid, opts: opts}, nil
+	default:
+		return nil, ErrUnknownStructRef
+	}
+}
+
+type contentDeleted struct {
+	len uint64
+}
+
+func (c *contentDeleted) ref() uint8      { return refContentDelete }
+func (c *contentDeleted) length() uint64  { return c.len }
+func (c *contentDeleted) countable() bool { return false }
+
+func (c *contentDeleted) splice(offset uint64) (content, content) {
+	return &contentDeleted{len: offset}, &contentDeleted{len: c.len - offset}
+}
+
+func (c *contentDeleted) write(enc *Encoder, offset uint64) {
+	enc.WriteVarUint(c.len - offset)
+}
+
+type contentJSON struct {
+	values []string
+}
+
+func readContentJSON(dec *Decoder) (content, error) {
+	n, err := dec.ReadVarUint()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, min(n, uint64(len(dec.buf))))
+	for i := uint64(0); i < n; i++ {
+		s, err := dec.ReadVarString()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, s)
+	}
+	return &contentJSON{values: values}, nil
+}
+
+func (c *contentJSON) ref() uint8      { return refContentJSON }
+func (c *contentJSON) length() uint64  { return uint64(len(c.values)) }
+func (c *contentJSON) countable() bool { return true }
+
+func (c *contentJSON) splice(offset uint64) (content, content) {
+	return &contentJSON{values: c.values[:offset]}, &contentJSON{values: c.values[offset:]}
+}
+
+func (c *contentJSON) write(enc *Encoder, offset uint64) {
+	enc.WriteVarUint(uint64(len(c.values)) - offset)
+	for _, v := range c.values[offset:] {
+		enc.WriteVarString(v)
+	}
+}
+
+type contentBinary struct {
+	data []byte
+}
+
+func (c *contentBinary) ref() uint8      { return refContentBinary }
+func (c *contentBinary) length() uint64  { return 1 }
+func (c *contentBinary) countable() bool { return true }
+
+func (c *contentBinary) splice(uint64) (content, content) {
+	return c, nil
+}
+
+func (c *contentBinary) write(enc *Encoder, _ uint64) {
+	enc.WriteVarUint8Array(c.data)
+}
+
+// contentString keeps text as UTF-16 code units because Yjs clocks count them.
+type contentString struct {
+	str []uint16
+}
+
+func (c *contentString) ref() uint8      { return refContentString }
+func (c *contentString) length() uint64  { return uint64(len(c.str)) }
+func (c *contentString) countable() bool { return true }
+
+func (c *contentString) splice(offset uint64) (content, content) {
+	left := append([]uint16(nil), c.str[:offset]...)
+	right := append([]uint16(nil), c.str[offset:]...)
+	// Do not split surrogate pairs; Yjs replaces both halves with U+FFFD.
+	if offset > 0 && left[offset-1] >= 0xd800 && left[offset-1] <= 0xdbff {
+		left[offset-1] = 0xfffd
+		if len(right) > 0 {
+			right[0] = 0xfffd
+		}
+	}
+	return &contentString{str: left}, &contentString{str: right}
+}
+
+func (c *contentString) write(enc *Encoder, offset uint64) {
+	if offset == 0 {
+		enc.WriteVarString(fromUTF16(c.str))
+		return
+	}
+	_, right := c.splice(offset)
+	enc.WriteVarString(fromUTF16(right.(*contentString).str))
+}
+
+type contentEmbed struct {
+	json string
+}
+
+func (c *contentEmbed) ref() uint8      { return refContentEmbed }
+func (c *contentEmbed) length() uint64  { return 1 }
+func (c *contentEmbed) countable() bool { return true }
+
+func (c *contentEmbed) splice(uint64) (content, content) {
+	return c, nil
+}
+
+func (c *contentEmbed) write(enc *Encoder, _ uint64) {
+	enc.WriteVarString(c.json)
+}
+
+type contentFormat struct {
+	key  string
+	json string
+}
+
+func (c *contentFormat) ref() uint8      { return refContentFormat }
+func (c *contentFormat) length() uint64  { return 1 }
+func (c *contentFormat) countable() bool { return false }
+
+func (c *contentFormat) splice(uint64) (content, content) {
+	return c, nil
+}
+
+func (c *contentFormat) write(enc *Encoder, _ uint64) {
+	enc.WriteVarString(c.key)
+	enc.WriteVarString(c.json)
+}
+
+type contentType struct {
+	typeRef uint64
+	// name is the node name of XML elements or the hook name of XML hooks.
+	name string
+}
+
+func readContentType(dec *Decoder) (content, error) {
+	typeRef, err := dec.ReadVarUint()
+	if err != nil {
+		return nil, err
+	}
+	c := &contentType{typeRef: typeRef}
+	switch typeRef {
+	case typeRefArray, typeRefMap, typeRefText, typeRefXMLFragment, typeRefXMLText:
+	case typeRefXMLElement, typeRefXMLHook:
+		c.name, err = dec.ReadVarString()
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnknownStructRef
+	}
+	return c, nil
+}
+
+func (c *contentType) ref() uint8      { return refContentType }
+func (c *contentType) length() uint64  { return 1 }
+func (c *contentType) countable() bool { return true }
+
+func (c *contentType) splice(uint64) (content, content) {
+	return c, nil
+}
+
+func (c *contentType) write(enc *Encoder, _ uint64) {
+	enc.WriteVarUint(c.typeRef)
+	if c.typeRef == typeRefXMLElement || c.typeRef == typeRefXMLHook {
+		enc.WriteVarString(c.name)
+	}
+}
+
+// contentAny stores each element in its raw lib0 encoding.
+type contentAny struct {
+	values [][]byte
+}
+
+func readContentAny(dec *Decoder) (content, error) {
+	n, err := dec.ReadVarUint()
+	if err != nil {
+		return nil, err
+	}
+	values := make([][]byte, 0, min(n, uint64(len(dec.buf))))
+	for i := uint64(0); i < n; i++ {
+		v, err := dec.ReadAny()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return &contentAny{values: values}, nil
+}
+
+func (c *contentAny) ref() uint8      { return refContentAny }
+func (c *contentAny) length() uint64  { return uint64(len(c.values)) }
+func (c *contentAny) countable() bool { return true }
+
+func (c *contentAny) splice(offset uint64) (content, content) {
+	return &contentAny{values: c.values[:offset]}, &contentAny{values: c.values[offset:]}
+}
+
+func (c *contentAny) write(enc *Encoder, offset uint64) {
+	enc.WriteVarUint(uint64(len(c.values)) - offset)
+	for _, v := range c.values[offset:] {
+		enc.WriteRaw(v)
+	}
+}
+
+type contentDoc struct {
+	guid string
+	opts []byte
+}
+
+func (c *contentDoc) ref() uint8      { return refContentDoc }
+func (c *contentDoc) length() uint64  { return 1 }
+func (c *contentDoc) countable() bool { return true }
+
+func (c *contentDoc) splice(uint64) (content, content) {
+	return c, nil
+}
+
+func (c *contentDoc) write(enc *Encoder, _ uint64) {
+	enc.WriteVarString(c.guid)
+	enc.WriteRaw(c.opts)
+}
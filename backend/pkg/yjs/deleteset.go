@@ -0,0 +1,123 @@
+package yjs
+
+import "sort"
+
+// DeleteRange marks len clocks starting at clock as deleted.
+type DeleteRange struct {
+	Clock uint64
+	Len   uint64
+}
+
+// DeleteSet maps client ids to sorted, non-overlapping deleted ranges.
+type DeleteSet map[uint64][]DeleteRange
+
+func readDeleteSet(dec *Decoder) (DeleteSet, error) {
+	ds := make(DeleteSet)
+	numClients, err := dec.ReadVarUint()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < numClients; i++ {
+		client, err := dec.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		numRanges, err := dec.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		ranges := make([]DeleteRange, 0, min(numRanges, uint64(len(dec.buf))))
+		for j := uint64(0); j < numRanges; j++ {
+			clock, err := dec.ReadVarUint()
+			if err != nil {
+				return nil, err
+			}
+			n, err := dec.ReadVarUint()
+			if err != nil {
+				return nil, err
+			}
+			if clock > maxClock || n > maxClock-clock {
+				return nil, ErrInvalidUpdate
+			}
+			if n > 0 {
+				ranges = append(ranges, DeleteRange{Clock: clock, Len: n})
+			}
+		}
+		ds[client] = append(ds[client], ranges...)
+	}
+	ds.normalize()
+	return ds, nil
+}
+
+func (ds DeleteSet) write(enc *Encoder) {
+	clients := make([]uint64, 0, len(ds))
+	for client, ranges := range ds {
+		if len(ranges) > 0 {
+			clients = append(clients, client)
+		}
+	}
+	sort.Slice(clients, func(a, b int) bool { return clients[a] > clients[b] })
+
+	enc.WriteVarUint(uint64(len(clients)))
+	for _, client := range clients {
+		ranges := ds[client]
+		enc.WriteVarUint(client)
+		enc.WriteVarUint(uint64(len(ranges)))
+		for _, r := range ranges {
+			enc.WriteVarUint(r.Clock)
+			enc.WriteVarUint(r.Len)
+		}
+	}
+}
+
+// Add marks a range as deleted.
+func (ds DeleteSet) Add(client, clock, length uint64) {
+	if length == 0 {
+		return
+	}
+	ds[client] = append(ds[client], DeleteRange{Clock: clock, Len: length})
+	ds.normalizeClient(client)
+}
+
+// Merge adds all ranges of other to ds.
+func (ds DeleteSet) Merge(other DeleteSet) {
+	for client, ranges := range other {
+		if len(ranges) == 0 {
+			continue
+		}
+		ds[client] = append(ds[client], ranges...)
+		ds.normalizeClient(client)
+	}
+}
+
+// IsDeleted reports whether the given id lies in a deleted range.
+func (ds DeleteSet) IsDeleted(id ID) bool {
+	ranges := ds[id.Client]
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].Clock+ranges[i].Len > id.Clock })
+	return i < len(ranges) && ranges[i].Clock <= id.Clock
+}
+
+func (ds DeleteSet) normalize() {
+	for client := range ds {
+		ds.normalizeClient(client)
+	}
+}
+
+func (ds DeleteSet) normalizeClient(client uint64) {
+	ranges := ds[client]
+	sort.Slice(ranges, func(a, b int) bool { return ranges[a].Clock < ranges[b].Clock })
+	merged := ranges[:0]
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && merged[n-1].Clock+merged[n-1].Len >= r.Clock {
+			last := &merged[n-1]
+			last.Len = max(last.Len, r.Clock+r.Len-last.Clock)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	if len(merged) == 0 {
+		delete(ds, client)
+		return
+	}
+	ds[client] = merged
+}
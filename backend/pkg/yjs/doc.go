@@ -0,0 +1,159 @@
+package yjs
+
+// Doc is a struct store holding the merged state of every update applied to it.
+// It does not integrate items into shared types; it keeps them per client in
+// clock order, which is enough to produce full or differential state updates
+// that any Y.Doc can load. Doc is not safe for concurrent use.
+type Doc struct {
+	structs map[uint64][]*block
+	ds      DeleteSet
+}
+
+// NewDoc returns an empty document.
+func NewDoc() *Doc {
+	return &Doc{
+		structs: make(map[uint64][]*block),
+		ds:      make(DeleteSet),
+	}
+}
+
+// NewDocFromUpdate builds a document from a full state update, e.g. a snapshot.
+func NewDocFromUpdate(update []byte) (*Doc, error) {
+	doc := NewDoc()
+	if err := doc.ApplyUpdate(update); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+type decodedUpdate struct {
+	structs map[uint64][]*block
+	ds      DeleteSet
+}
+
+func decodeUpdate(update []byte) (*decodedUpdate, error) {
+	dec := NewDecoder(update)
+	structs, err := readStructs(dec)
+	if err != nil {
+		return nil, err
+	}
+	ds, err := readDeleteSet(dec)
+	if err != nil {
+		return nil, err
+	}
+	if dec.HasContent() {
+		return nil, ErrInvalidUpdate
+	}
+	return &decodedUpdate{structs: structs, ds: ds}, nil
+}
+
+// ValidateUpdate checks that update is a well-formed v1 update.
+func ValidateUpdate(update []byte) error {
+	_, err := decodeUpdate(update)
+	return err
+}
+
+// UpdateSpan returns how many clocks the structs of a v1 update cover, summed
+// over its clients. Deleted content, GC ranges and skips are encoded by their
+// length alone, so the span can be far larger than the update.
+func UpdateSpan(update []byte) (uint64, error) {
+	decoded, err := decodeUpdate(update)
+	if err != nil {
+		return 0, err
+	}
+	var span uint64
+	for _, refs := range decoded.structs {
+		for _, b := range refs {
+			span += b.length()
+		}
+	}
+	return span, nil
+}
+
+// ApplyUpdate merges a v1 update into the document. The document is left
+// untouched when the update cannot be decoded.
+func (d *Doc) ApplyUpdate(update []byte) error {
+	decoded, err := decodeUpdate(update)
+	if err != nil {
+		return err
+	}
+	for client, refs := range decoded.structs {
+		d.structs[client] = mergeBlocks(d.structs[client], refs)
+		if len(d.structs[client]) == 0 {
+			delete(d.structs, client)
+		}
+	}
+	d.ds.Merge(decoded.ds)
+	return nil
+}
+
+// StateVector returns the contiguous clock reached for every client.
+func (d *Doc) StateVector() StateVector {
+	sv := make(StateVector, len(d.structs))
+	for client, refs := range d.structs {
+		var clock uint64
+		for _, b := range refs {
+			if b.id.Clock > clock {
+				break
+			}
+			clock = b.end()
+		}
+		if clock > 0 {
+			sv[client] = clock
+		}
+	}
+	return sv
+}
+
+// EncodeStateVector returns the encoded state vector of the document.
+func (d *Doc) EncodeStateVector() []byte {
+	return d.StateVector().Encode()
+}
+
+// EncodeStateAsUpdate encodes everything the holder of sv is missing. A nil
+// state vector yields the full document state.
+func (d *Doc) EncodeStateAsUpdate(sv StateVector) []byte {
+	enc := NewEncoder()
+	writeStructs(enc, d.structs, sv)
+	d.ds.write(enc)
+	return enc.Bytes()
+}
+
+// IsEmpty reports whether the document holds no structs and no deletions.
+func (d *Doc) IsEmpty() bool {
+	return len(d.structs) == 0 && len(d.ds) == 0
+}
+
+// MergeUpdates combines several v1 updates into a single update.
+func MergeUpdates(updates ...[]byte) ([]byte, error) {
+	doc := NewDoc()
+	for _, update := range updates {
+		if err := doc.ApplyUpdate(update); err != nil {
+			return nil, err
+		}
+	}
+	return doc.EncodeStateAsUpdate(nil), nil
+}
+
+// DiffUpdate returns the part of update that is not covered by the encoded
+// state vector.
+func DiffUpdate(update, stateVector []byte) ([]byte, error) {
+	sv, err := DecodeStateVector(stateVector)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := NewDocFromUpdate(update)
+	if err != nil {
+		return nil, err
+	}
+	return doc.EncodeStateAsUpdate(sv), nil
+}
+
+// EncodeStateVectorFromUpdate computes the state vector of an update.
+func EncodeStateVectorFromUpdate(update []byte) ([]byte, error) {
+	doc, err := NewDocFromUpdate(update)
+	if err != nil {
+		return nil, err
+	}
+	return doc.EncodeStateVector(), nil
+}
@@ -0,0 +1,224 @@
+package yjs_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+)
+
+// rootInsert encodes an update inserting text at the start of the root type "content".
+func rootInsert(client, clock uint64, text string) []byte {
+	enc := yjs.NewEncoder()
+	enc.WriteVarUint(1)
+	enc.WriteVarUint(1)
+	enc.WriteVarUint(client)
+	enc.WriteVarUint(clock)
+	enc.WriteUint8(4) // ContentString without origins
+	enc.WriteVarUint(1)
+	enc.WriteVarString("content")
+	enc.WriteVarString(text)
+	enc.WriteVarUint(0)
+	return enc.Bytes()
+}
+
+// appendAfter encodes an update inserting text right after origin.
+func appendAfter(client, clock uint64, origin yjs.ID, text string) []byte {
+	enc := yjs.NewEncoder()
+	enc.WriteVarUint(1)
+	enc.WriteVarUint(1)
+	enc.WriteVarUint(client)
+	enc.WriteVarUint(clock)
+	enc.WriteUint8(4 | 0x80)
+	enc.WriteVarUint(origin.Client)
+	enc.WriteVarUint(origin.Clock)
+	enc.WriteVarString(text)
+	enc.WriteVarUint(0)
+	return enc.Bytes()
+}
+
+// structs encodes an update with a single struct of client written by write.
+func structs(client, clock uint64, write func(enc *yjs.Encoder)) []byte {
+	enc := yjs.NewEncoder()
+	enc.WriteVarUint(1)
+	enc.WriteVarUint(1)
+	enc.WriteVarUint(client)
+	enc.WriteVarUint(clock)
+	write(enc)
+	enc.WriteVarUint(0)
+	return enc.Bytes()
+}
+
+// rootDeleted encodes an update with a run of deleted content at the start of
+// the root type "content".
+func rootDeleted(client, clock, length uint64) []byte {
+	return structs(client, clock, func(enc *yjs.Encoder) {
+		enc.WriteUint8(1) // ContentDeleted without origins
+		enc.WriteVarUint(1)
+		enc.WriteVarString("content")
+		enc.WriteVarUint(length)
+	})
+}
+
+// deletion encodes an update that only carries a delete set.
+func deletion(client, clock, length uint64) []byte {
+	enc := yjs.NewEncoder()
+	enc.WriteVarUint(0)
+	enc.WriteVarUint(1)
+	enc.WriteVarUint(client)
+	enc.WriteVarUint(1)
+	enc.WriteVarUint(clock)
+	enc.WriteVarUint(length)
+	return enc.Bytes()
+}
+
+func TestDocApplyUpdate(t *testing.T) {
+	t.Run("EncodesKnownYjsUpdate", func(t *testing.T) {
+		// Y.Doc with clientID 1 after ydoc.getText("content").insert(0, "a")
+		expected := []byte{1, 1, 1, 0, 4, 1, 7, 'c', 'o', 'n', 't', 'e', 'n', 't', 1, 'a', 0}
+		assert.Equal(t, expected, rootInsert(1, 0, "a"))
+
+		doc, err := yjs.NewDocFromUpdate(expected)
+		require.NoError(t, err)
+		assert.Equal(t, expected, doc.EncodeStateAsUpdate(nil))
+	})
+
+	t.Run("MergesSequentialUpdates", func(t *testing.T) {
+		doc := yjs.NewDoc()
+		require.NoError(t, doc.ApplyUpdate(rootInsert(1, 0, "a")))
+		require.NoError(t, doc.ApplyUpdate(appendAfter(1, 1, yjs.ID{Client: 1, Clock: 0}, "b")))
+
+		assert.Equal(t, rootInsert(1, 0, "ab"), doc.EncodeStateAsUpdate(nil))
+		assert.Equal(t, yjs.StateVector{1: 2}, doc.StateVector())
+	})
+
+	t.Run("MergesOutOfOrderAndDuplicateUpdates", func(t *testing.T) {
+		first := rootInsert(1, 0, "a")
+		second := appendAfter(1, 1, yjs.ID{Client: 1, Clock: 0}, "b")
+
+		merged, err := yjs.MergeUpdates(second, first, first, second)
+		require.NoError(t, err)
+		assert.Equal(t, rootInsert(1, 0, "ab"), merged)
+	})
+
+	t.Run("KeepsOverlappingRangesOnce", func(t *testing.T) {
+		merged, err := yjs.MergeUpdates(
+			rootInsert(1, 0, "ab"),
+			appendAfter(1, 1, yjs.ID{Client: 1, Clock: 0}, "bcd"),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, rootInsert(1, 0, "abcd"), merged)
+	})
+
+	t.Run("WritesSkipForMissingClocks", func(t *testing.T) {
+		doc := yjs.NewDoc()
+		require.NoError(t, doc.ApplyUpdate(rootInsert(1, 0, "a")))
+		require.NoError(t, doc.ApplyUpdate(appendAfter(1, 3, yjs.ID{Client: 1, Clock: 2}, "d")))
+
+		assert.Equal(t, yjs.StateVector{1: 1}, doc.StateVector())
+
+		expected := []byte{1, 3, 1, 0, 4, 1, 7, 'c', 'o', 'n', 't', 'e', 'n', 't', 1, 'a', 10, 2, 0x84, 1, 2, 1, 'd', 0}
+		assert.Equal(t, expected, doc.EncodeStateAsUpdate(nil))
+	})
+
+	t.Run("MergesDeleteSets", func(t *testing.T) {
+		merged, err := yjs.MergeUpdates(rootInsert(1, 0, "abc"), deletion(1, 0, 1), deletion(1, 1, 1))
+		require.NoError(t, err)
+
+		doc, err := yjs.NewDocFromUpdate(merged)
+		require.NoError(t, err)
+		expected := append(rootInsert(1, 0, "abc")[:16+2], 1, 1, 1, 0, 2)
+		assert.Equal(t, expected, doc.EncodeStateAsUpdate(nil))
+	})
+
+	t.Run("RejectsMalformedUpdate", func(t *testing.T) {
+		update := rootInsert(1, 0, "abc")
+
+		doc := yjs.NewDoc()
+		assert.Error(t, doc.ApplyUpdate(update[:len(update)-3]))
+		assert.Error(t, doc.ApplyUpdate(append(update, 0)))
+		assert.Error(t, doc.ApplyUpdate([]byte{1, 1, 1, 0, 31}))
+		assert.True(t, doc.IsEmpty())
+	})
+
+	t.Run("RejectsOversizedAndOverflowingClocks", func(t *testing.T) {
+		cases := map[string][]byte{
+			"DeletedContent":    rootDeleted(1, 0, 1<<28),
+			"GC":                structs(1, 0, func(enc *yjs.Encoder) { enc.WriteUint8(0); enc.WriteVarUint(1 << 28) }),
+			"Skip":              structs(1, 0, func(enc *yjs.Encoder) { enc.WriteUint8(10); enc.WriteVarUint(1 << 28) }),
+			"StartClock":        rootInsert(1, 1<<60, "a"),
+			"ClockOverflow":     rootDeleted(1, 1<<53-2, 2),
+			"DeleteSetOverflow": deletion(1, 1<<63, 1<<63),
+		}
+		for name, update := range cases {
+			t.Run(name, func(t *testing.T) {
+				assert.ErrorIs(t, yjs.ValidateUpdate(update), yjs.ErrInvalidUpdate)
+			})
+		}
+
+		assert.NoError(t, yjs.ValidateUpdate(rootDeleted(1, 0, 1<<20)))
+	})
+}
+
+func TestUpdateSpan(t *testing.T) {
+	update, err := yjs.MergeUpdates(rootInsert(1, 0, "ab"), rootDeleted(2, 0, 1<<20), deletion(1, 0, 1))
+	require.NoError(t, err)
+
+	span, err := yjs.UpdateSpan(update)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2+1<<20), span)
+
+	_, err = yjs.UpdateSpan([]byte{1, 1, 7})
+	assert.Error(t, err)
+}
+
+func TestDiffUpdate(t *testing.T) {
+	state, err := yjs.MergeUpdates(
+		rootInsert(1, 0, "ab"),
+		rootInsert(2, 0, "x"),
+		deletion(2, 0, 1),
+	)
+	require.NoError(t, err)
+
+	t.Run("SlicesPartiallyKnownStructs", func(t *testing.T) {
+		sv := yjs.StateVector{1: 1, 2: 1}.Encode()
+
+		diff, err := yjs.DiffUpdate(state, sv)
+		require.NoError(t, err)
+
+		expected := []byte{1, 1, 1, 1, 0x84, 1, 0, 1, 'b', 1, 2, 1, 0, 1}
+		assert.Equal(t, expected, diff)
+	})
+
+	t.Run("ReturnsFullStateForEmptyVector", func(t *testing.T) {
+		diff, err := yjs.DiffUpdate(state, yjs.StateVector{}.Encode())
+		require.NoError(t, err)
+		assert.Equal(t, state, diff)
+	})
+
+	t.Run("ComputesStateVectorFromUpdate", func(t *testing.T) {
+		encoded, err := yjs.EncodeStateVectorFromUpdate(state)
+		require.NoError(t, err)
+
+		sv, err := yjs.DecodeStateVector(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, yjs.StateVector{1: 2, 2: 1}, sv)
+	})
+}
+
+func TestDecoderReadAny(t *testing.T) {
+	// {"a": [1, -70, "x", true, null]}
+	raw := []byte{118, 1, 1, 'a', 117, 5, 125, 1, 125, 0xc6, 1, 119, 1, 'x', 120, 126}
+	dec := yjs.NewDecoder(append(raw, 0xff))
+
+	value, err := dec.ReadAny()
+	require.NoError(t, err)
+	assert.Equal(t, raw, value)
+	assert.True(t, dec.HasContent())
+
+	enc := yjs.NewEncoder()
+	enc.WriteVarInt(-70)
+	assert.Equal(t, []byte{0xc6, 1}, enc.Bytes())
+}
@@ -0,0 +1,279 @@
+// Package yjs implements the parts of the Yjs binary update format (v1) that the
+// collaboration server needs: lib0 encoding, update decoding, state vectors,
+// delete sets and a struct store that merges updates into a full document state.
+package yjs
+
+import (
+	"errors"
+	"unicode/utf16"
+)
+
+var (
+	ErrUnexpectedEOF    = errors.New("yjs: unexpected end of data")
+	ErrVarUintOverflow  = errors.New("yjs: varuint overflow")
+	ErrUnknownStructRef = errors.New("yjs: unknown struct reference")
+	ErrUnknownAnyType   = errors.New("yjs: unknown any type")
+	ErrInvalidUpdate    = errors.New("yjs: invalid update")
+)
+
+const (
+	bit6  = 0x20
+	bit7  = 0x40
+	bit8  = 0x80
+	bits5 = 0x1f
+	bits6 = 0x3f
+	bits7 = 0x7f
+)
+
+// Encoder writes lib0 encoded values into a growing buffer.
+type Encoder struct {
+	buf []byte
+}
+
+// NewEncoder returns an empty encoder.
+func NewEncoder() *Encoder {
+	return &Encoder{}
+}
+
+// Bytes returns the encoded content.
+func (e *Encoder) Bytes() []byte {
+	return e.buf
+}
+
+// WriteUint8 appends a single byte.
+func (e *Encoder) WriteUint8(v uint8) {
+	e.buf = append(e.buf, v)
+}
+
+// WriteVarUint appends an unsigned LEB128 integer.
+func (e *Encoder) WriteVarUint(v uint64) {
+	for v > bits7 {
+		e.buf = append(e.buf, bit8|byte(v&bits7))
+		v >>= 7
+	}
+	e.buf = append(e.buf, byte(v&bits7))
+}
+
+// WriteVarInt appends a signed lib0 varint (sign stored in the first byte).
+func (e *Encoder) WriteVarInt(v int64) {
+	negative := v < 0
+	if negative {
+		v = -v
+	}
+	first := byte(v & bits6)
+	if negative {
+		first |= bit7
+	}
+	v >>= 6
+	if v > 0 {
+		first |= bit8
+	}
+	e.buf = append(e.buf, first)
+	for v > 0 {
+		b := byte(v & bits7)
+		v >>= 7
+		if v > 0 {
+			b |= bit8
+		}
+		e.buf = append(e.buf, b)
+	}
+}
+
+// WriteVarUint8Array appends a length-prefixed byte slice.
+func (e *Encoder) WriteVarUint8Array(data []byte) {
+	e.WriteVarUint(uint64(len(data)))
+	e.buf = append(e.buf, data...)
+}
+
+// WriteVarString appends a length-prefixed UTF-8 string.
+func (e *Encoder) WriteVarString(s string) {
+	e.WriteVarUint8Array([]byte(s))
+}
+
+// WriteRaw appends bytes without a length prefix.
+func (e *Encoder) WriteRaw(data []byte) {
+	e.buf = append(e.buf, data...)
+}
+
+// Decoder reads lib0 encoded values from a byte slice.
+type Decoder struct {
+	buf []byte
+	pos int
+}
+
+// NewDecoder returns a decoder positioned at the beginning of data.
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{buf: data}
+}
+
+// HasContent reports whether unread bytes remain.
+func (d *Decoder) HasContent() bool {
+	return d.pos < len(d.buf)
+}
+
+// Pos returns the current read offset.
+func (d *Decoder) Pos() int {
+	return d.pos
+}
+
+// ReadUint8 reads a single byte.
+func (d *Decoder) ReadUint8() (uint8, error) {
+	if d.pos >= len(d.buf) {
+		return 0, ErrUnexpectedEOF
+	}
+	v := d.buf[d.pos]
+	d.pos++
+	return v, nil
+}
+
+// ReadVarUint reads an unsigned LEB128 integer.
+func (d *Decoder) ReadVarUint() (uint64, error) {
+	var (
+		v     uint64
+		shift uint
+	)
+	for {
+		b, err := d.ReadUint8()
+		if err != nil {
+			return 0, err
+		}
+		if shift >= 64 {
+			return 0, ErrVarUintOverflow
+		}
+		v |= uint64(b&bits7) << shift
+		if b < bit8 {
+			return v, nil
+		}
+		shift += 7
+	}
+}
+
+// ReadVarInt reads a signed lib0 varint.
+func (d *Decoder) ReadVarInt() (int64, error) {
+	b, err := d.ReadUint8()
+	if err != nil {
+		return 0, err
+	}
+	v := int64(b & bits6)
+	negative := b&bit7 != 0
+	shift := uint(6)
+	for b&bit8 != 0 {
+		b, err = d.ReadUint8()
+		if err != nil {
+			return 0, err
+		}
+		if shift >= 63 {
+			return 0, ErrVarUintOverflow
+		}
+		v |= int64(b&bits7) << shift
+		shift += 7
+	}
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
+
+// ReadBytes reads n raw bytes.
+func (d *Decoder) ReadBytes(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.buf) {
+		return nil, ErrUnexpectedEOF
+	}
+	v := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return v, nil
+}
+
+// ReadVarUint8Array reads a length-prefixed byte slice.
+func (d *Decoder) ReadVarUint8Array() ([]byte, error) {
+	n, err := d.ReadVarUint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.buf)-d.pos) {
+		return nil, ErrUnexpectedEOF
+	}
+	return d.ReadBytes(int(n))
+}
+
+// ReadVarString reads a length-prefixed UTF-8 string.
+func (d *Decoder) ReadVarString() (string, error) {
+	data, err := d.ReadVarUint8Array()
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ReadAny reads a lib0 "any" value and returns its raw encoding, so that the
+// value can be written back byte for byte without interpreting it.
+func (d *Decoder) ReadAny() ([]byte, error) {
+	start := d.pos
+	if err := d.skipAny(); err != nil {
+		return nil, err
+	}
+	return d.buf[start:d.pos], nil
+}
+
+func (d *Decoder) skipAny() error {
+	t, err := d.ReadUint8()
+	if err != nil {
+		return err
+	}
+	switch t {
+	case 127, 126, 121, 120: // undefined, null, false, true
+		return nil
+	case 125: // integer
+		_, err = d.ReadVarInt()
+		return err
+	case 124: // float32
+		_, err = d.ReadBytes(4)
+		return err
+	case 123, 122: // float64, bigint64
+		_, err = d.ReadBytes(8)
+		return err
+	case 119: // string
+		_, err = d.ReadVarUint8Array()
+		return err
+	case 118: // object
+		n, err := d.ReadVarUint()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < n; i++ {
+			if _, err := d.ReadVarUint8Array(); err != nil {
+				return err
+			}
+			if err := d.skipAny(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case 117: // array
+		n, err := d.ReadVarUint()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < n; i++ {
+			if err := d.skipAny(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case 116: // Uint8Array
+		_, err = d.ReadVarUint8Array()
+		return err
+	default:
+		return ErrUnknownAnyType
+	}
+}
+
+// toUTF16 converts a Go string into JavaScript string units, which is how Yjs
+// measures text length and clock offsets.
+func toUTF16(s string) []uint16 {
+	return utf16.Encode([]rune(s))
+}
+
+func fromUTF16(s []uint16) string {
+	return string(utf16.Decode(s))
+}
@@ -0,0 +1,47 @@
+package yjs
+
+import "sort"
+
+// StateVector maps client ids to the next expected clock of that client.
+type StateVector map[uint64]uint64
+
+// DecodeStateVector parses an encoded state vector as sent in SyncStep1.
+func DecodeStateVector(data []byte) (StateVector, error) {
+	dec := NewDecoder(data)
+	n, err := dec.ReadVarUint()
+	if err != nil {
+		return nil, err
+	}
+	sv := make(StateVector, min(n, uint64(len(data))))
+	for i := uint64(0); i < n; i++ {
+		client, err := dec.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		clock, err := dec.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		sv[client] = clock
+	}
+	return sv, nil
+}
+
+// Encode serializes the state vector, higher client ids first.
+func (sv StateVector) Encode() []byte {
+	clients := make([]uint64, 0, len(sv))
+	for client, clock := range sv {
+		if clock > 0 {
+			clients = append(clients, client)
+		}
+	}
+	sort.Slice(clients, func(a, b int) bool { return clients[a] > clients[b] })
+
+	enc := NewEncoder()
+	enc.WriteVarUint(uint64(len(clients)))
+	for _, client := range clients {
+		enc.WriteVarUint(client)
+		enc.WriteVarUint(sv[client])
+	}
+	return enc.Bytes()
+}
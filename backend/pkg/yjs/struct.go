@@ -0,0 +1,379 @@
+package yjs
+
+import "sort"
+
+// ID identifies a single clock position of a Yjs client.
+type ID struct {
+	Client uint64
+	Clock  uint64
+}
+
+// maxClock is the largest clock a Yjs client can reach; clocks are JavaScript
+// numbers, so they stay below 2^53.
+const maxClock = 1<<53 - 1
+
+// maxStructLength bounds the length of structs that do not carry their content,
+// i.e. GC and Skip ranges and deleted content. Client ids change with every Yjs
+// session, so no session comes close to it.
+const maxStructLength = 1 << 24
+
+type blockKind uint8
+
+const (
+	kindItem blockKind = iota
+	kindGC
+	kindSkip
+)
+
+// block is a decoded struct of an update: an Item, a GC range or a Skip gap.
+// Items are kept lazily, i.e. they are not integrated into a type tree, which is
+// all that is needed to merge and diff updates.
+type block struct {
+	kind blockKind
+	id   ID
+	// len is only used by GC and Skip blocks; items take their length from content.
+	len uint64
+
+	origin      *ID
+	rightOrigin *ID
+	// Parent information is only present when the item has neither origin.
+	parentName string
+	parentID   *ID
+	parentSub  *string
+	content    content
+}
+
+func (b *block) length() uint64 {
+	if b.kind == kindItem {
+		return b.content.length()
+	}
+	return b.len
+}
+
+func (b *block) end() uint64 {
+	return b.id.Clock + b.length()
+}
+
+// sliceFrom returns the part of the block that starts diff clocks after its beginning.
+func (b *block) sliceFrom(diff uint64) *block {
+	if diff == 0 {
+		return b
+	}
+	id := ID{Client: b.id.Client, Clock: b.id.Clock + diff}
+	if b.kind != kindItem {
+		return &block{kind: b.kind, id: id, len: b.len - diff}
+	}
+	_, right := b.content.splice(diff)
+	return &block{
+		kind:        kindItem,
+		id:          id,
+		origin:      &ID{Client: b.id.Client, Clock: id.Clock - 1},
+		rightOrigin: b.rightOrigin,
+		parentName:  b.parentName,
+		parentID:    b.parentID,
+		parentSub:   b.parentSub,
+		content:     right,
+	}
+}
+
+// mergeWith appends right to b if both can be represented as a single struct.
+func (b *block) mergeWith(right *block) bool {
+	if b.kind != right.kind || b.end() != right.id.Clock {
+		return false
+	}
+	switch b.kind {
+	case kindGC, kindSkip:
+		b.len += right.len
+		return true
+	}
+	if right.origin == nil || *right.origin != (ID{Client: b.id.Client, Clock: b.end() - 1}) {
+		return false
+	}
+	if !equalIDs(b.rightOrigin, right.rightOrigin) || !equalStrings(b.parentSub, right.parentSub) {
+		return false
+	}
+	switch l := b.content.(type) {
+	case *contentString:
+		if r, ok := right.content.(*contentString); ok {
+			b.content = &contentString{str: append(append([]uint16(nil), l.str...), r.str...)}
+			return true
+		}
+	case *contentDeleted:
+		if r, ok := right.content.(*contentDeleted); ok {
+			b.content = &contentDeleted{len: l.len + r.len}
+			return true
+		}
+	case *contentAny:
+		if r, ok := right.content.(*contentAny); ok {
+			b.content = &contentAny{values: append(append([][]byte(nil), l.values...), r.values...)}
+			return true
+		}
+	case *contentJSON:
+		if r, ok := right.content.(*contentJSON); ok {
+			b.content = &contentJSON{values: append(append([]string(nil), l.values...), r.values...)}
+			return true
+		}
+	}
+	return false
+}
+
+func (b *block) write(enc *Encoder, offset uint64) {
+	switch b.kind {
+	case kindGC:
+		enc.WriteUint8(refGC)
+		enc.WriteVarUint(b.len - offset)
+		return
+	case kindSkip:
+		enc.WriteUint8(refSkip)
+		enc.WriteVarUint(b.len - offset)
+		return
+	}
+
+	origin := b.origin
+	if offset > 0 {
+		origin = &ID{Client: b.id.Client, Clock: b.id.Clock + offset - 1}
+	}
+	info := b.content.ref() & bits5
+	if origin != nil {
+		info |= bit8
+	}
+	if b.rightOrigin != nil {
+		info |= bit7
+	}
+	if b.parentSub != nil {
+		info |= bit6
+	}
+	enc.WriteUint8(info)
+	if origin != nil {
+		enc.WriteVarUint(origin.Client)
+		enc.WriteVarUint(origin.Clock)
+	}
+	if b.rightOrigin != nil {
+		enc.WriteVarUint(b.rightOrigin.Client)
+		enc.WriteVarUint(b.rightOrigin.Clock)
+	}
+	if origin == nil && b.rightOrigin == nil {
+		if b.parentID != nil {
+			enc.WriteVarUint(0)
+			enc.WriteVarUint(b.parentID.Client)
+			enc.WriteVarUint(b.parentID.Clock)
+		} else {
+			enc.WriteVarUint(1)
+			enc.WriteVarString(b.parentName)
+		}
+		if b.parentSub != nil {
+			enc.WriteVarString(*b.parentSub)
+		}
+	}
+	b.content.write(enc, offset)
+}
+
+func readID(dec *Decoder) (*ID, error) {
+	client, err := dec.ReadVarUint()
+	if err != nil {
+		return nil, err
+	}
+	clock, err := dec.ReadVarUint()
+	if err != nil {
+		return nil, err
+	}
+	return &ID{Client: client, Clock: clock}, nil
+}
+
+func readItem(dec *Decoder, id ID, info uint8) (*block, error) {
+	var err error
+	b := &block{kind: kindItem, id: id}
+	if info&bit8 != 0 {
+		if b.origin, err = readID(dec); err != nil {
+			return nil, err
+		}
+	}
+	if info&bit7 != 0 {
+		if b.rightOrigin, err = readID(dec); err != nil {
+			return nil, err
+		}
+	}
+	if info&(bit7|bit8) == 0 {
+		isRoot, err := dec.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		if isRoot == 1 {
+			if b.parentName, err = dec.ReadVarString(); err != nil {
+				return nil, err
+			}
+		} else if b.parentID, err = readID(dec); err != nil {
+			return nil, err
+		}
+		if info&bit6 != 0 {
+			sub, err := dec.ReadVarString()
+			if err != nil {
+				return nil, err
+			}
+			b.parentSub = &sub
+		}
+	}
+	if b.content, err = readContent(dec, info); err != nil {
+		return nil, err
+	}
+	if b.content.length() == 0 {
+		return nil, ErrInvalidUpdate
+	}
+	return b, nil
+}
+
+// readStructs decodes the struct section of a v1 update, grouped by client and
+// ordered by clock.
+func readStructs(dec *Decoder) (map[uint64][]*block, error) {
+	numClients, err := dec.ReadVarUint()
+	if err != nil {
+		return nil, err
+	}
+	structs := make(map[uint64][]*block)
+	for i := uint64(0); i < numClients; i++ {
+		numStructs, err := dec.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		client, err := dec.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		clock, err := dec.ReadVarUint()
+		if err != nil {
+			return nil, err
+		}
+		if clock > maxClock {
+			return nil, ErrInvalidUpdate
+		}
+		refs := make([]*block, 0, min(numStructs, uint64(len(dec.buf))))
+		for j := uint64(0); j < numStructs; j++ {
+			info, err := dec.ReadUint8()
+			if err != nil {
+				return nil, err
+			}
+			id := ID{Client: client, Clock: clock}
+			var b *block
+			switch info & bits5 {
+			case refGC, refSkip:
+				n, err := dec.ReadVarUint()
+				if err != nil {
+					return nil, err
+				}
+				if n > maxStructLength {
+					return nil, ErrInvalidUpdate
+				}
+				kind := kindGC
+				if info&bits5 == refSkip {
+					kind = kindSkip
+				}
+				b = &block{kind: kind, id: id, len: n}
+			default:
+				if b, err = readItem(dec, id, info); err != nil {
+					return nil, err
+				}
+			}
+			if b.length() > maxClock-clock {
+				return nil, ErrInvalidUpdate
+			}
+			clock += b.length()
+			refs = append(refs, b)
+		}
+		structs[client] = append(structs[client], refs...)
+	}
+	for client, refs := range structs {
+		sort.SliceStable(refs, func(a, b int) bool { return refs[a].id.Clock < refs[b].id.Clock })
+		structs[client] = refs
+	}
+	return structs, nil
+}
+
+// writeStructs encodes per-client struct lists, higher client ids first like Yjs
+// does, filling clock gaps between blocks with Skip structs.
+func writeStructs(enc *Encoder, structs map[uint64][]*block, sv StateVector) {
+	clients := make([]uint64, 0, len(structs))
+	for client, refs := range structs {
+		if len(refs) > 0 && refs[len(refs)-1].end() > sv[client] {
+			clients = append(clients, client)
+		}
+	}
+	sort.Slice(clients, func(a, b int) bool { return clients[a] > clients[b] })
+
+	enc.WriteVarUint(uint64(len(clients)))
+	for _, client := range clients {
+		refs := structs[client]
+		from := sv[client]
+		start := sort.Search(len(refs), func(i int) bool { return refs[i].end() > from })
+		refs = refs[start:]
+
+		firstClock := max(from, refs[0].id.Clock)
+		count := uint64(len(refs))
+		for i := 1; i < len(refs); i++ {
+			if refs[i-1].end() < refs[i].id.Clock {
+				count++
+			}
+		}
+
+		enc.WriteVarUint(count)
+		enc.WriteVarUint(client)
+		enc.WriteVarUint(firstClock)
+		refs[0].write(enc, firstClock-refs[0].id.Clock)
+		for i := 1; i < len(refs); i++ {
+			if gap := refs[i-1].end(); gap < refs[i].id.Clock {
+				skip := &block{kind: kindSkip, id: ID{Client: client, Clock: gap}, len: refs[i].id.Clock - gap}
+				skip.write(enc, 0)
+			}
+			refs[i].write(enc, 0)
+		}
+	}
+}
+
+// mergeBlocks combines two clock-ordered block lists of one client, dropping
+// duplicated ranges and skips, and merging adjacent structs where possible.
+func mergeBlocks(existing, incoming []*block) []*block {
+	if len(existing) > 0 && len(incoming) > 0 && incoming[0].id.Clock >= existing[len(existing)-1].end() {
+		return appendBlocks(existing, incoming)
+	}
+	combined := make([]*block, 0, len(existing)+len(incoming))
+	combined = append(combined, existing...)
+	combined = append(combined, incoming...)
+	sort.SliceStable(combined, func(a, b int) bool { return combined[a].id.Clock < combined[b].id.Clock })
+	return appendBlocks(nil, combined)
+}
+
+func appendBlocks(dst, src []*block) []*block {
+	for _, b := range src {
+		if b.kind == kindSkip {
+			continue
+		}
+		if len(dst) > 0 {
+			last := dst[len(dst)-1]
+			covered := last.end()
+			if b.end() <= covered {
+				continue
+			}
+			if b.id.Clock < covered {
+				b = b.sliceFrom(covered - b.id.Clock)
+			}
+			if last.mergeWith(b) {
+				continue
+			}
+		}
+		dst = append(dst, b)
+	}
+	return dst
+}
+
+func equalIDs(a, b *ID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalStrings(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
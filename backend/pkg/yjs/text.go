@@ -0,0 +1,387 @@
+package yjs
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// listItem is an item integrated into the linked list of its parent type.
+type listItem struct {
+	id          ID
+	length      uint64
+	origin      *ID
+	rightOrigin *ID
+	content     content
+	// parent identifies the list the item belongs to; empty for detached items
+	// such as GC ranges, map entries or items whose origins were collected.
+	parent      string
+	left, right *listItem
+}
+
+// sequence integrates the items of a Doc into per-type linked lists following
+// the YATA rules Yjs uses, so that the resulting order matches every client.
+type sequence struct {
+	items  map[uint64][]*listItem
+	starts map[string]*listItem
+}
+
+func rootKey(name string) string {
+	return "root:" + name
+}
+
+func typeKey(id ID) string {
+	return fmt.Sprintf("type:%d:%d", id.Client, id.Clock)
+}
+
+func newSequence(d *Doc) *sequence {
+	s := &sequence{
+		items:  make(map[uint64][]*listItem, len(d.structs)),
+		starts: make(map[string]*listItem),
+	}
+
+	pending := make(map[uint64][]*block, len(d.structs))
+	clients := make([]uint64, 0, len(d.structs))
+	for client, refs := range d.structs {
+		pending[client] = refs
+		clients = append(clients, client)
+	}
+	sort.Slice(clients, func(a, b int) bool { return clients[a] < clients[b] })
+
+	// Integrate blocks whose dependencies are known until no progress is made;
+	// blocks with missing dependencies stay pending like they do in Yjs.
+	for progress := true; progress; {
+		progress = false
+		for _, client := range clients {
+			for len(pending[client]) > 0 && s.ready(pending[client][0]) {
+				s.integrate(pending[client][0])
+				pending[client] = pending[client][1:]
+				progress = true
+			}
+		}
+	}
+	return s
+}
+
+// next returns the clock following the last integrated struct of client.
+func (s *sequence) next(client uint64) uint64 {
+	items := s.items[client]
+	if len(items) == 0 {
+		return 0
+	}
+	last := items[len(items)-1]
+	return last.id.Clock + last.length
+}
+
+func (s *sequence) has(id *ID) bool {
+	return id == nil || s.next(id.Client) > id.Clock
+}
+
+func (s *sequence) ready(b *block) bool {
+	if b.id.Clock != s.next(b.id.Client) {
+		return false
+	}
+	if b.kind != kindItem {
+		return true
+	}
+	return s.has(b.origin) && s.has(b.rightOrigin) && s.has(b.parentID)
+}
+
+func (s *sequence) find(id ID) *listItem {
+	items := s.items[id.Client]
+	i := sort.Search(len(items), func(i int) bool { return items[i].id.Clock+items[i].length > id.Clock })
+	if i < len(items) && items[i].id.Clock <= id.Clock {
+		return items[i]
+	}
+	return nil
+}
+
+// split cuts item at diff and links the right part after it.
+func (s *sequence) split(item *listItem, diff uint64) *listItem {
+	right := &listItem{
+		id:          ID{Client: item.id.Client, Clock: item.id.Clock + diff},
+		length:      item.length - diff,
+		origin:      &ID{Client: item.id.Client, Clock: item.id.Clock + diff - 1},
+		rightOrigin: item.rightOrigin,
+		parent:      item.parent,
+	}
+	if item.content != nil {
+		item.content, right.content = item.content.splice(diff)
+	}
+	item.length = diff
+
+	if item.parent != "" {
+		right.left = item
+		right.right = item.right
+		if item.right != nil {
+			item.right.left = right
+		}
+		item.right = right
+	}
+
+	items := s.items[item.id.Client]
+	i := sort.Search(len(items), func(i int) bool { return items[i].id.Clock > item.id.Clock })
+	items = append(items, nil)
+	copy(items[i+1:], items[i:])
+	items[i] = right
+	s.items[item.id.Client] = items
+	return right
+}
+
+// itemEndingAt returns the item whose last clock is id, splitting if needed.
+func (s *sequence) itemEndingAt(id ID) *listItem {
+	item := s.find(id)
+	if item != nil && id.Clock+1 < item.id.Clock+item.length {
+		s.split(item, id.Clock+1-item.id.Clock)
+	}
+	return item
+}
+
+// itemStartingAt returns the item whose first clock is id, splitting if needed.
+func (s *sequence) itemStartingAt(id ID) *listItem {
+	item := s.find(id)
+	if item != nil && item.id.Clock < id.Clock {
+		return s.split(item, id.Clock-item.id.Clock)
+	}
+	return item
+}
+
+func (s *sequence) integrate(b *block) {
+	item := &listItem{id: b.id, length: b.length()}
+	s.items[b.id.Client] = append(s.items[b.id.Client], item)
+	if b.kind != kindItem {
+		return
+	}
+	item.origin = b.origin
+	item.rightOrigin = b.rightOrigin
+	item.content = b.content
+
+	var left, right *listItem
+	if b.origin != nil {
+		left = s.itemEndingAt(*b.origin)
+	}
+	if b.rightOrigin != nil {
+		right = s.itemStartingAt(*b.rightOrigin)
+	}
+
+	switch {
+	case left != nil:
+		item.parent = left.parent
+	case right != nil:
+		item.parent = right.parent
+	case b.parentID != nil:
+		item.parent = typeKey(*b.parentID)
+	default:
+		item.parent = rootKey(b.parentName)
+	}
+	// Origins pointing at collected structs, as well as map entries, are not
+	// part of any sequence.
+	if (left != nil && left.parent == "") || (right != nil && right.parent == "") || b.parentSub != nil {
+		item.parent = ""
+	}
+	if item.parent == "" {
+		return
+	}
+
+	o := s.starts[item.parent]
+	if left != nil {
+		o = left.right
+	}
+	conflicting := make(map[*listItem]bool)
+	beforeOrigin := make(map[*listItem]bool)
+	for o != nil && o != right {
+		beforeOrigin[o] = true
+		conflicting[o] = true
+		if equalIDs(item.origin, o.origin) {
+			if o.id.Client < item.id.Client {
+				left = o
+				clear(conflicting)
+			} else if equalIDs(item.rightOrigin, o.rightOrigin) {
+				break
+			}
+		} else if oOrigin := s.originItem(o); oOrigin != nil && beforeOrigin[oOrigin] {
+			if !conflicting[oOrigin] {
+				left = o
+				clear(conflicting)
+			}
+		} else {
+			break
+		}
+		o = o.right
+	}
+
+	item.left = left
+	if left != nil {
+		item.right = left.right
+		left.right = item
+	} else {
+		item.right = s.starts[item.parent]
+		s.starts[item.parent] = item
+	}
+	if item.right != nil {
+		item.right.left = item
+	}
+}
+
+func (s *sequence) originItem(item *listItem) *listItem {
+	if item.origin == nil {
+		return nil
+	}
+	return s.find(*item.origin)
+}
+
+// textRun is an item of a root type in document order. Runs of non-string
+// content, deleted content included, only serve as insertion anchors, so they
+// are kept by length and never expanded.
+type textRun struct {
+	id     ID
+	length uint64
+	// str is the text of string content; nil for any other content.
+	str []uint16
+}
+
+// textUnit is a visible UTF-16 code unit of a text together with its id and
+// its position in the runs.
+type textUnit struct {
+	id     ID
+	char   uint16
+	run    int
+	offset uint64
+}
+
+// runs lists the items of the given root type in document order.
+func (s *sequence) runs(name string) []textRun {
+	var runs []textRun
+	for item := s.starts[rootKey(name)]; item != nil; item = item.right {
+		run := textRun{id: item.id, length: item.length}
+		if str, ok := item.content.(*contentString); ok {
+			run.str = str.str
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// visibleUnits lists the code units of string runs that are not deleted.
+func visibleUnits(runs []textRun, ds DeleteSet) []textUnit {
+	var units []textUnit
+	for r, run := range runs {
+		for i, char := range run.str {
+			id := ID{Client: run.id.Client, Clock: run.id.Clock + uint64(i)}
+			if !ds.IsDeleted(id) {
+				units = append(units, textUnit{id: id, char: char, run: r, offset: uint64(i)})
+			}
+		}
+	}
+	return units
+}
+
+// clockAfter returns the id following offset in run r in document order, or
+// nil at the end of the text.
+func clockAfter(runs []textRun, r int, offset uint64) *ID {
+	if offset+1 < runs[r].length {
+		return &ID{Client: runs[r].id.Client, Clock: runs[r].id.Clock + offset + 1}
+	}
+	if r+1 < len(runs) {
+		return &runs[r+1].id
+	}
+	return nil
+}
+
+// Text returns the content of the root Y.Text with the given name. Embeds and
+// formatting attributes are ignored.
+func (d *Doc) Text(name string) string {
+	units := visibleUnits(newSequence(d).runs(name), d.ds)
+	text := make([]uint16, len(units))
+	for i, unit := range units {
+		text[i] = unit.char
+	}
+	return fromUTF16(text)
+}
+
+// ReplaceText returns an update, authored by client, that turns the root Y.Text
+// with the given name into text. Only the range between the common prefix and
+// suffix of both texts is replaced, so concurrent edits elsewhere survive. It
+// returns nil when the text is unchanged. The document itself is not modified.
+func (d *Doc) ReplaceText(name, text string, client uint64) []byte {
+	runs := newSequence(d).runs(name)
+	units := visibleUnits(runs, d.ds)
+	target := toUTF16(text)
+
+	prefix := 0
+	for prefix < len(units) && prefix < len(target) && units[prefix].char == target[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(units)-prefix && suffix < len(target)-prefix &&
+		units[len(units)-1-suffix].char == target[len(target)-1-suffix] {
+		suffix++
+	}
+	// Keep surrogate pairs together on both sides of the replaced range.
+	if prefix > 0 && isHighSurrogate(target[prefix-1]) {
+		prefix--
+	}
+	if suffix > 0 && isLowSurrogate(target[len(target)-suffix]) {
+		suffix--
+	}
+
+	ds := make(DeleteSet)
+	for _, unit := range units[prefix : len(units)-suffix] {
+		ds.Add(unit.id.Client, unit.id.Clock, 1)
+	}
+
+	structs := make(map[uint64][]*block)
+	if inserted := target[prefix : len(target)-suffix]; len(inserted) > 0 {
+		item := &block{
+			kind:    kindItem,
+			id:      ID{Client: client, Clock: d.nextClock(client)},
+			content: &contentString{str: append([]uint16(nil), inserted...)},
+		}
+		// Anchor the insertion right after the last kept character.
+		if prefix > 0 {
+			anchor := units[prefix-1]
+			item.origin = &anchor.id
+			item.rightOrigin = clockAfter(runs, anchor.run, anchor.offset)
+		} else if len(runs) > 0 {
+			item.rightOrigin = &runs[0].id
+		}
+		if item.origin == nil && item.rightOrigin == nil {
+			item.parentName = name
+		}
+		structs[client] = []*block{item}
+	}
+
+	if len(structs) == 0 && len(ds) == 0 {
+		return nil
+	}
+
+	enc := NewEncoder()
+	writeStructs(enc, structs, nil)
+	ds.write(enc)
+	return enc.Bytes()
+}
+
+// nextClock returns the first clock of client not yet used in the document.
+func (d *Doc) nextClock(client uint64) uint64 {
+	refs := d.structs[client]
+	if len(refs) == 0 {
+		return 0
+	}
+	return refs[len(refs)-1].end()
+}
+
+// NewClientID returns a random client id in the 32 bit range Yjs clients use.
+func NewClientID() uint64 {
+	var buf [4]byte
+	_, _ = rand.Read(buf[:])
+	return uint64(binary.LittleEndian.Uint32(buf[:]))
+}
+
+func isHighSurrogate(c uint16) bool {
+	return c >= 0xd800 && c <= 0xdbff
+}
+
+func isLowSurrogate(c uint16) bool {
+	return c >= 0xdc00 && c <= 0xdfff
+}
@@ -0,0 +1,138 @@
+package yjs_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+)
+
+func docFromUpdates(t *testing.T, updates ...[]byte) *yjs.Doc {
+	t.Helper()
+	doc := yjs.NewDoc()
+	for _, update := range updates {
+		require.NoError(t, doc.ApplyUpdate(update))
+	}
+	return doc
+}
+
+// insertBetween encodes an update inserting text between origin and rightOrigin.
+func insertBetween(client, clock uint64, origin, rightOrigin yjs.ID, text string) []byte {
+	enc := yjs.NewEncoder()
+	enc.WriteVarUint(1)
+	enc.WriteVarUint(1)
+	enc.WriteVarUint(client)
+	enc.WriteVarUint(clock)
+	enc.WriteUint8(4 | 0x80 | 0x40)
+	enc.WriteVarUint(origin.Client)
+	enc.WriteVarUint(origin.Clock)
+	enc.WriteVarUint(rightOrigin.Client)
+	enc.WriteVarUint(rightOrigin.Clock)
+	enc.WriteVarString(text)
+	enc.WriteVarUint(0)
+	return enc.Bytes()
+}
+
+func TestDocText(t *testing.T) {
+	t.Run("RendersInsertionsAndDeletions", func(t *testing.T) {
+		doc := docFromUpdates(t,
+			rootInsert(1, 0, "hello"),
+			appendAfter(1, 5, yjs.ID{Client: 1, Clock: 4}, " world"),
+			deletion(1, 0, 1),
+		)
+
+		assert.Equal(t, "ello world", doc.Text("content"))
+		assert.Empty(t, doc.Text("other"))
+	})
+
+	t.Run("InsertsInsideExistingItem", func(t *testing.T) {
+		doc := docFromUpdates(t,
+			insertBetween(2, 0, yjs.ID{Client: 1, Clock: 1}, yjs.ID{Client: 1, Clock: 2}, "XY"),
+			rootInsert(1, 0, "hello"),
+		)
+
+		assert.Equal(t, "heXYllo", doc.Text("content"))
+	})
+
+	t.Run("OrdersConcurrentInsertsByClient", func(t *testing.T) {
+		first := docFromUpdates(t, rootInsert(2, 0, "b"), rootInsert(1, 0, "a"))
+		second := docFromUpdates(t, rootInsert(1, 0, "a"), rootInsert(2, 0, "b"))
+
+		assert.Equal(t, "ab", first.Text("content"))
+		assert.Equal(t, "ab", second.Text("content"))
+	})
+
+	t.Run("SkipsHugeDeletedRuns", func(t *testing.T) {
+		const deleted = 1 << 24
+		doc := docFromUpdates(t,
+			rootDeleted(1, 0, deleted),
+			appendAfter(1, deleted, yjs.ID{Client: 1, Clock: deleted - 1}, "hi"),
+		)
+
+		assert.Equal(t, "hi", doc.Text("content"))
+
+		require.NoError(t, doc.ApplyUpdate(doc.ReplaceText("content", "oh hi", 9)))
+		assert.Equal(t, "oh hi", doc.Text("content"))
+	})
+
+	t.Run("IgnoresItemsWithMissingDependencies", func(t *testing.T) {
+		doc := docFromUpdates(t, appendAfter(2, 0, yjs.ID{Client: 1, Clock: 3}, "x"))
+
+		assert.Empty(t, doc.Text("content"))
+	})
+}
+
+func TestDocReplaceText(t *testing.T) {
+	t.Run("EncodesInsertIntoEmptyText", func(t *testing.T) {
+		update := yjs.NewDoc().ReplaceText("content", "abc", 9)
+
+		assert.Equal(t, rootInsert(9, 0, "abc"), update)
+	})
+
+	t.Run("ReturnsNilWhenUnchanged", func(t *testing.T) {
+		doc := docFromUpdates(t, rootInsert(1, 0, "same"))
+
+		assert.Nil(t, doc.ReplaceText("content", "same", 9))
+	})
+
+	t.Run("ReplacesChangedRange", func(t *testing.T) {
+		cases := map[string]string{
+			"Insert":    "hello there world",
+			"Delete":    "hello",
+			"Replace":   "help world",
+			"Clear":     "",
+			"Surrogate": "hello 😀 world",
+		}
+		for name, target := range cases {
+			t.Run(name, func(t *testing.T) {
+				doc := docFromUpdates(t, rootInsert(1, 0, "hello world"))
+
+				update := doc.ReplaceText("content", target, 9)
+				require.NoError(t, doc.ApplyUpdate(update))
+
+				assert.Equal(t, target, doc.Text("content"))
+			})
+		}
+	})
+
+	t.Run("KeepsConcurrentEdits", func(t *testing.T) {
+		base := rootInsert(1, 0, "hello world")
+		doc := docFromUpdates(t, base)
+		update := doc.ReplaceText("content", "hello brave world", 9)
+
+		concurrent := appendAfter(3, 0, yjs.ID{Client: 1, Clock: 10}, "!")
+		merged := docFromUpdates(t, base, concurrent, update)
+
+		assert.Equal(t, "hello brave world!", merged.Text("content"))
+	})
+
+	t.Run("ContinuesClientClock", func(t *testing.T) {
+		doc := yjs.NewDoc()
+		require.NoError(t, doc.ApplyUpdate(doc.ReplaceText("content", "ab", 9)))
+		require.NoError(t, doc.ApplyUpdate(doc.ReplaceText("content", "abc", 9)))
+
+		assert.Equal(t, "abc", doc.Text("content"))
+		assert.Equal(t, yjs.StateVector{9: 3}, doc.StateVector())
+	})
+}
@@ -0,0 +1,184 @@
+//go:build func_test
+
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/seeder"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/testapp"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/testdb"
+)
+
+func TestCollaborativeEditsReachDocumentContent(t *testing.T) {
+	db, err := testdb.NewDB()
+	require.NoError(t, err)
+	require.NoError(t, testdb.ResetDB(db))
+
+	app := testapp.NewApp()
+	ctx, cancel := context.WithCancel(context.Background())
+	go app.Run(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	t.Cleanup(func() {
+		db.Close()
+		cancel()
+	})
+
+	client := logIn(t, seeder.NewSeeder(db), "editor")
+
+	// Arrange
+	groupUUID := client.create(t, "/api/v1/groups", map[string]string{"name": "group"})
+	documentUUID := client.create(t, "/api/v1/documents", map[string]string{
+		"group_uuid": groupUUID,
+		"name":       "document",
+		"content":    "",
+	})
+
+	conn, _, err := client.dial(documentUUID)
+	require.NoError(t, err)
+
+	// Act: edit over the websocket, then leave so the room closes
+	doc := yjs.NewDoc()
+	update := doc.ReplaceText("content", "# Notes\n\nwritten live", yjs.NewClientID())
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, updateFrame(update)))
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, conn.Close())
+
+	// Assert
+	require.Eventually(t, func() bool {
+		resp := client.do(t, http.MethodGet, "/api/v1/documents/"+documentUUID, nil)
+		defer resp.Body.Close()
+
+		var response struct {
+			Content string `json:"content"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&response) != nil {
+			return false
+		}
+		return response.Content == "# Notes\n\nwritten live"
+	}, 5*time.Second, 50*time.Millisecond)
+
+	resp := client.do(t, http.MethodGet, "/api/v1/documents/"+documentUUID, nil)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestRESTUpdatesReachLiveRoom(t *testing.T) {
+	db, err := testdb.NewDB()
+	require.NoError(t, err)
+	require.NoError(t, testdb.ResetDB(db))
+
+	app := testapp.NewApp()
+	ctx, cancel := context.WithCancel(context.Background())
+	go app.Run(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	t.Cleanup(func() {
+		db.Close()
+		cancel()
+	})
+
+	client := logIn(t, seeder.NewSeeder(db), "editor")
+
+	// Arrange: a live editor with some text
+	groupUUID := client.create(t, "/api/v1/groups", map[string]string{"name": "group"})
+	documentUUID := client.create(t, "/api/v1/documents", map[string]string{
+		"group_uuid": groupUUID,
+		"name":       "document",
+		"content":    "",
+	})
+
+	conn, _, err := client.dial(documentUUID)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	local := yjs.NewDoc()
+	update := local.ReplaceText("content", "typed live", yjs.NewClientID())
+	require.NoError(t, local.ApplyUpdate(update))
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, updateFrame(update)))
+	time.Sleep(50 * time.Millisecond)
+
+	// Act
+	resp := client.do(t, http.MethodPut, "/api/v1/documents/"+documentUUID, map[string]string{
+		"name":    "document",
+		"content": "written over REST",
+	})
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Assert: the editor receives the change as a Yjs update
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for local.Text("content") != "written over REST" {
+		_, message, err := conn.ReadMessage()
+		require.NoError(t, err)
+
+		dec := yjs.NewDecoder(message)
+		msgType, err := dec.ReadVarUint()
+		require.NoError(t, err)
+		step, err := dec.ReadVarUint()
+		if msgType != 0 || err != nil || step != 2 {
+			continue
+		}
+		payload, err := dec.ReadVarUint8Array()
+		require.NoError(t, err)
+		require.NoError(t, local.ApplyUpdate(payload))
+	}
+}
+
+func TestCollaborativeUpdatesAreStoredWithHubVersions(t *testing.T) {
+	db, err := testdb.NewDB()
+	require.NoError(t, err)
+	require.NoError(t, testdb.ResetDB(db))
+
+	app := testapp.NewApp()
+	ctx, cancel := context.WithCancel(context.Background())
+	go app.Run(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	t.Cleanup(func() {
+		db.Close()
+		cancel()
+	})
+
+	s := seeder.NewSeeder(db)
+	client := logIn(t, s, "editor")
+
+	// Arrange
+	groupUUID := client.create(t, "/api/v1/groups", map[string]string{"name": "group"})
+	documentUUID := client.create(t, "/api/v1/documents", map[string]string{
+		"group_uuid": groupUUID,
+		"name":       "document",
+		"content":    "",
+	})
+
+	conn, _, err := client.dial(documentUUID)
+	require.NoError(t, err)
+
+	// Act: type a few edits quickly, then leave
+	doc := yjs.NewDoc()
+	clientID := yjs.NewClientID()
+	for _, text := range []string{"a", "ab", "abc"} {
+		update := doc.ReplaceText("content", text, clientID)
+		require.NoError(t, doc.ApplyUpdate(update))
+		require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, updateFrame(update)))
+	}
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, conn.Close())
+
+	// Assert: every edit is stored once, with consecutive versions
+	var versions []int
+	require.Eventually(t, func() bool {
+		versions, err = s.GetUpdateVersions(documentUUID)
+		return err == nil && len(versions) == 3
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, []int{versions[0], versions[0] + 1, versions[0] + 2}, versions)
+}
@@ -0,0 +1,239 @@
+//go:build func_test
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/seeder"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/testapp"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/testdb"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const messageTypeControl = 100
+
+// apiClient sends authenticated requests to the test app.
+type apiClient struct {
+	cookies []*http.Cookie
+}
+
+func (c *apiClient) do(t *testing.T, method, path string, body any) *http.Response {
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(jsonBody)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, testapp.Addr+path, reader) //nolint:noctx
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func (c *apiClient) create(t *testing.T, path string, body any) string {
+	t.Helper()
+
+	resp := c.do(t, http.MethodPost, path, body)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var response struct {
+		UUID string `json:"uuid"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return response.UUID
+}
+
+func (c *apiClient) dial(documentUUID string) (*websocket.Conn, *http.Response, error) {
+	return c.dialQuery(documentUUID, "")
+}
+
+// dialQuery opens a collaboration websocket with an encoded query string.
+func (c *apiClient) dialQuery(documentUUID, query string) (*websocket.Conn, *http.Response, error) {
+	header := http.Header{}
+	header.Set("Origin", "http://localhost:3000")
+	for _, cookie := range c.cookies {
+		header.Add("Cookie", cookie.String())
+	}
+	url := strings.Replace(testapp.Addr, "http", "ws", 1) + "/ws/documents/" + documentUUID
+	if query != "" {
+		url += "?" + query
+	}
+	return websocket.DefaultDialer.Dial(url, header)
+}
+
+func logIn(t *testing.T, s *seeder.Seeder, login string) *apiClient {
+	t.Helper()
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	require.NoError(t, s.NewUser().WithLogin(login).WithEmail(login+"@example.com").WithHashedPassword(string(hashed)).Create())
+
+	jsonBody, err := json.Marshal(map[string]string{"login": login, "password": "password123"})
+	require.NoError(t, err)
+	resp, err := http.Post(testapp.Addr+"/api/v1/auth/login", "application/json", bytes.NewBuffer(jsonBody))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	return &apiClient{cookies: resp.Cookies()}
+}
+
+// updateFrame wraps a Yjs update into a y-websocket sync frame.
+func updateFrame(update []byte) []byte {
+	enc := yjs.NewEncoder()
+	enc.WriteVarUint(0) // sync message
+	enc.WriteVarUint(2) // update
+	enc.WriteVarUint8Array(update)
+	return enc.Bytes()
+}
+
+// editContinuously sends document updates until the connection breaks or stop
+// is closed.
+func editContinuously(conn *websocket.Conn, stop <-chan struct{}) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		doc := yjs.NewDoc()
+		client := yjs.NewClientID()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			update := doc.ReplaceText("content", fmt.Sprintf("edit %d", i), client)
+			if err := doc.ApplyUpdate(update); err != nil {
+				return
+			}
+			if err := conn.WriteMessage(websocket.BinaryMessage, updateFrame(update)); err != nil {
+				return
+			}
+			time.Sleep(2 * time.Millisecond)
+		}
+	}()
+	return &wg
+}
+
+// awaitControl reads frames until a control notice arrives and returns its type.
+func awaitControl(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		_, message, err := conn.ReadMessage()
+		require.NoError(t, err)
+
+		dec := yjs.NewDecoder(message)
+		msgType, err := dec.ReadVarUint()
+		require.NoError(t, err)
+		if msgType != messageTypeControl {
+			continue
+		}
+
+		payload, err := dec.ReadVarString()
+		require.NoError(t, err)
+		var control struct {
+			Type string `json:"type"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(payload), &control))
+		return control.Type
+	}
+}
+
+func TestDeleteClosesCollaborationRoom(main *testing.T) {
+	setup := func(t *testing.T) (*seeder.Seeder, *apiClient) {
+		db, err := testdb.NewDB()
+		require.NoError(t, err)
+		require.NoError(t, testdb.ResetDB(db))
+
+		app := testapp.NewApp()
+		ctx, cancel := context.WithCancel(context.Background())
+		go app.Run(ctx)
+		time.Sleep(100 * time.Millisecond)
+
+		t.Cleanup(func() {
+			db.Close()
+			cancel()
+		})
+
+		s := seeder.NewSeeder(db)
+		return s, logIn(t, s, "author")
+	}
+
+	for name, deletePath := range map[string]func(groupUUID, documentUUID string) string{
+		"Document": func(_, documentUUID string) string { return "/api/v1/documents/" + documentUUID },
+		"Group":    func(groupUUID, _ string) string { return "/api/v1/groups/" + groupUUID },
+	} {
+		main.Run(name, func(t *testing.T) {
+			s, client := setup(t)
+
+			// Arrange
+			groupUUID := client.create(t, "/api/v1/groups", map[string]string{"name": "group"})
+			documentUUID := client.create(t, "/api/v1/documents", map[string]string{
+				"group_uuid": groupUUID,
+				"name":       "document",
+				"content":    "",
+			})
+
+			conn, _, err := client.dial(documentUUID)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			stop := make(chan struct{})
+			editing := editContinuously(conn, stop)
+
+			// Act: delete while the client keeps editing
+			time.Sleep(50 * time.Millisecond)
+			resp := client.do(t, http.MethodDelete, deletePath(groupUUID, documentUUID), nil)
+			resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			// Assert
+			assert.Equal(t, "document_deleted", awaitControl(t, conn))
+			require.Eventually(t, func() bool {
+				_, _, err := conn.ReadMessage()
+				return err != nil
+			}, 5*time.Second, 10*time.Millisecond)
+			close(stop)
+			editing.Wait()
+
+			// Nothing written by the room outlives the document.
+			time.Sleep(100 * time.Millisecond)
+			rows, err := s.CountCollabRows(documentUUID)
+			require.NoError(t, err)
+			assert.Zero(t, rows)
+
+			_, resp, err = client.dial(documentUUID)
+			require.Error(t, err)
+			require.NotNil(t, resp)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+//go:build func_test
+
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/seeder"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/testapp"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/testdb"
+)
+
+// awaitHandshake reads frames until the handshake arrives and returns the
+// version it carries.
+func awaitHandshake(t *testing.T, conn *websocket.Conn) int {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		_, message, err := conn.ReadMessage()
+		require.NoError(t, err)
+
+		dec := yjs.NewDecoder(message)
+		msgType, err := dec.ReadVarUint()
+		require.NoError(t, err)
+		if msgType != messageTypeControl {
+			continue
+		}
+
+		payload, err := dec.ReadVarString()
+		require.NoError(t, err)
+		var control struct {
+			Type    string `json:"type"`
+			Version int    `json:"version"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(payload), &control))
+		if control.Type == "handshake" {
+			return control.Version
+		}
+	}
+}
+
+// syncStep1Frame wraps a state vector into a y-websocket SyncStep1 frame.
+func syncStep1Frame(stateVector []byte) []byte {
+	enc := yjs.NewEncoder()
+	enc.WriteVarUint(0) // sync message
+	enc.WriteVarUint(0) // step 1
+	enc.WriteVarUint8Array(stateVector)
+	return enc.Bytes()
+}
+
+func TestReconnectCatchesUpOnMissedUpdates(t *testing.T) {
+	db, err := testdb.NewDB()
+	require.NoError(t, err)
+	require.NoError(t, testdb.ResetDB(db))
+
+	app := testapp.NewApp()
+	ctx, cancel := context.WithCancel(context.Background())
+	go app.Run(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	t.Cleanup(func() {
+		db.Close()
+		cancel()
+	})
+
+	client := logIn(t, seeder.NewSeeder(db), "editor")
+
+	// Arrange: an editor writes, remembers the handshake version and leaves
+	groupUUID := client.create(t, "/api/v1/groups", map[string]string{"name": "group"})
+	documentUUID := client.create(t, "/api/v1/documents", map[string]string{
+		"group_uuid": groupUUID,
+		"name":       "document",
+		"content":    "",
+	})
+
+	conn, _, err := client.dial(documentUUID)
+	require.NoError(t, err)
+	version := awaitHandshake(t, conn)
+
+	local := yjs.NewDoc()
+	update := local.ReplaceText("content", "first", yjs.NewClientID())
+	require.NoError(t, local.ApplyUpdate(update))
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, updateFrame(update)))
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, conn.Close())
+
+	// while it is away, someone else keeps editing
+	other, _, err := client.dial(documentUUID)
+	require.NoError(t, err)
+	remote := local.ReplaceText("content", "first and second", yjs.NewClientID())
+	require.NoError(t, other.WriteMessage(websocket.BinaryMessage, updateFrame(remote)))
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, other.Close())
+	time.Sleep(50 * time.Millisecond)
+
+	// Act: reconnect from the remembered version with the local state vector
+	conn, _, err = client.dialQuery(documentUUID, "version="+strconv.Itoa(version))
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Greater(t, awaitHandshake(t, conn), version)
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, syncStep1Frame(local.EncodeStateVector())))
+
+	// Assert
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		_, message, err := conn.ReadMessage()
+		require.NoError(t, err)
+
+		dec := yjs.NewDecoder(message)
+		msgType, err := dec.ReadVarUint()
+		require.NoError(t, err)
+		step, err := dec.ReadVarUint()
+		if msgType != 0 || err != nil || step != 1 {
+			continue
+		}
+		payload, err := dec.ReadVarUint8Array()
+		require.NoError(t, err)
+		require.NoError(t, local.ApplyUpdate(payload))
+		break
+	}
+	assert.Equal(t, "first and second", local.Text("content"))
+}
@@ -0,0 +1,71 @@
+//go:build func_test
+
+package api_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/pkg/yjs"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/seeder"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/testapp"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/testdb"
+)
+
+func TestShutdownSavesRoomsAndAsksClientsToReconnect(t *testing.T) {
+	db, err := testdb.NewDB()
+	require.NoError(t, err)
+	require.NoError(t, testdb.ResetDB(db))
+	t.Cleanup(func() { db.Close() })
+
+	app := testapp.NewApp()
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		app.Run(ctx)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	t.Cleanup(cancel)
+
+	s := seeder.NewSeeder(db)
+	client := logIn(t, s, "editor")
+
+	// Arrange: an edit that is still buffered when the server stops
+	groupUUID := client.create(t, "/api/v1/groups", map[string]string{"name": "group"})
+	documentUUID := client.create(t, "/api/v1/documents", map[string]string{
+		"group_uuid": groupUUID,
+		"name":       "document",
+		"content":    "",
+	})
+
+	conn, _, err := client.dial(documentUUID)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	update := yjs.NewDoc().ReplaceText("content", "typed before restart", yjs.NewClientID())
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, updateFrame(update)))
+	time.Sleep(20 * time.Millisecond)
+
+	// Act
+	cancel()
+
+	// Assert
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		_, _, err = conn.ReadMessage()
+		if err != nil {
+			break
+		}
+	}
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseServiceRestart), "unexpected close: %v", err)
+
+	<-stopped
+	versions, err := s.GetUpdateVersions(documentUUID)
+	require.NoError(t, err)
+	assert.Len(t, versions, 1)
+}
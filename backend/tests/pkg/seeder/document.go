@@ -0,0 +1,51 @@
+package seeder
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+)
+
+// countCollabRows counts the rows collaboration rooms store for a document.
+func countCollabRows(db *sql.DB, documentUUID string) (int, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM document_snapshots WHERE document_id = $1) +
+			(SELECT COUNT(*) FROM document_updates WHERE document_id = $1) +
+			(SELECT COUNT(*) FROM document_presence WHERE document_id = $1)`
+
+	var count int
+	if err := db.QueryRow(query, documentUUID).Scan(&count); err != nil { //nolint:noctx
+		return 0, errors.Join(domain.ErrInternal, fmt.Errorf("document seeder: countCollabRows: %w", err))
+	}
+
+	return count, nil
+}
+
+// getUpdateVersions lists the versions of the stored updates of a document in
+// insertion order.
+func getUpdateVersions(db *sql.DB, documentUUID string) ([]int, error) {
+	query := `SELECT version FROM document_updates WHERE document_id = $1 ORDER BY id`
+
+	rows, err := db.Query(query, documentUUID) //nolint:noctx
+	if err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document seeder: getUpdateVersions: %w", err))
+	}
+	defer rows.Close()
+
+	var versions []int
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document seeder: getUpdateVersions: %w", err))
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Join(domain.ErrInternal, fmt.Errorf("document seeder: getUpdateVersions: %w", err))
+	}
+
+	return versions, nil
+}
@@ -27,3 +27,11 @@ func (s *Seeder) GetUserByEmail(email string) (*User, error) {
 func (s *Seeder) GetUserByUUID(uuid string) (*User, error) {
 	return getUserByUUID(s.db, uuid)
 }
+
+func (s *Seeder) CountCollabRows(documentUUID string) (int, error) {
+	return countCollabRows(s.db, documentUUID)
+}
+
+func (s *Seeder) GetUpdateVersions(documentUUID string) ([]int, error) {
+	return getUpdateVersions(s.db, documentUUID)
+}
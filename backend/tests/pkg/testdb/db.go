@@ -5,8 +5,11 @@ import (
 	"errors"
 )
 
+// DSN is the connection string of the test database.
+const DSN = "host=localhost port=5433 user=postgres password=postgres dbname=mcd sslmode=disable"
+
 func NewDB() (*sql.DB, error) {
-	db, err := sql.Open("postgres", "host=localhost port=5433 user=postgres password=postgres dbname=mcd sslmode=disable")
+	db, err := sql.Open("postgres", DSN)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,55 @@
+//go:build func_test
+
+package repo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/testdb"
+	"go.uber.org/zap"
+)
+
+func TestNotifyBrokerSubscriptions(t *testing.T) {
+	db, err := testdb.NewDB()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	newBroker := func(t *testing.T) *repo.NotifyBroker {
+		broker := repo.NewNotifyBroker(db, testdb.DSN, zap.NewNop())
+		t.Cleanup(func() {
+			broker.Close()
+		})
+		return broker
+	}
+
+	t.Run("StaleUnsubscribeKeepsTheNewSubscription", func(t *testing.T) {
+		// Arrange: a new hub subscribed before the old one unsubscribed
+		publisher, subscriber := newBroker(t), newBroker(t)
+		documentID := uuid.New()
+		received := make(chan []byte, 1)
+
+		old, err := subscriber.Subscribe(documentID, func([]byte) {}, func() {})
+		require.NoError(t, err)
+		_, err = subscriber.Subscribe(documentID, func(message []byte) { received <- message }, func() {})
+		require.NoError(t, err)
+
+		// Act
+		require.NoError(t, subscriber.Unsubscribe(documentID, old))
+		publisher.Publish(documentID, []byte("update"))
+
+		// Assert
+		select {
+		case message := <-received:
+			assert.Equal(t, []byte("update"), message)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the new subscription stopped receiving messages")
+		}
+	})
+}
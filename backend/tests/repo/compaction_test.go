@@ -0,0 +1,173 @@
+//go:build func_test
+
+package repo_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/testdb"
+)
+
+const retention = 48 * time.Hour
+
+// compactionFixture is a document with a snapshot at version 3, compacted up
+// to version 2, and updates 1 to 3. Updates 1 and 3 are older than the
+// retention period; update 2 is recent.
+type compactionFixture struct {
+	db         *sql.DB
+	documentID uuid.UUID
+	updateIDs  map[int]int64
+}
+
+func setupCompaction(t *testing.T) *compactionFixture {
+	t.Helper()
+
+	db, err := testdb.NewDB()
+	require.NoError(t, err)
+	require.NoError(t, testdb.ResetDB(db))
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	f := &compactionFixture{db: db, documentID: uuid.New(), updateIDs: make(map[int]int64)}
+	userID := uuid.New()
+	groupID := uuid.New()
+
+	_, err = db.Exec(`INSERT INTO users (uuid, login, email, hashed_password) VALUES ($1, $2, $3, 'hash')`, //nolint:noctx
+		userID, "compaction-"+userID.String()[:8], userID.String()+"@example.com")
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO groups (uuid, name) VALUES ($1, 'compaction')`, groupID) //nolint:noctx
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO documents (uuid, group_uuid, name) VALUES ($1, $2, 'compaction')`, f.documentID, groupID) //nolint:noctx
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO document_snapshots (document_id, yjs_snapshot, version, compacted_version, history_base_version)
+		VALUES ($1, '\x00', 3, 2, 1)`, f.documentID) //nolint:noctx
+	require.NoError(t, err)
+
+	ages := map[int]time.Duration{1: 72 * time.Hour, 2: time.Hour, 3: 72 * time.Hour}
+	for version := 1; version <= 3; version++ {
+		var id int64
+		err = db.QueryRow(`
+			INSERT INTO document_updates (document_id, yjs_update, user_id, version, created_at)
+			VALUES ($1, '\x0000', $2, $3, NOW() - make_interval(secs => $4))
+			RETURNING id`, f.documentID, userID, version, ages[version].Seconds()).Scan(&id) //nolint:noctx
+		require.NoError(t, err)
+		f.updateIDs[version] = id
+	}
+
+	return f
+}
+
+func (f *compactionFixture) updateVersions(t *testing.T, table string) []int {
+	t.Helper()
+
+	rows, err := f.db.Query(`SELECT version FROM `+table+` WHERE document_id = $1 ORDER BY version`, f.documentID) //nolint:noctx
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var versions []int
+	for rows.Next() {
+		var version int
+		require.NoError(t, rows.Scan(&version))
+		versions = append(versions, version)
+	}
+	require.NoError(t, rows.Err())
+	return versions
+}
+
+func TestDocumentCompaction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ListsDocumentsWithUnfoldedUpdates", func(t *testing.T) {
+		f := setupCompaction(t)
+		persistence := repo.NewDocumentPersistence(f.db)
+
+		documentIDs, err := persistence.ListCompactionCandidates(ctx, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{f.documentID}, documentIDs)
+	})
+
+	t.Run("SavesCompactedSnapshotOfUnchangedVersion", func(t *testing.T) {
+		f := setupCompaction(t)
+		persistence := repo.NewDocumentPersistence(f.db)
+
+		stale, err := persistence.SaveCompactedSnapshot(ctx, f.documentID, []byte{1}, 2, 3)
+		require.NoError(t, err)
+		assert.False(t, stale)
+
+		saved, err := persistence.SaveCompactedSnapshot(ctx, f.documentID, []byte{1}, 3, 3)
+		require.NoError(t, err)
+		assert.True(t, saved)
+
+		snapshot, err := persistence.LoadSnapshot(ctx, f.documentID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, snapshot.CompactedVersion)
+		assert.Equal(t, []byte{1}, snapshot.YjsSnapshot)
+	})
+
+	t.Run("PrunesOnlyFoldedUpdatesPastRetention", func(t *testing.T) {
+		f := setupCompaction(t)
+		persistence := repo.NewDocumentPersistence(f.db)
+
+		documentIDs, err := persistence.ListPrunableDocuments(ctx, retention, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{f.documentID}, documentIDs)
+
+		updates, err := persistence.GetPrunableUpdates(ctx, f.documentID, retention)
+		require.NoError(t, err)
+		require.Len(t, updates, 1)
+		assert.Equal(t, 1, updates[0].Version)
+
+		documentIDs, err = persistence.ListPrunableDocuments(ctx, 100*time.Hour, 10)
+		require.NoError(t, err)
+		assert.Empty(t, documentIDs)
+	})
+
+	t.Run("DeletesPrunedUpdatesAndStoresHistoryBase", func(t *testing.T) {
+		f := setupCompaction(t)
+		persistence := repo.NewDocumentPersistence(f.db)
+
+		err := persistence.PruneUpdates(ctx, f.documentID, []byte{7}, 1, []int64{f.updateIDs[1]})
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{2, 3}, f.updateVersions(t, "document_updates"))
+		base, baseVersion, err := persistence.LoadHistoryBase(ctx, f.documentID)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{7}, base)
+		assert.Equal(t, 1, baseVersion)
+	})
+
+	t.Run("KeepsHighestHistoryBaseVersion", func(t *testing.T) {
+		f := setupCompaction(t)
+		persistence := repo.NewDocumentPersistence(f.db)
+
+		err := persistence.PruneUpdates(ctx, f.documentID, []byte{7}, 0, nil)
+		require.NoError(t, err)
+
+		_, baseVersion, err := persistence.LoadHistoryBase(ctx, f.documentID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, baseVersion)
+		assert.Equal(t, []int{1, 2, 3}, f.updateVersions(t, "document_updates"))
+	})
+
+	t.Run("ArchivesOnlyFoldedUpdatesPastRetention", func(t *testing.T) {
+		f := setupCompaction(t)
+		persistence := repo.NewDocumentPersistence(f.db)
+
+		archived, err := persistence.ArchiveCompactedUpdates(ctx, retention)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), archived)
+		assert.Equal(t, []int{2, 3}, f.updateVersions(t, "document_updates"))
+		assert.Equal(t, []int{1}, f.updateVersions(t, "document_updates_archive"))
+	})
+}
@@ -0,0 +1,73 @@
+//go:build func_test
+
+package repo_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo"
+)
+
+func TestDocumentPersistenceVersions(t *testing.T) {
+	ctx := context.Background()
+
+	batch := func(documentID uuid.UUID, n int) []repo.UpdateRecord {
+		updates := make([]repo.UpdateRecord, n)
+		for i := range updates {
+			updates[i] = repo.UpdateRecord{DocumentID: documentID, YjsUpdate: []byte{0, 0}, UserID: uuid.New(), CreatedAt: time.Now()}
+		}
+		return updates
+	}
+
+	t.Run("SaveUpdatesAssignsVersionsAfterLatest", func(t *testing.T) {
+		f := setupCompaction(t)
+		persistence := repo.NewDocumentPersistence(f.db)
+
+		version, err := persistence.SaveUpdates(ctx, batch(f.documentID, 2))
+
+		require.NoError(t, err)
+		assert.Equal(t, 5, version)
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, f.updateVersions(t, "document_updates"))
+	})
+
+	t.Run("ConcurrentBatchesClaimDistinctVersions", func(t *testing.T) {
+		f := setupCompaction(t)
+		persistence := repo.NewDocumentPersistence(f.db)
+
+		var wg sync.WaitGroup
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := persistence.SaveUpdates(ctx, batch(f.documentID, 3))
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, f.updateVersions(t, "document_updates"))
+	})
+
+	t.Run("SaveSnapshotKeepsNewerSnapshot", func(t *testing.T) {
+		f := setupCompaction(t)
+		persistence := repo.NewDocumentPersistence(f.db)
+
+		require.NoError(t, persistence.SaveSnapshot(ctx, f.documentID, []byte{1}, 2, uuid.Nil))
+		snapshot, err := persistence.LoadSnapshot(ctx, f.documentID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, snapshot.Version)
+		assert.Equal(t, []byte{0}, snapshot.YjsSnapshot)
+
+		require.NoError(t, persistence.SaveSnapshot(ctx, f.documentID, []byte{1}, 4, uuid.Nil))
+		snapshot, err = persistence.LoadSnapshot(ctx, f.documentID)
+		require.NoError(t, err)
+		assert.Equal(t, 4, snapshot.Version)
+		assert.Equal(t, []byte{1}, snapshot.YjsSnapshot)
+	})
+}
@@ -0,0 +1,56 @@
+//go:build func_test
+
+package repo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/domain"
+	"github.com/ukma-cs-ssdm-2025/team-circus/internal/repo/user"
+	"github.com/ukma-cs-ssdm-2025/team-circus/tests/pkg/testdb"
+)
+
+func TestUserRepositoryHidesGuestUser(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := testdb.NewDB()
+	require.NoError(t, err)
+	require.NoError(t, testdb.ResetDB(db))
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	_, err = db.Exec(`
+		INSERT INTO users (uuid, login, email, hashed_password)
+		VALUES ($1, 'share-link-guest', 'guest@share-link.invalid', '!')
+		ON CONFLICT DO NOTHING`, domain.GuestUserUUID) //nolint:noctx
+	require.NoError(t, err)
+	memberID := uuid.New()
+	_, err = db.Exec(`INSERT INTO users (uuid, login, email, hashed_password) VALUES ($1, 'member', 'member@example.com', 'hash')`, //nolint:noctx
+		memberID)
+	require.NoError(t, err)
+
+	users := user.NewUserRepository(db)
+
+	t.Run("ListsOnlyAccounts", func(t *testing.T) {
+		all, err := users.GetAll(ctx)
+
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.Equal(t, memberID, all[0].UUID)
+	})
+
+	t.Run("DoesNotFindGuestUser", func(t *testing.T) {
+		byUUID, err := users.GetByUUID(ctx, domain.GuestUserUUID)
+		require.NoError(t, err)
+		assert.Nil(t, byUUID)
+
+		byLogin, err := users.GetByLogin(ctx, "share-link-guest")
+		require.NoError(t, err)
+		assert.Nil(t, byLogin)
+	})
+}
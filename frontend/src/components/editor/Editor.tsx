@@ -105,6 +105,11 @@ const buildTheme = (mode: "light" | "dark") => {
 	);
 };
 
+const buildReadOnly = (readOnly: boolean) => [
+	EditorState.readOnly.of(readOnly),
+	EditorView.editable.of(!readOnly),
+];
+
 export const Editor = ({
 	value,
 	defaultValue = "",
@@ -112,6 +117,7 @@ export const Editor = ({
 	onCursorChange,
 	remoteUsers = [],
 	isConnected = false,
+	readOnly = false,
 	showPresence = true,
 	className = "",
 	ariaLabel = "Collaborative editor",
@@ -124,6 +130,8 @@ export const Editor = ({
 	const hostRef = useRef<HTMLDivElement | null>(null);
 	const viewRef = useRef<EditorView | null>(null);
 	const themeCompartmentRef = useRef(new Compartment());
+	const readOnlyCompartmentRef = useRef(new Compartment());
+	const readOnlyRef = useRef(readOnly);
 	const updatingFromProps = useRef(false);
 	const lastCursorPosition = useRef<number | null>(null);
 	const initialDocRef = useRef(value ?? defaultValue ?? "");
@@ -154,6 +162,7 @@ export const Editor = ({
 		}
 
 		const themeCompartment = themeCompartmentRef.current;
+		const readOnlyCompartment = readOnlyCompartmentRef.current;
 		const state = EditorState.create({
 			doc: initialDocRef.current,
 			extensions: [
@@ -166,6 +175,7 @@ export const Editor = ({
 				history(),
 				keymap.of([...defaultKeymap, ...historyKeymap]),
 				themeCompartment.of(buildTheme(scheme)),
+				readOnlyCompartment.of(buildReadOnly(readOnlyRef.current)),
 				EditorView.contentAttributes.of({
 					"aria-label": ariaLabel,
 					spellcheck: "false",
@@ -269,6 +279,18 @@ export const Editor = ({
 		});
 	}, [scheme]);
 
+	useEffect(() => {
+		readOnlyRef.current = readOnly;
+		if (!viewRef.current) {
+			return;
+		}
+		viewRef.current.dispatch({
+			effects: readOnlyCompartmentRef.current.reconfigure(
+				buildReadOnly(readOnly),
+			),
+		});
+	}, [readOnly]);
+
 	const editorClass = ["editor-frame", className].filter(Boolean).join(" ");
 
 	return (
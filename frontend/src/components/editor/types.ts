@@ -20,6 +20,7 @@ export type EditorProps = {
 	onCursorChange?: (position: number) => void;
 	remoteUsers?: RemoteUserPresence[];
 	isConnected?: boolean;
+	readOnly?: boolean;
 	showPresence?: boolean;
 	className?: string;
 	ariaLabel?: string;
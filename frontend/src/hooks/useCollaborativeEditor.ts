@@ -3,6 +3,7 @@ import type { Awareness } from "y-protocols/awareness";
 import type * as Y from "yjs";
 import {
 	type CollaborativeUser,
+	type ControlNotice,
 	YjsCollaborativeEditor,
 } from "../services/YjsWebsocketProvider";
 
@@ -27,6 +28,12 @@ export interface AwarenessState {
 	cursorPosition?: number;
 }
 
+// SessionEnd is why the server closed the session for good.
+export type SessionEnd = Extract<
+	ControlNotice["type"],
+	"access_revoked" | "document_deleted"
+>;
+
 export interface RemoteUser {
 	id: string;
 	name?: string;
@@ -59,6 +66,8 @@ export function useCollaborativeEditor(options: UseCollaborativeEditorOptions) {
 	const [isConnected, setIsConnected] = useState(false);
 	const [remoteUsers, setRemoteUsers] = useState<RemoteUser[]>([]);
 	const [yDoc, setYDoc] = useState<Y.Doc | null>(null);
+	const [canEdit, setCanEdit] = useState(true);
+	const [sessionEnd, setSessionEnd] = useState<SessionEnd | null>(null);
 
 	const providerRef = useRef<YjsCollaborativeEditor | null>(null);
 
@@ -97,6 +106,8 @@ export function useCollaborativeEditor(options: UseCollaborativeEditorOptions) {
 			setRemoteUsers([]);
 			setIsConnected(false);
 			setYDoc(null);
+			setCanEdit(true);
+			setSessionEnd(null);
 			return;
 		}
 
@@ -131,7 +142,24 @@ export function useCollaborativeEditor(options: UseCollaborativeEditorOptions) {
 		};
 		collaborativeProvider.getProvider().on("status", handleStatusChange);
 
+		// The server tells the session its access on connect and whenever it
+		// changes; revoked access and deleted documents end the session.
+		const stopControl = collaborativeProvider.onControl((notice) => {
+			switch (notice.type) {
+				case "handshake":
+				case "permission_changed":
+					setCanEdit(notice.can_edit ?? false);
+					break;
+				case "access_revoked":
+				case "document_deleted":
+					setCanEdit(false);
+					setSessionEnd(notice.type);
+					break;
+			}
+		});
+
 		return () => {
+			stopControl();
 			text.unobserve(handleTextChange);
 			awareness.off("change", handleAwarenessChange);
 			collaborativeProvider.getProvider().off("status", handleStatusChange);
@@ -140,6 +168,8 @@ export function useCollaborativeEditor(options: UseCollaborativeEditorOptions) {
 			setYDoc(null);
 			setIsConnected(false);
 			setRemoteUsers([]);
+			setCanEdit(true);
+			setSessionEnd(null);
 		};
 	}, [
 		baseUrl,
@@ -211,5 +241,7 @@ export function useCollaborativeEditor(options: UseCollaborativeEditorOptions) {
 		remoteUsers,
 		yDoc,
 		awareness,
+		canEdit,
+		sessionEnd,
 	};
 }
@@ -5,7 +5,8 @@ import { useApi, useMutation } from "./useApi";
 
 type SavePayload = {
 	name: string;
-	content: string;
+	// Leaving content out keeps the text stored on the server.
+	content?: string;
 };
 
 type DocumentSyncReturn = {
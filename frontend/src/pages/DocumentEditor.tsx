@@ -98,11 +98,21 @@ const DocumentEditor = ({ className = "" }: DocumentEditorProps) => {
 		remoteUsers,
 		updateCursorPosition,
 		yDoc,
+		canEdit,
+		sessionEnd,
 	} = useCollaborativeEditor({
 		documentId,
 		user: collaborativeUser,
 	});
 
+	// A revoked access or a deleted document ends the session; there is
+	// nothing left to edit here.
+	useEffect(() => {
+		if (sessionEnd) {
+			navigate(ROUTES.DOCUMENTS, { replace: true });
+		}
+	}, [navigate, sessionEnd]);
+
 	useEffect(() => {
 		if (documentData?.name) {
 			setDocName(documentData.name);
@@ -167,21 +177,17 @@ const DocumentEditor = ({ className = "" }: DocumentEditorProps) => {
 	}, [documentData, setContent, yDoc]);
 
 	const debouncedContent = useDebounce(content, 300);
-	const debouncedSaveState = useDebounce(
-		{
-			name: docName,
-			content,
-		},
-		800,
-	);
+	// The text is saved by the collaboration session, which merges the edits
+	// of every collaborator; a full-text PUT from this copy could undo theirs.
+	// Only renames go through the REST API.
+	const debouncedName = useDebounce(docName, 800);
 
 	useEffect(() => {
 		if (!documentData) {
 			return;
 		}
 
-		const payloadName = debouncedSaveState.name.trim();
-		const payloadContent = debouncedSaveState.content ?? "";
+		const payloadName = debouncedName.trim();
 
 		if (!payloadName) {
 			return;
@@ -192,10 +198,7 @@ const DocumentEditor = ({ className = "" }: DocumentEditorProps) => {
 			content: documentData.content ?? "",
 		};
 
-		if (
-			lastSaved.name === payloadName &&
-			lastSaved.content === payloadContent
-		) {
+		if (lastSaved.name === payloadName) {
 			return;
 		}
 
@@ -205,7 +208,6 @@ const DocumentEditor = ({ className = "" }: DocumentEditorProps) => {
 
 		saveDocument({
 			name: payloadName,
-			content: payloadContent,
 		})
 			.then((saved) => {
 				if (requestId !== saveRequestIdRef.current) {
@@ -213,7 +215,7 @@ const DocumentEditor = ({ className = "" }: DocumentEditorProps) => {
 				}
 				lastSavedRef.current = {
 					name: saved.name,
-					content: saved.content ?? payloadContent,
+					content: saved.content ?? lastSaved.content,
 				};
 				setSaveError(null);
 			})
@@ -227,7 +229,7 @@ const DocumentEditor = ({ className = "" }: DocumentEditorProps) => {
 						: t("documentEditor.saveError");
 				setSaveError(message || t("documentEditor.saveError"));
 			});
-	}, [debouncedSaveState, documentData, saveDocument, t]);
+	}, [debouncedName, documentData, saveDocument, t]);
 
 	const buildFileName = useCallback(
 		(extension: string) => {
@@ -384,6 +386,7 @@ const DocumentEditor = ({ className = "" }: DocumentEditorProps) => {
 								onChange={setContent}
 								onCursorChange={updateCursorPosition}
 								isConnected={isConnected}
+								readOnly={!canEdit}
 								remoteUsers={remoteUsers.map((remoteUser) => ({
 									...remoteUser,
 									name: remoteUser.name ?? remoteUser.id,
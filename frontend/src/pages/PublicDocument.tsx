@@ -80,7 +80,7 @@ const PublicDocument = () => {
 		[createFallbackGuestId, sigParam],
 	);
 
-	const { content: liveContent } = useCollaborativeEditor({
+	const { content: liveContent, sessionEnd } = useCollaborativeEditor({
 		documentId: docParam || "unknown",
 		user: guestUser,
 		wsPath: "/ws/public/documents",
@@ -131,6 +131,17 @@ const PublicDocument = () => {
 			.finally(() => setLoading(false));
 	}, [docParam, expParam, sigParam, t]);
 
+	// A revoked link or a deleted document ends the live session; the page
+	// then shows why instead of the document.
+	useEffect(() => {
+		if (!sessionEnd) {
+			return;
+		}
+		setDocument(null);
+		setStatus(sessionEnd === "access_revoked" ? 410 : 404);
+		setError(t("publicDocument.error"));
+	}, [sessionEnd, t]);
+
 	const createdLabel = document ? formatDateTime(document.created_at) : "";
 	const isExpired = status === 410;
 	const isInvalid = status === 404 || status === 400;
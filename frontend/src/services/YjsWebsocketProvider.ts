@@ -1,7 +1,27 @@
 import * as Y from "yjs";
+import * as decoding from "lib0/decoding";
 import { WebsocketProvider } from "y-websocket";
 import { ENV } from "../config/env";
 
+// The server sends notices about the session, such as access changes, as JSON
+// in frames of this type; y-websocket only handles sync and awareness frames.
+const MESSAGE_CONTROL = 100;
+
+export type ControlNotice = {
+  type:
+    | "handshake"
+    | "permission_changed"
+    | "access_revoked"
+    | "document_deleted"
+    | "document_too_large";
+  role?: string;
+  can_edit?: boolean;
+  version?: number;
+  limit?: number;
+};
+
+type ControlListener = (notice: ControlNotice) => void;
+
 export type CollaborativeUser = {
   id: string;
   name?: string;
@@ -22,6 +42,7 @@ export class YjsCollaborativeEditor {
   private doc: Y.Doc;
   private text: Y.Text;
   private provider: WebsocketProvider;
+  private controlListeners = new Set<ControlListener>();
 
   constructor(
     documentId: string,
@@ -37,6 +58,9 @@ export class YjsCollaborativeEditor {
     this.provider = new WebsocketProvider(wsUrl, roomName, this.doc, {
       connect: true,
     });
+    this.provider.messageHandlers[MESSAGE_CONTROL] = (_encoder, decoder) => {
+      this.handleControl(decoding.readVarString(decoder));
+    };
 
     this.provider.awareness.setLocalStateField("user", {
       id: user.id,
@@ -58,11 +82,48 @@ export class YjsCollaborativeEditor {
     return this.doc;
   }
 
+  // onControl subscribes to the notices of the server and returns the
+  // unsubscribe function.
+  onControl(listener: ControlListener) {
+    this.controlListeners.add(listener);
+    return () => {
+      this.controlListeners.delete(listener);
+    };
+  }
+
   destroy() {
+    this.controlListeners.clear();
     this.provider.destroy();
     this.doc.destroy();
   }
 
+  private handleControl(payload: string) {
+    let notice: ControlNotice;
+    try {
+      notice = JSON.parse(payload) as ControlNotice;
+    } catch {
+      return;
+    }
+
+    if (notice.type === "handshake" && notice.version) {
+      this.setResumeVersion(notice.version);
+    }
+    if (notice.type === "access_revoked" || notice.type === "document_deleted") {
+      // The server refuses this session from now on; reconnecting is pointless.
+      this.provider.disconnect();
+    }
+    this.controlListeners.forEach((listener) => listener(notice));
+  }
+
+  // setResumeVersion makes reconnects resume from the version of the last
+  // handshake, so that the server sends the updates stored while away. The
+  // provider reads its url on every connection attempt.
+  private setResumeVersion(version: number) {
+    const url = new URL(this.provider.url);
+    url.searchParams.set("version", String(version));
+    this.provider.url = url.toString();
+  }
+
   private buildWebsocketUrl(
     _documentId: string,
     options: ProviderOptions = {},